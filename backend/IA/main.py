@@ -1,17 +1,49 @@
-from fastapi import FastAPI, HTTPException, BackgroundTasks
+from fastapi import FastAPI, HTTPException, BackgroundTasks, Request, UploadFile, File, Form
+from fastapi.responses import FileResponse, StreamingResponse
+from pypdf import PdfWriter
+from starlette.background import BackgroundTask
 import base64
+import json
 import os
 import traceback
+import uuid
 
 from model.proposta import Proposta as PropostaModel
 from src.ia_generator.ia import gerar_html_proposta
-from src.ia_generator.pdf_generator import converter_html_para_pdf
+from src.ia_generator.pdf_generator import OUTPUT_DIR, converter_html_para_pdf
 
 app = FastAPI()
 
+TAMANHO_BLOCO_PDF = 64 * 1024
+
+
+def aceita_multipart(request: Request) -> bool:
+    return "multipart/mixed" in request.headers.get("accept", "")
+
+
+def stream_multipart(html: str, caminho_pdf: str, boundary: str):
+    """Gera a resposta multipart/mixed: primeiro o JSON com o HTML, depois o
+    PDF em binário lido do disco em blocos, sem carregar o arquivo inteiro."""
+    yield (
+        f"--{boundary}\r\n"
+        "Content-Type: application/json; charset=utf-8\r\n\r\n"
+    ).encode("utf-8")
+    yield json.dumps({"html": html}, ensure_ascii=False).encode("utf-8")
+    yield (
+        f"\r\n--{boundary}\r\n"
+        "Content-Type: application/pdf\r\n"
+        f"Content-Length: {os.path.getsize(caminho_pdf)}\r\n\r\n"
+    ).encode("utf-8")
+    with open(caminho_pdf, "rb") as pdf_file:
+        while bloco := pdf_file.read(TAMANHO_BLOCO_PDF):
+            yield bloco
+    yield f"\r\n--{boundary}--\r\n".encode("utf-8")
+
+
 @app.post("/gerarproposta/pdf_dynamic")
 async def criar_proposta_pdf_dinamica(
     proposta: PropostaModel,
+    request: Request,
     background_tasks: BackgroundTasks
 ):
     
@@ -20,6 +52,17 @@ async def criar_proposta_pdf_dinamica(
         html_gerado = await gerar_html_proposta(proposta)
 
         caminho_pdf = await converter_html_para_pdf(html_gerado)
+
+        # Clientes que aceitam multipart/mixed recebem o PDF em binário, em
+        # streaming; os demais continuam recebendo o contrato antigo em base64.
+        if aceita_multipart(request):
+            boundary = uuid.uuid4().hex
+            return StreamingResponse(
+                stream_multipart(html_gerado, caminho_pdf, boundary),
+                media_type=f"multipart/mixed; boundary={boundary}",
+                background=BackgroundTask(os.remove, caminho_pdf),
+            )
+
         with open(caminho_pdf, "rb") as pdf_file:
             pdf_bytes = pdf_file.read()
 
@@ -36,3 +79,44 @@ async def criar_proposta_pdf_dinamica(
             os.remove(caminho_pdf)
             
         raise HTTPException(status_code=500, detail=f"Erro ao gerar proposta: {str(e)}")
+
+
+def remover_arquivos(*caminhos):
+    for caminho in caminhos:
+        if caminho and os.path.exists(caminho):
+            os.remove(caminho)
+
+
+@app.post("/gerarproposta/anexar_certificado")
+async def anexar_certificado(
+    background_tasks: BackgroundTasks,
+    pdf: UploadFile = File(...),
+    certificado_html: str = Form(...),
+):
+    """Renderiza a página de certificado do aceite e a anexa ao fim do PDF da
+    proposta, devolvendo o documento completo."""
+    caminho_original = os.path.join(OUTPUT_DIR, f"{uuid.uuid4()}.pdf")
+    caminho_certificado = None
+    caminho_final = os.path.join(OUTPUT_DIR, f"{uuid.uuid4()}.pdf")
+    try:
+        with open(caminho_original, "wb") as destino:
+            while bloco := await pdf.read(TAMANHO_BLOCO_PDF):
+                destino.write(bloco)
+
+        caminho_certificado = await converter_html_para_pdf(certificado_html)
+
+        writer = PdfWriter()
+        writer.append(caminho_original)
+        writer.append(caminho_certificado)
+        with open(caminho_final, "wb") as destino:
+            writer.write(destino)
+        writer.close()
+
+        remover_arquivos(caminho_original, caminho_certificado)
+        background_tasks.add_task(os.remove, caminho_final)
+        return FileResponse(caminho_final, media_type="application/pdf")
+
+    except Exception as e:
+        traceback.print_exc()
+        remover_arquivos(caminho_original, caminho_certificado, caminho_final)
+        raise HTTPException(status_code=500, detail=f"Erro ao anexar certificado: {str(e)}")
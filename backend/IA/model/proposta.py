@@ -2,6 +2,42 @@ from pydantic import BaseModel, Field
 from typing import Optional, List
 from datetime import datetime
 from uuid import UUID
+from decimal import Decimal
+
+class ItemProposta(BaseModel):
+    descricao: str
+    quantidade: Decimal
+    unidade: str
+    preco_unitario: Decimal = Field(alias="precoUnitario")
+    desconto: Decimal
+    imposto: Decimal
+    subtotal: Decimal
+    valor_desconto: Decimal = Field(alias="valorDesconto")
+    valor_imposto: Decimal = Field(alias="valorImposto")
+    total: Decimal
+    class Config:
+        populate_by_name = True
+
+class TotaisProposta(BaseModel):
+    moeda: str
+    subtotal: Decimal
+    descontos: Decimal
+    impostos: Decimal
+    total: Decimal
+
+class ContatoMarca(BaseModel):
+    email: Optional[str] = None
+    telefone: Optional[str] = None
+    site: Optional[str] = None
+    endereco: Optional[str] = None
+
+class EstiloMarca(BaseModel):
+    cores_secundarias: List[str] = Field(default=[], alias="coresSecundarias")
+    fonte: Optional[str] = None
+    rodape: Optional[str] = None
+    contato: ContatoMarca = ContatoMarca()
+    class Config:
+        populate_by_name = True
 
 class Proposta(BaseModel):
     id: UUID
@@ -17,5 +53,11 @@ class Proposta(BaseModel):
     arquivo_final: Optional[str] = Field(default=None, alias="arquivoFinal")
     data_criacao: datetime = Field(alias="dataCriacao")
     last_update: datetime = Field(alias="lastUpdate")
+    valido_ate: Optional[datetime] = Field(default=None, alias="validoAte")
+    # Calculados pelo backend; a IA não refaz as contas.
+    itens: List[ItemProposta] = []
+    totais: Optional[TotaisProposta] = None
+    # Estilo efetivo do kit de marca, já com as sobrescritas da proposta.
+    marca: Optional[EstiloMarca] = None
     class Config:
         populate_by_name = True
@@ -8,6 +8,8 @@ from model.proposta import Proposta as PropostaModel
 from langchain_core.output_parsers import StrOutputParser
 from .ai_models import PropostaConteudo
 from pathlib import Path
+from decimal import Decimal
+from html import escape
 
 load_dotenv()
 
@@ -60,6 +62,14 @@ em formato HTML, com base nas informações fornecidas e no exemplo de proposta
 * **Cores Sugeridas:** {cores}
 * **Logo da Empresa:** {logo}
 * **Logo do Cliente:** {logo_cliente}
+* **Validade da Proposta:** {validade}
+* **Cores Secundárias:** {cores_secundarias}
+* **Fonte:** {fonte}
+* **Rodapé:** {rodape}
+* **Contato da Empresa:** {contato}
+
+### TABELA DE PREÇOS
+{tabela_precos}
 
 ### EXEMPLO DE PROPOSTA (Use como sua base de estilo e estrutura)
 Este é um um exemplo de alta qualidade fornecido:
@@ -70,8 +80,11 @@ Este é um um exemplo de alta qualidade fornecido:
 2.  **CSS Inline ou em Bloco:** TODO o CSS deve estar dentro do arquivo, seja em atributos `style="..."` ou em um bloco `<style>...</style>` no `<head>`. Não use links externos para CSS.
 3.  **Design Moderno:** Use um design limpo, profissional e moderno (ex: flexbox, padding, fontes legíveis).
 4.  **Conteúdo Persuasivo:** Use as informações base para gerar o conteúdo de todas as seções necessárias (Introdução, O Desafio do Cliente, Nossa Solução, Escopo, Próximos Passos).
-5.  **Use as Cores:** Se as cores forem fornecidas, tente incorporá-las no design (ex: em títulos, botões).
-6.  **REGRA ESTRITA:** Responda APENAS com o código HTML. Não inclua NENHUM texto, preâmbulo (como "Aqui está seu HTML...") ou explicação antes de `<!DOCTYPE html>` ou depois de `</html>`.
+5.  **Validade:** Se a validade for informada, exiba-a de forma visível no documento (ex: "Proposta válida até 30/11/2025"), sem inventar outra data.
+6.  **Use as Cores:** Se as cores forem fornecidas, tente incorporá-las no design (ex: em títulos, botões).
+7.  **Identidade Visual:** Se fonte, rodapé ou contato forem informados, use a fonte em todo o documento (com uma fonte genérica de fallback) e exiba o rodapé e o contato no fim do documento exatamente como informados. Use as cores secundárias como apoio às cores sugeridas.
+8.  **Preços:** Se houver uma tabela de preços acima, inclua-a em uma seção "Investimento" exatamente com os itens, quantidades e valores informados; você pode estilizar a tabela, mas não altere, arredonde nem recalcule nenhum número. Sem tabela, não invente preços nem valores.
+9.  **REGRA ESTRITA:** Responda APENAS com o código HTML. Não inclua NENHUM texto, preâmbulo (como "Aqui está seu HTML...") ou explicação antes de `<!DOCTYPE html>` ou depois de `</html>`.
 
 **Início da Resposta HTML:**
 <!DOCTYPE html>
@@ -84,6 +97,61 @@ prompt = ChatPromptTemplate.from_template(
 
 chain = prompt | llm | StrOutputParser()
 
+def formatar_brl(valor: Decimal) -> str:
+    """Formata no padrão brasileiro, ex.: R$ 1.234,56."""
+    texto = f"{valor:,.2f}".replace(",", "_").replace(".", ",").replace("_", ".")
+    return f"R$ {texto}"
+
+def formatar_numero(valor: Decimal) -> str:
+    """Quantidades e percentuais sem zeros à direita, com vírgula decimal."""
+    texto = format(valor.normalize(), "f")
+    return texto.replace(".", ",")
+
+def montar_tabela_precos(proposta: PropostaModel) -> str:
+    """Monta a tabela HTML com os valores já calculados pelo backend, para que
+    o documento mostre os números oficiais e não os da IA."""
+    if not proposta.itens or not proposta.totais:
+        return "Nenhuma tabela de preços informada."
+    linhas = []
+    for item in proposta.itens:
+        linhas.append(
+            "<tr>"
+            f"<td>{escape(item.descricao)}</td>"
+            f"<td>{formatar_numero(item.quantidade)} {escape(item.unidade)}</td>"
+            f"<td>{formatar_brl(item.preco_unitario)}</td>"
+            f"<td>{formatar_numero(item.desconto)}%</td>"
+            f"<td>{formatar_numero(item.imposto)}%</td>"
+            f"<td>{formatar_brl(item.total)}</td>"
+            "</tr>"
+        )
+    totais = proposta.totais
+    return (
+        "<table>"
+        "<thead><tr><th>Descrição</th><th>Quantidade</th><th>Preço unitário</th>"
+        "<th>Desconto</th><th>Imposto</th><th>Total</th></tr></thead>"
+        f"<tbody>{''.join(linhas)}</tbody>"
+        "<tfoot>"
+        f"<tr><td colspan=\"5\">Subtotal</td><td>{formatar_brl(totais.subtotal)}</td></tr>"
+        f"<tr><td colspan=\"5\">Descontos</td><td>- {formatar_brl(totais.descontos)}</td></tr>"
+        f"<tr><td colspan=\"5\">Impostos</td><td>{formatar_brl(totais.impostos)}</td></tr>"
+        f"<tr><td colspan=\"5\">Total</td><td>{formatar_brl(totais.total)}</td></tr>"
+        "</tfoot>"
+        "</table>"
+    )
+
+def dados_marca(proposta: PropostaModel) -> dict:
+    """Campos do kit de marca para o prompt; os ausentes viram "Não informado"."""
+    marca = proposta.marca
+    contato = []
+    if marca:
+        contato = [c for c in (marca.contato.email, marca.contato.telefone, marca.contato.site, marca.contato.endereco) if c]
+    return {
+        "cores_secundarias": ", ".join(marca.cores_secundarias) if marca and marca.cores_secundarias else "Não informadas",
+        "fonte": marca.fonte if marca and marca.fonte else "Não informada",
+        "rodape": marca.rodape if marca and marca.rodape else "Não informado",
+        "contato": " · ".join(contato) if contato else "Não informado",
+    }
+
 async def gerar_html_proposta(proposta: PropostaModel) -> str:
     html_existente = getattr(proposta, "html", None)
     referencia_html = html_existente if html_existente else exemplo_html
@@ -95,6 +163,9 @@ async def gerar_html_proposta(proposta: PropostaModel) -> str:
         "cores": ", ".join(proposta.cores) if proposta.cores else "Cores padrão (azul e cinza)",
         "logo": proposta.logo,
         "logo_cliente": proposta.logo_cliente,
+        "validade": proposta.valido_ate.strftime("%d/%m/%Y") if proposta.valido_ate else "Não informada",
+        "tabela_precos": montar_tabela_precos(proposta),
+        **dados_marca(proposta),
         "exemplo_html": referencia_html
     }
     try:
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -38,6 +39,12 @@ func main() {
 	logger.Info("Conexão com o banco de dados estabelecida com sucesso!")
 	
 	router := gin.Default()
+	// ClientIP é gravado como evidência do aceite; X-Forwarded-For só é
+	// considerado quando a conexão vem de um proxy configurado.
+	if err := router.SetTrustedProxies(ProxiesConfiaveis()); err != nil {
+		logger.Error("Erro fatal ao configurar PROXIES_CONFIAVEIS", err)
+		os.Exit(1)
+	}
 
 	port := os.Getenv("PORT"); if port == "" {
     port = "8080"
@@ -49,7 +56,11 @@ func main() {
 	}
 
 	logger.Info("Inicializando as rotas dos serviços!")
-	handler.SetupServices(dbpool, router)
+	geracaoWorker, webhookWorker, err := handler.SetupServices(ctx, dbpool, router)
+	if err != nil {
+		logger.Error("Erro fatal ao configurar os serviços", err)
+		os.Exit(1)
+	}
 
 	go SetupServer(server)
 
@@ -64,6 +75,12 @@ func main() {
 		logger.Error("Erro no graceful shutdown do servidor", err)
 	}
 
+	logger.Info("Aguardando workers de geração finalizarem...")
+	geracaoWorker.Wait()
+
+	logger.Info("Aguardando entregas de webhook em andamento...")
+	webhookWorker.Wait()
+
 	logger.Info("Servidor finalizado com sucesso.")
 }
 
@@ -75,6 +92,18 @@ func SetupServer(server *http.Server) {
 	}
 }
 
+// ProxiesConfiaveis lê PROXIES_CONFIAVEIS, uma lista de IPs ou CIDRs separados
+// por vírgula. Vazia, nenhum proxy é confiável e o IP do cliente é o da conexão.
+func ProxiesConfiaveis() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("PROXIES_CONFIAVEIS"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 func DbConfig(ctx context.Context) (*pgxpool.Pool, error) {
 	connectString := os.Getenv("DATABASE_URL")
 	if connectString == "" {
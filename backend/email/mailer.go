@@ -0,0 +1,189 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"propulse/shared/env"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BackendSMTP    = "smtp"
+	BackendMemoria = "memoria"
+)
+
+var ErrSemDestinatarios = errors.New("a mensagem não tem destinatários")
+
+var ErrEnderecoInvalido = errors.New("endereço de e-mail inválido")
+
+// Anexo é lido em streaming durante o envio; o Conteudo não é carregado
+// inteiro em memória.
+type Anexo struct {
+	Nome        string
+	ContentType string
+	Conteudo    io.Reader
+}
+
+type Mensagem struct {
+	Para    []string
+	Cc      []string
+	Assunto string
+	Corpo   string
+	Anexos  []Anexo
+}
+
+// Destinatarios devolve todos os endereços do envelope SMTP.
+func (m Mensagem) Destinatarios() []string {
+	return append(append([]string{}, m.Para...), m.Cc...)
+}
+
+// Mailer entrega mensagens e devolve o Message-ID gerado para elas.
+type Mailer interface {
+	Enviar(ctx context.Context, msg Mensagem) (string, error)
+}
+
+// validarDestinatarios exige que cada destinatário seja só um endereço, sem
+// nome nem quebras de linha, que chegariam aos cabeçalhos To e Cc.
+func validarDestinatarios(msg Mensagem) error {
+	destinatarios := msg.Destinatarios()
+	if len(destinatarios) == 0 {
+		return ErrSemDestinatarios
+	}
+	for _, destinatario := range destinatarios {
+		endereco, err := mail.ParseAddress(destinatario)
+		if err != nil || endereco.Name != "" || endereco.Address != destinatario {
+			return fmt.Errorf("%w: %q", ErrEnderecoInvalido, destinatario)
+		}
+	}
+	return nil
+}
+
+// NewMailerFromEnv escolhe a implementação pela variável MAIL_BACKEND.
+func NewMailerFromEnv() (Mailer, error) {
+	backend := env.String("MAIL_BACKEND", BackendSMTP)
+	switch backend {
+	case BackendSMTP:
+		return NewSMTPMailer(SMTPConfigFromEnv()), nil
+	case BackendMemoria:
+		return NewMemoriaMailer(env.String("MAIL_REMETENTE", remetentePadrao)), nil
+	default:
+		return nil, fmt.Errorf("MAIL_BACKEND inválido: %q (use %q ou %q)", backend, BackendSMTP, BackendMemoria)
+	}
+}
+
+const remetentePadrao = "Propulse <propostas@propulse.local>"
+
+// novoMessageID gera um Message-ID no domínio do remetente.
+func novoMessageID(remetente string) string {
+	dominio := "propulse.local"
+	if endereco, err := mail.ParseAddress(remetente); err == nil {
+		if i := strings.LastIndexByte(endereco.Address, '@'); i >= 0 {
+			dominio = endereco.Address[i+1:]
+		}
+	}
+	return "<" + uuid.NewString() + "@" + dominio + ">"
+}
+
+// enderecoEnvelope extrai o endereço de "Nome <endereco>" para o MAIL FROM.
+func enderecoEnvelope(remetente string) (string, error) {
+	endereco, err := mail.ParseAddress(remetente)
+	if err != nil {
+		return "", fmt.Errorf("MAIL_REMETENTE inválido: %w", err)
+	}
+	return endereco.Address, nil
+}
+
+// escreverMensagem grava a mensagem em formato MIME: o corpo em texto com
+// quoted-printable e cada anexo em base64, copiado em streaming.
+func escreverMensagem(w io.Writer, remetente string, messageID string, msg Mensagem) error {
+	mw := multipart.NewWriter(w)
+	cabecalhos := []string{
+		"From: " + remetente,
+		"To: " + strings.Join(msg.Para, ", "),
+	}
+	if len(msg.Cc) > 0 {
+		cabecalhos = append(cabecalhos, "Cc: "+strings.Join(msg.Cc, ", "))
+	}
+	cabecalhos = append(cabecalhos,
+		"Subject: "+mime.QEncoding.Encode("utf-8", msg.Assunto),
+		"Date: "+time.Now().Format(time.RFC1123Z),
+		"Message-ID: "+messageID,
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary="+mw.Boundary(),
+	)
+	if _, err := io.WriteString(w, strings.Join(cabecalhos, "\r\n")+"\r\n\r\n"); err != nil {
+		return err
+	}
+
+	parte, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(parte)
+	if _, err := io.WriteString(qp, msg.Corpo); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+
+	for _, anexo := range msg.Anexos {
+		parte, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(anexo.ContentType, map[string]string{"name": anexo.Nome})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": anexo.Nome})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return err
+		}
+		b64 := base64.NewEncoder(base64.StdEncoding, &quebraLinhas{w: parte})
+		if _, err := io.Copy(b64, anexo.Conteudo); err != nil {
+			return err
+		}
+		if err := b64.Close(); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// quebraLinhas insere CRLF a cada 76 caracteres, o limite de linha do base64
+// em mensagens MIME.
+type quebraLinhas struct {
+	w      io.Writer
+	coluna int
+}
+
+func (q *quebraLinhas) Write(p []byte) (int, error) {
+	escritos := 0
+	for len(p) > 0 {
+		n := min(76-q.coluna, len(p))
+		if _, err := q.w.Write(p[:n]); err != nil {
+			return escritos, err
+		}
+		escritos += n
+		q.coluna += n
+		p = p[n:]
+		if q.coluna == 76 {
+			if _, err := io.WriteString(q.w, "\r\n"); err != nil {
+				return escritos, err
+			}
+			q.coluna = 0
+		}
+	}
+	return escritos, nil
+}
@@ -0,0 +1,129 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestMemoriaMailerRecusaEnderecosInvalidos(t *testing.T) {
+	casos := []struct {
+		nome     string
+		msg      Mensagem
+		esperado error
+	}{
+		{"sem destinatários", Mensagem{Assunto: "Proposta"}, ErrSemDestinatarios},
+		{"CRLF com Bcc injetado", Mensagem{Para: []string{"maria@cliente.com\r\nBcc: intruso@externo.com"}}, ErrEnderecoInvalido},
+		{"LF com cabeçalho injetado", Mensagem{Para: []string{"maria@cliente.com\nX-Injetado: 1"}}, ErrEnderecoInvalido},
+		{"CRLF na cópia", Mensagem{Para: []string{"maria@cliente.com"}, Cc: []string{"a@b.com\r\n\r\ncorpo falso"}}, ErrEnderecoInvalido},
+		{"com nome", Mensagem{Para: []string{"Maria <maria@cliente.com>"}}, ErrEnderecoInvalido},
+		{"dois endereços em um", Mensagem{Para: []string{"maria@cliente.com, intruso@externo.com"}}, ErrEnderecoInvalido},
+		{"sem arroba", Mensagem{Para: []string{"maria"}}, ErrEnderecoInvalido},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			mailer := NewMemoriaMailer(remetentePadrao)
+			if _, err := mailer.Enviar(context.Background(), c.msg); !errors.Is(err, c.esperado) {
+				t.Errorf("erro %v, esperado %v", err, c.esperado)
+			}
+			if n := len(mailer.Enviadas()); n != 0 {
+				t.Errorf("%d mensagens guardadas, esperado nenhuma", n)
+			}
+		})
+	}
+}
+
+func TestMemoriaMailerMensagemMIME(t *testing.T) {
+	pdf := bytes.Repeat([]byte("%PDF-1.7 conteúdo binário \x00\xff "), 40)
+	msg := Mensagem{
+		Para:    []string{"maria@cliente.com", "joao@cliente.com"},
+		Cc:      []string{"compras@cliente.com"},
+		Assunto: "Proposta: Ação comercial\r\nBcc: intruso@externo.com",
+		Corpo:   "Olá, Maria,\n\nSegue a proposta com acentuação: ç, ã, é.\n",
+		Anexos:  []Anexo{{Nome: "Proposta Comercial - Maria.pdf", ContentType: "application/pdf", Conteudo: bytes.NewReader(pdf)}},
+	}
+	mailer := NewMemoriaMailer("Propulse <propostas@empresa.com.br>")
+	messageID, err := mailer.Enviar(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Enviar: %v", err)
+	}
+	if !strings.HasSuffix(messageID, "@empresa.com.br>") {
+		t.Errorf("Message-ID %q fora do domínio do remetente", messageID)
+	}
+
+	enviadas := mailer.Enviadas()
+	if len(enviadas) != 1 {
+		t.Fatalf("%d mensagens guardadas, esperado 1", len(enviadas))
+	}
+	enviada := enviadas[0]
+	if got := strings.Join(enviada.Destinatarios, ","); got != "maria@cliente.com,joao@cliente.com,compras@cliente.com" {
+		t.Errorf("destinatários do envelope: %s", got)
+	}
+
+	lida, err := mail.ReadMessage(bytes.NewReader(enviada.Dados))
+	if err != nil {
+		t.Fatalf("mensagem MIME inválida: %v", err)
+	}
+	cabecalhos := lida.Header
+	if got := cabecalhos.Get("To"); got != "maria@cliente.com, joao@cliente.com" {
+		t.Errorf("To: %q", got)
+	}
+	if got := cabecalhos.Get("Cc"); got != "compras@cliente.com" {
+		t.Errorf("Cc: %q", got)
+	}
+	if got := cabecalhos.Get("Message-ID"); got != messageID {
+		t.Errorf("Message-ID: %q, esperado %q", got, messageID)
+	}
+	if _, ok := cabecalhos["Bcc"]; ok {
+		t.Errorf("cabeçalho Bcc injetado pelo assunto")
+	}
+	assunto, err := new(mime.WordDecoder).DecodeHeader(cabecalhos.Get("Subject"))
+	if err != nil || assunto != msg.Assunto {
+		t.Errorf("Subject decodificado %q (%v), esperado %q", assunto, err, msg.Assunto)
+	}
+
+	_, params, err := mime.ParseMediaType(cabecalhos.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("Content-Type: %v", err)
+	}
+	partes := multipart.NewReader(lida.Body, params["boundary"])
+
+	texto, err := partes.NextPart()
+	if err != nil {
+		t.Fatalf("parte de texto: %v", err)
+	}
+	corpo, _ := io.ReadAll(quotedprintable.NewReader(texto))
+	// O corpo em texto trafega com quebras CRLF.
+	if strings.ReplaceAll(string(corpo), "\r\n", "\n") != msg.Corpo {
+		t.Errorf("corpo %q, esperado %q", corpo, msg.Corpo)
+	}
+
+	anexo, err := partes.NextPart()
+	if err != nil {
+		t.Fatalf("parte do anexo: %v", err)
+	}
+	if anexo.FileName() != "Proposta Comercial - Maria.pdf" {
+		t.Errorf("nome do anexo %q", anexo.FileName())
+	}
+	codificado, _ := io.ReadAll(anexo)
+	for _, linha := range strings.Split(strings.TrimRight(string(codificado), "\r\n"), "\r\n") {
+		if len(linha) > 76 {
+			t.Fatalf("linha base64 com %d caracteres", len(linha))
+		}
+	}
+	conteudo, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(codificado)))
+	if err != nil || !bytes.Equal(conteudo, pdf) {
+		t.Errorf("anexo decodificado diferente do original (%v)", err)
+	}
+	if _, err := partes.NextPart(); err != io.EOF {
+		t.Errorf("partes extras na mensagem: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"propulse/shared/logger"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// MensagemEnviada é uma mensagem guardada pelo MemoriaMailer, já no formato
+// MIME que seria transmitido.
+type MensagemEnviada struct {
+	MessageID     string
+	Destinatarios []string
+	Assunto       string
+	Dados         []byte
+}
+
+// MemoriaMailer guarda as mensagens em memória em vez de entregá-las. Serve
+// para desenvolvimento e testes sem servidor SMTP.
+type MemoriaMailer struct {
+	remetente string
+	mu        sync.Mutex
+	enviadas  []MensagemEnviada
+}
+
+func NewMemoriaMailer(remetente string) *MemoriaMailer {
+	return &MemoriaMailer{remetente: remetente}
+}
+
+func (m *MemoriaMailer) Enviar(ctx context.Context, msg Mensagem) (string, error) {
+	if err := validarDestinatarios(msg); err != nil {
+		return "", err
+	}
+	messageID := novoMessageID(m.remetente)
+	var dados bytes.Buffer
+	if err := escreverMensagem(&dados, m.remetente, messageID, msg); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	m.enviadas = append(m.enviadas, MensagemEnviada{
+		MessageID:     messageID,
+		Destinatarios: msg.Destinatarios(),
+		Assunto:       msg.Assunto,
+		Dados:         dados.Bytes(),
+	})
+	m.mu.Unlock()
+	logger.Info("Mensagem guardada pelo mailer em memória", zap.String("message_id", messageID))
+	return messageID, nil
+}
+
+// Enviadas devolve uma cópia das mensagens guardadas até agora.
+func (m *MemoriaMailer) Enviadas() []MensagemEnviada {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MensagemEnviada{}, m.enviadas...)
+}
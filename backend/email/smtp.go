@@ -0,0 +1,120 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type SMTPConfig struct {
+	Host      string
+	Porta     int
+	Usuario   string
+	Senha     string
+	StartTLS  bool
+	Remetente string
+	Timeout   time.Duration
+}
+
+func SMTPConfigFromEnv() SMTPConfig {
+	return SMTPConfig{
+		Host:      env.String("SMTP_HOST", "mailhog"),
+		Porta:     env.Int("SMTP_PORTA", 1025),
+		Usuario:   env.String("SMTP_USUARIO", ""),
+		Senha:     env.String("SMTP_SENHA", ""),
+		StartTLS:  env.Bool("SMTP_STARTTLS", false),
+		Remetente: env.String("MAIL_REMETENTE", remetentePadrao),
+		Timeout:   env.Duration("SMTP_TIMEOUT", 30*time.Second),
+	}
+}
+
+// SMTPMailer entrega as mensagens em um servidor SMTP (MailHog em
+// desenvolvimento). Cada envio abre uma conexão própria.
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+func (m *SMTPMailer) Enviar(ctx context.Context, msg Mensagem) (string, error) {
+	if err := validarDestinatarios(msg); err != nil {
+		return "", err
+	}
+	destinatarios := msg.Destinatarios()
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+
+	endereco := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Porta))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", endereco)
+	if err != nil {
+		logger.Error("Erro ao conectar no servidor SMTP", err, zap.String("endereco", endereco))
+		return "", err
+	}
+	// net/smtp não recebe contexto; o prazo da conexão cobre a conversa inteira.
+	if prazo, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(prazo)
+	}
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		logger.Error("Erro ao iniciar sessão SMTP", err, zap.String("endereco", endereco))
+		return "", err
+	}
+	defer client.Close()
+
+	if m.cfg.StartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: m.cfg.Host}); err != nil {
+			logger.Error("Erro no STARTTLS com o servidor SMTP", err)
+			return "", err
+		}
+	}
+	if m.cfg.Usuario != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Usuario, m.cfg.Senha, m.cfg.Host)); err != nil {
+			logger.Error("Erro de autenticação no servidor SMTP", err)
+			return "", err
+		}
+	}
+
+	remetente, err := enderecoEnvelope(m.cfg.Remetente)
+	if err != nil {
+		return "", err
+	}
+	if err := client.Mail(remetente); err != nil {
+		return "", err
+	}
+	for _, destinatario := range destinatarios {
+		if err := client.Rcpt(destinatario); err != nil {
+			logger.Error("Destinatário recusado pelo servidor SMTP", err, zap.String("destinatario", destinatario))
+			return "", err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", err
+	}
+	messageID := novoMessageID(m.cfg.Remetente)
+	if err := escreverMensagem(w, m.cfg.Remetente, messageID, msg); err != nil {
+		w.Close()
+		logger.Error("Erro ao transmitir mensagem SMTP", err)
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		logger.Error("Servidor SMTP recusou a mensagem", err)
+		return "", err
+	}
+	if err := client.Quit(); err != nil {
+		// A mensagem já foi aceita no fim do DATA; falhar no QUIT não a desfaz.
+		logger.Error("Erro ao encerrar sessão SMTP", err)
+	}
+	return messageID, nil
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+	"propulse/storage"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+)
+
+var paginaAceiteTemplate = template.Must(template.New("aceite").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Proposta.Titulo}}</title>
+<style>
+body { font-family: sans-serif; margin: 0; color: #222; background: #f5f5f5; }
+main { max-width: 960px; margin: 0 auto; padding: 24px; }
+iframe { width: 100%; height: 70vh; border: 1px solid #ccc; background: #fff; }
+form, .decisao, .erro { background: #fff; border: 1px solid #ccc; padding: 16px; margin-top: 16px; }
+.erro { border-color: #c62828; color: #c62828; }
+label { display: block; margin-top: 12px; font-weight: bold; }
+input[type=text], textarea { width: 100%; box-sizing: border-box; padding: 8px; margin-top: 4px; }
+button { margin-top: 16px; margin-right: 8px; padding: 10px 20px; font-size: 15px; cursor: pointer; }
+.hash { font-family: monospace; font-size: 12px; word-break: break-all; color: #555; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Proposta.Titulo}}</h1>
+<p>{{.Proposta.NomeEmpresa}}</p>
+<iframe src="/p/{{.Token}}?formato=pdf" title="Proposta"></iframe>
+{{if .Proposta.ArquivoSha256}}<p class="hash">SHA-256 do documento: {{.Proposta.ArquivoSha256}}</p>{{end}}
+{{if .Erro}}<div class="erro">{{.Erro}}</div>{{end}}
+{{if .Aceite}}
+<div class="decisao">
+<p>Proposta {{if eq .Aceite.Decisao "aceitar"}}aceita{{else}}recusada{{end}} por <strong>{{.Aceite.Nome}}</strong> em {{.Aceite.DataCriacao.UTC.Format "02/01/2006 15:04"}} (UTC).</p>
+{{if .Aceite.Comentario}}<p>Comentário: {{.Aceite.Comentario}}</p>{{end}}
+</div>
+{{else if .Proposta.ArquivoSha256}}
+<form method="post" action="/p/{{.Token}}/aceite">
+<input type="hidden" name="documentoSha256" value="{{.Proposta.ArquivoSha256}}">
+<label for="nome">Nome completo</label>
+<input type="text" id="nome" name="nome" value="{{.Input.Nome}}" required minlength="3" maxlength="255">
+<label for="comentario">Comentário (opcional)</label>
+<textarea id="comentario" name="comentario" rows="4" maxlength="2000">{{.Input.Comentario}}</textarea>
+<button type="submit" name="decisao" value="aceitar">Aceitar proposta</button>
+<button type="submit" name="decisao" value="recusar">Recusar proposta</button>
+</form>
+{{end}}
+</main>
+</body>
+</html>
+`))
+
+type dadosPaginaAceite struct {
+	Token    string
+	Proposta *model.Proposta
+	Aceite   *model.Aceite
+	Input    model.RegistrarAceite
+	Erro     string
+}
+
+type AceiteHandler struct {
+	aceiteService service.AceiteService
+}
+
+func NewAceiteHandler(aceiteService service.AceiteService) AceiteHandler {
+	return AceiteHandler{
+		aceiteService: aceiteService,
+	}
+}
+
+func renderizarPaginaAceite(ctx *gin.Context, status int, dados dadosPaginaAceite) {
+	ctx.Header("X-Robots-Tag", "noindex")
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	if err := paginaAceiteTemplate.Execute(ctx.Writer, dados); err != nil {
+		logger.Error("Erro ao renderizar página de aceite", err)
+	}
+}
+
+// statusErroAceite traduz os erros do fluxo de aceite em status HTTP e na
+// mensagem exibida ao cliente.
+func statusErroAceite(err error) (int, string) {
+	var validacao validator.ValidationErrors
+	switch {
+	case errors.Is(err, service.ErrLinkInvalido), errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound, service.ErrLinkInvalido.Error()
+	case errors.Is(err, service.ErrLinkExpirado):
+		return http.StatusGone, err.Error()
+	case errors.Is(err, service.ErrPDFIndisponivel):
+		return http.StatusConflict, "a proposta ainda não possui PDF para ser aceito"
+	case errors.Is(err, model.ErrTransicaoInvalida):
+		return http.StatusConflict, "a proposta não está aguardando decisão do cliente"
+	case errors.Is(err, model.ErrDocumentoDivergente), errors.Is(err, model.ErrAceiteJaRegistrado):
+		return http.StatusConflict, err.Error()
+	case errors.As(err, &validacao):
+		return http.StatusBadRequest, "informe o nome completo (mínimo de 3 caracteres) e a decisão"
+	}
+	return http.StatusInternalServerError, "erro ao registrar a decisão"
+}
+
+// PaginaAceite é a página pública em que o cliente lê a proposta e decide.
+func (h *AceiteHandler) PaginaAceite(ctx *gin.Context) {
+	token := ctx.Param("token")
+	proposta, aceite, err := h.aceiteService.PaginaAceite(ctx.Request.Context(), token)
+	if err != nil {
+		status, msg := statusErroAceite(err)
+		if status == http.StatusInternalServerError {
+			logger.Error("Erro ao carregar página de aceite", err)
+		}
+		ctx.Header("Content-Type", "text/plain; charset=utf-8")
+		ctx.String(status, msg)
+		return
+	}
+	renderizarPaginaAceite(ctx, http.StatusOK, dadosPaginaAceite{
+		Token:    token,
+		Proposta: proposta,
+		Aceite:   aceite,
+	})
+}
+
+// RegistrarAceite recebe a decisão do cliente. Aceita JSON ou o formulário da
+// página de aceite; para o formulário a resposta é a própria página.
+func (h *AceiteHandler) RegistrarAceite(ctx *gin.Context) {
+	token := ctx.Param("token")
+	viaFormulario := !strings.HasPrefix(ctx.ContentType(), "application/json")
+
+	var input model.RegistrarAceite
+	err := ctx.ShouldBind(&input)
+	if err == nil {
+		err = model.ValidarStructRegistrarAceite(&input)
+	}
+	var aceite *model.Aceite
+	if err == nil {
+		aceite, err = h.aceiteService.RegistrarAceite(ctx.Request.Context(), token, input, service.EvidenciaRequisicao{
+			Ip:        ctx.ClientIP(),
+			UserAgent: ctx.Request.UserAgent(),
+		})
+	}
+
+	status, msg := http.StatusCreated, ""
+	if err != nil {
+		status, msg = statusErroAceite(err)
+		if status == http.StatusInternalServerError {
+			logger.Error("Erro ao registrar decisão do cliente", err)
+		}
+		if !viaFormulario && status == http.StatusBadRequest {
+			msg = err.Error()
+		}
+	}
+
+	if !viaFormulario {
+		if err != nil {
+			ctx.JSON(status, gin.H{"error": msg})
+			return
+		}
+		ctx.JSON(status, aceite)
+		return
+	}
+
+	dados := dadosPaginaAceite{Token: token, Input: input, Erro: msg}
+	// Recarrega a página para refletir o estado atual, inclusive uma decisão
+	// registrada antes por outra aba.
+	proposta, atual, errPagina := h.aceiteService.PaginaAceite(ctx.Request.Context(), token)
+	if errPagina != nil {
+		ctx.Header("Content-Type", "text/plain; charset=utf-8")
+		ctx.String(status, msg)
+		return
+	}
+	dados.Proposta = proposta
+	dados.Aceite = atual
+	if status == http.StatusCreated {
+		status = http.StatusOK
+	}
+	renderizarPaginaAceite(ctx, status, dados)
+}
+
+func (h *AceiteHandler) FindAceite(ctx *gin.Context) {
+	aceite, err := h.aceiteService.FindAceite(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta sem decisão do cliente"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, aceite)
+}
+
+// GerarCertificado refaz o certificado de um aceite cuja geração falhou.
+func (h *AceiteHandler) GerarCertificado(ctx *gin.Context) {
+	aceite, err := h.aceiteService.GerarCertificado(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta sem decisão do cliente"})
+		return
+	}
+	if errors.Is(err, model.ErrDocumentoDivergente) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrPDFIndisponivel) || errors.Is(err, storage.ErrNaoEncontrado) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "PDF da proposta não encontrado"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao gerar certificado do aceite", err)
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, aceite)
+}
+
+func (h *AceiteHandler) RegisterRoutes(router *gin.Engine) {
+	aceiteRoutes := router.Group("/proposta", AutenticacaoAPI())
+	{
+		aceiteRoutes.GET("/:id/aceite", h.FindAceite)
+		aceiteRoutes.POST("/:id/aceite/certificado", h.GerarCertificado)
+	}
+	router.GET("/p/:token/aceite", h.PaginaAceite)
+	router.POST("/p/:token/aceite", h.RegistrarAceite)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"propulse/shared/logger"
+	"propulse/storage"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ArquivoHandler serve as URLs assinadas do storage local. Com o backend S3 as
+// URLs assinadas apontam direto para o bucket e esta rota não é registrada.
+type ArquivoHandler struct {
+	store *storage.LocalStore
+}
+
+func NewArquivoHandler(store *storage.LocalStore) ArquivoHandler {
+	return ArquivoHandler{
+		store: store,
+	}
+}
+
+func (h *ArquivoHandler) BaixarArquivo(ctx *gin.Context) {
+	chave := strings.TrimPrefix(ctx.Param("chave"), "/")
+	if !h.store.VerificarAssinatura(chave, ctx.Query("expira"), ctx.Query("assinatura")) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "assinatura inválida ou expirada"})
+		return
+	}
+
+	arquivo, info, err := h.store.Get(ctx.Request.Context(), chave)
+	if errors.Is(err, storage.ErrNaoEncontrado) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "arquivo não encontrado"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao abrir arquivo do storage", err, zap.String("chave", chave))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao abrir arquivo"})
+		return
+	}
+	defer arquivo.Close()
+
+	if info.ContentType != "" {
+		ctx.Header("Content-Type", info.ContentType)
+	}
+	ctx.Header("ETag", info.ETag)
+	http.ServeContent(ctx.Writer, ctx.Request, "", info.ModificadoEm, arquivo)
+}
+
+func (h *ArquivoHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/arquivos/*chave", h.BaixarArquivo)
+}
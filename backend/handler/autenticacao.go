@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AutenticacaoAPI exige o token configurado em API_TOKEN, enviado como
+// "Authorization: Bearer <token>" ou no header X-API-Key. Sem API_TOKEN
+// definido a rota fica aberta, como no ambiente de desenvolvimento.
+func AutenticacaoAPI() gin.HandlerFunc {
+	token := env.String("API_TOKEN", "")
+	if token == "" {
+		logger.Info("API_TOKEN não definido, rotas autenticadas ficam abertas")
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+	return func(ctx *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(credencialRecebida(ctx)), []byte(token)) != 1 {
+			ctx.Header("WWW-Authenticate", `Bearer realm="propulse"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token de API ausente ou inválido"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// credencialRecebida devolve o token enviado como "Authorization: Bearer" ou em
+// X-API-Key, com precedência para o Bearer; vazio quando não há nenhum.
+func credencialRecebida(ctx *gin.Context) string {
+	if auth := ctx.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ctx.GetHeader("X-API-Key")
+}
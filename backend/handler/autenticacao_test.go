@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAutenticacaoAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	casos := []struct {
+		nome           string
+		token          string
+		headers        map[string]string
+		statusEsperado int
+	}{
+		{"sem API_TOKEN a rota fica aberta", "", nil, http.StatusOK},
+		{"sem credencial", "segredo", nil, http.StatusUnauthorized},
+		{"bearer correto", "segredo", map[string]string{"Authorization": "Bearer segredo"}, http.StatusOK},
+		{"x-api-key correto", "segredo", map[string]string{"X-API-Key": "segredo"}, http.StatusOK},
+		{"bearer errado", "segredo", map[string]string{"Authorization": "Bearer outro"}, http.StatusUnauthorized},
+		{"x-api-key errado", "segredo", map[string]string{"X-API-Key": "outro"}, http.StatusUnauthorized},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			t.Setenv("API_TOKEN", c.token)
+			router := gin.New()
+			router.GET("/proposta/:id/pdf", AutenticacaoAPI(), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/proposta/1/pdf", nil)
+			for nome, valor := range c.headers {
+				req.Header.Set(nome, valor)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != c.statusEsperado {
+				t.Errorf("status %d, esperado %d", w.Code, c.statusEsperado)
+			}
+			if c.statusEsperado == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 sem WWW-Authenticate")
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type ClienteHandler struct {
+	clienteService service.ClienteService
+}
+
+func NewClienteHandler(clienteService service.ClienteService) ClienteHandler {
+	return ClienteHandler{
+		clienteService: clienteService,
+	}
+}
+
+// bindCliente lê e valida o corpo usado na criação e na atualização.
+func bindCliente(ctx *gin.Context) (*model.Cliente, bool) {
+	var cliente model.Cliente
+	if err := ctx.ShouldBindJSON(&cliente); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if err := model.ValidarStructCliente(&cliente); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &cliente, true
+}
+
+func (h *ClienteHandler) CriarCliente(ctx *gin.Context) {
+	input, ok := bindCliente(ctx)
+	if !ok {
+		return
+	}
+	cliente, err := h.clienteService.CriarCliente(ctx.Request.Context(), *input)
+	if errors.Is(err, model.ErrClienteDuplicado) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, cliente)
+}
+
+func (h *ClienteHandler) ListarClientes(ctx *gin.Context) {
+	clientes, err := h.clienteService.ListarClientes(ctx.Request.Context(), ctx.Query("q"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, clientes)
+}
+
+func (h *ClienteHandler) FindCliente(ctx *gin.Context) {
+	cliente, err := h.clienteService.FindCliente(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": model.ErrClienteNaoEncontrado.Error()})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao buscar cliente", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, cliente)
+}
+
+func (h *ClienteHandler) AtualizarCliente(ctx *gin.Context) {
+	input, ok := bindCliente(ctx)
+	if !ok {
+		return
+	}
+	cliente, err := h.clienteService.AtualizarCliente(ctx.Request.Context(), ctx.Param("id"), *input)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": model.ErrClienteNaoEncontrado.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrClienteDuplicado) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, cliente)
+}
+
+func (h *ClienteHandler) DeleteCliente(ctx *gin.Context) {
+	err := h.clienteService.DeleteCliente(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": model.ErrClienteNaoEncontrado.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrClienteEmUso) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *ClienteHandler) RegisterRoutes(router *gin.Engine) {
+	clienteRoutes := router.Group("/clientes", AutenticacaoAPI())
+	{
+		clienteRoutes.POST("/", h.CriarCliente)
+		clienteRoutes.GET("/", h.ListarClientes)
+		clienteRoutes.GET("/:id", h.FindCliente)
+		clienteRoutes.PUT("/:id", h.AtualizarCliente)
+		clienteRoutes.DELETE("/:id", h.DeleteCliente)
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// contentDisposition gera o header com um filename ASCII para clientes antigos
+// e o filename* em UTF-8 (RFC 6266/5987) preservando os acentos.
+func contentDisposition(tipo string, nome string) string {
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, tipo, nomeASCII(nome), codificarRFC5987(nome))
+}
+
+func nomeASCII(nome string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(nome) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == '"' || r == '\\' || r < 0x20 || r > 0x7e:
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func codificarRFC5987(s string) string {
+	const attrChar = "!#$&+-.^_`|~"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte(attrChar, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"propulse/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DiagnosticoHandler struct {
+	iaClient *service.IAClient
+	coletor  *service.ColetorOrfaos
+}
+
+func NewDiagnosticoHandler(iaClient *service.IAClient, coletor *service.ColetorOrfaos) DiagnosticoHandler {
+	return DiagnosticoHandler{
+		iaClient: iaClient,
+		coletor:  coletor,
+	}
+}
+
+func (d *DiagnosticoHandler) EstadoIA(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, d.iaClient.Estado())
+}
+
+// ColetarOrfaos executa o coletor de PDFs órfãos sob demanda. Por padrão roda
+// em dry-run e só relata; ?dryRun=false remove os órfãos encontrados.
+func (d *DiagnosticoHandler) ColetarOrfaos(ctx *gin.Context) {
+	dryRun := ctx.DefaultQuery("dryRun", "true") != "false"
+	relatorio, err := d.coletor.Executar(ctx.Request.Context(), dryRun)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if relatorio == nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "coleta de órfãos já em execução"})
+		return
+	}
+	ctx.JSON(http.StatusOK, relatorio)
+}
+
+func (h *DiagnosticoHandler) RegisterRoutes(router *gin.Engine) {
+	diagnosticoRoutes := router.Group("/diagnostico")
+	{
+		diagnosticoRoutes.GET("/ia", h.EstadoIA)
+		diagnosticoRoutes.POST("/coletor-orfaos", AutenticacaoAPI(), h.ColetarOrfaos)
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+	"propulse/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type EnvioHandler struct {
+	envioService service.EnvioService
+}
+
+func NewEnvioHandler(envioService service.EnvioService) EnvioHandler {
+	return EnvioHandler{
+		envioService: envioService,
+	}
+}
+
+func (h *EnvioHandler) EnviarProposta(ctx *gin.Context) {
+	var input model.EnviarProposta
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := model.ValidarStructEnviarProposta(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	envio, proposta, err := h.envioService.EnviarProposta(ctx.Request.Context(), ctx.Param("id"), input, atorDaRequisicao(ctx))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if errors.Is(err, service.ErrPDFIndisponivel) || errors.Is(err, storage.ErrNaoEncontrado) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "a proposta ainda não possui PDF para enviar"})
+		return
+	}
+	if errors.Is(err, model.ErrTransicaoInvalida) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrTemplateInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrEnvioFalhou) {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "envio": envio})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao enviar proposta por e-mail", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	omitHTML(proposta)
+	ctx.JSON(http.StatusCreated, gin.H{
+		"envio":    envio,
+		"proposta": proposta,
+	})
+}
+
+func (h *EnvioHandler) ListarEnvios(ctx *gin.Context) {
+	envios, err := h.envioService.ListarEnvios(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao listar envios da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, envios)
+}
+
+func (h *EnvioHandler) RegisterRoutes(router *gin.Engine) {
+	envioRoutes := router.Group("/proposta", AutenticacaoAPI())
+	{
+		envioRoutes.POST("/:id/enviar", h.EnviarProposta)
+		envioRoutes.GET("/:id/envios", h.ListarEnvios)
+	}
+}
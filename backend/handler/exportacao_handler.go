@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportacaoHandler struct {
+	exportacaoService service.ExportacaoService
+}
+
+func NewExportacaoHandler(exportacaoService service.ExportacaoService) ExportacaoHandler {
+	return ExportacaoHandler{
+		exportacaoService: exportacaoService,
+	}
+}
+
+func (h *ExportacaoHandler) ExportarPropostas(ctx *gin.Context) {
+	formato := ctx.DefaultQuery("formato", service.FormatoCSV)
+	contentType, ok := service.ContentTypesExportacao[formato]
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": service.ErrFormatoExportacao.Error()})
+		return
+	}
+	var filtro model.FiltroPropostas
+	if err := ctx.ShouldBindQuery(&filtro); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := model.ValidarStructFiltroPropostas(&filtro); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	nome := "propostas-" + time.Now().Format("2006-01-02") + "." + formato
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", `attachment; filename="`+nome+`"`)
+	ctx.Header("Cache-Control", "no-store")
+
+	err := h.exportacaoService.Exportar(ctx.Request.Context(), filtro, formato, ctx.Writer)
+	if err == nil {
+		return
+	}
+	if ctx.Writer.Written() {
+		// A planilha já começou a ser enviada; só resta interromper a resposta.
+		logger.Error("Exportação interrompida após o início da resposta", err)
+		ctx.Abort()
+		return
+	}
+	ctx.Writer.Header().Del("Content-Disposition")
+	ctx.Writer.Header().Del("Content-Type")
+	if errors.Is(err, model.ErrFiltroInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Error("Erro ao exportar propostas", err)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+func (h *ExportacaoHandler) RegisterRoutes(router *gin.Engine) {
+	exportacaoRoutes := router.Group("/proposta", AutenticacaoAPI())
+	{
+		exportacaoRoutes.GET("/export", h.ExportarPropostas)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"propulse/service"
+	"propulse/shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// respostaCapturada guarda uma cópia do corpo enviado ao cliente para que ela
+// possa ser gravada junto da chave de idempotência.
+type respostaCapturada struct {
+	gin.ResponseWriter
+	corpo bytes.Buffer
+}
+
+func (r *respostaCapturada) Write(b []byte) (int, error) {
+	r.corpo.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *respostaCapturada) WriteString(s string) (int, error) {
+	r.corpo.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotencia aplica o header Idempotency-Key: a primeira requisição com a
+// chave é processada e sua resposta é gravada; repetições com o mesmo corpo
+// recebem a resposta original, e uma chave reutilizada com outro corpo recebe 422.
+// Respostas 5xx não são gravadas, permitindo nova tentativa com a mesma chave.
+// As chaves valem por chamador: a mesma chave enviada por outro não colide.
+func Idempotencia(is service.IdempotenciaService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		chave := ctx.GetHeader(idempotencyKeyHeader)
+		if chave == "" {
+			ctx.Next()
+			return
+		}
+		if len(chave) > 255 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key deve ter no máximo 255 caracteres"})
+			return
+		}
+
+		corpo, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(corpo))
+
+		chave = chaveDoChamador(ctx, chave)
+		registro, err := is.Iniciar(ctx.Request.Context(), chave, hashRequisicao(ctx.Request, corpo))
+		switch {
+		case errors.Is(err, service.ErrIdempotenciaConflito):
+			ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrIdempotenciaEmAndamento):
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			logger.Error("Erro ao verificar Idempotency-Key", err, zap.String("chave", chave))
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if registro != nil {
+			contentType := "application/json; charset=utf-8"
+			if registro.ContentType != nil {
+				contentType = *registro.ContentType
+			}
+			ctx.Header("Idempotent-Replayed", "true")
+			ctx.Data(*registro.StatusCode, contentType, registro.Resposta)
+			ctx.Abort()
+			return
+		}
+
+		// A gravação não deve se perder se o cliente desconectar após a resposta.
+		gravacaoCtx := context.WithoutCancel(ctx.Request.Context())
+		defer func() {
+			// Um panic no handler não chega a Concluir; a reserva é liberada
+			// antes de o Recovery responder 500.
+			if r := recover(); r != nil {
+				is.Liberar(gravacaoCtx, chave)
+				panic(r)
+			}
+		}()
+
+		captura := &respostaCapturada{ResponseWriter: ctx.Writer}
+		ctx.Writer = captura
+		ctx.Next()
+
+		status := captura.Status()
+		if status >= http.StatusInternalServerError {
+			is.Liberar(gravacaoCtx, chave)
+			return
+		}
+		is.Concluir(gravacaoCtx, chave, status, captura.Header().Get("Content-Type"), captura.corpo.Bytes())
+	}
+}
+
+// hashRequisicao identifica a requisição pelo método, pelo caminho e pelo
+// corpo: a mesma chave com qualquer um deles diferente é um conflito.
+func hashRequisicao(req *http.Request, corpo []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.Path+"\n")
+	hash.Write(corpo)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// chaveDoChamador combina a Idempotency-Key com a credencial do chamador ou,
+// sem credencial, com o IP. O resultado é um hash, então cabe na coluna
+// qualquer que seja o tamanho do token, e o token não fica gravado no banco.
+func chaveDoChamador(ctx *gin.Context, chave string) string {
+	chamador := "token:" + credencialRecebida(ctx)
+	if chamador == "token:" {
+		chamador = "ip:" + ctx.ClientIP()
+	}
+	hash := sha256.New()
+	io.WriteString(hash, chamador+"\n"+chave)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"propulse/service"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHashRequisicao(t *testing.T) {
+	requisicao := func(metodo string, caminho string) *http.Request {
+		return httptest.NewRequest(metodo, caminho, nil)
+	}
+	base := hashRequisicao(requisicao(http.MethodPost, "/proposta"), []byte(`{"titulo":"A"}`))
+	casos := []struct {
+		nome  string
+		hash  string
+		igual bool
+	}{
+		{"mesma requisição", hashRequisicao(requisicao(http.MethodPost, "/proposta"), []byte(`{"titulo":"A"}`)), true},
+		{"query string não conta", hashRequisicao(requisicao(http.MethodPost, "/proposta?x=1"), []byte(`{"titulo":"A"}`)), true},
+		{"outro corpo", hashRequisicao(requisicao(http.MethodPost, "/proposta"), []byte(`{"titulo":"B"}`)), false},
+		{"outro caminho", hashRequisicao(requisicao(http.MethodPost, "/proposta/1/regerar"), []byte(`{"titulo":"A"}`)), false},
+		{"outro método", hashRequisicao(requisicao(http.MethodPut, "/proposta"), []byte(`{"titulo":"A"}`)), false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if (c.hash == base) != c.igual {
+				t.Errorf("hash igual = %v, esperado %v", c.hash == base, c.igual)
+			}
+		})
+	}
+}
+
+func TestIdempotenciaSemChave(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Sem o header o serviço nem é consultado.
+	router.POST("/proposta", Idempotencia(service.IdempotenciaService{}), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/proposta", strings.NewReader(`{}`)))
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("status %d, replayed %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotenciaChaveLonga(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/proposta", Idempotencia(service.IdempotenciaService{}), func(ctx *gin.Context) {
+		t.Error("handler chamado com chave inválida")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/proposta", strings.NewReader(`{}`))
+	req.Header.Set(idempotencyKeyHeader, strings.Repeat("k", 256))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, esperado 400", w.Code)
+	}
+}
+
+func TestRespostaCapturada(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	captura := &respostaCapturada{ResponseWriter: ctx.Writer}
+	ctx.Writer = captura
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": "1"})
+
+	if captura.corpo.String() != w.Body.String() || w.Body.Len() == 0 {
+		t.Errorf("corpo capturado %q, enviado %q", captura.corpo.String(), w.Body.String())
+	}
+	if captura.Status() != http.StatusCreated {
+		t.Errorf("status capturado %d", captura.Status())
+	}
+}
+
+func TestChaveDoChamador(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	chave := func(ip string, headers map[string]string, idempotencyKey string) string {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/proposta", nil)
+		ctx.Request.RemoteAddr = ip + ":4321"
+		for nome, valor := range headers {
+			ctx.Request.Header.Set(nome, valor)
+		}
+		return chaveDoChamador(ctx, idempotencyKey)
+	}
+	bearer := map[string]string{"Authorization": "Bearer token-a"}
+	base := chave("192.0.2.1", bearer, "k1")
+	casos := []struct {
+		nome  string
+		chave string
+		igual bool
+	}{
+		{"mesmo token e mesma chave", chave("192.0.2.1", bearer, "k1"), true},
+		{"mesmo token de outro IP", chave("192.0.2.9", bearer, "k1"), true},
+		{"mesmo token em X-API-Key", chave("192.0.2.1", map[string]string{"X-API-Key": "token-a"}, "k1"), true},
+		{"outro token", chave("192.0.2.1", map[string]string{"Authorization": "Bearer token-b"}, "k1"), false},
+		{"outra chave", chave("192.0.2.1", bearer, "k2"), false},
+		{"sem token", chave("192.0.2.1", nil, "k1"), false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if (c.chave == base) != c.igual {
+				t.Errorf("chave igual = %v, esperado %v", c.chave == base, c.igual)
+			}
+		})
+	}
+
+	if chave("192.0.2.1", nil, "k1") == chave("192.0.2.2", nil, "k1") {
+		t.Error("sem token, chamadores de IPs diferentes compartilham a chave")
+	}
+	if len(chave("192.0.2.1", bearer, strings.Repeat("x", 255))) != 64 {
+		t.Error("chave armazenada não tem o tamanho do hash")
+	}
+}
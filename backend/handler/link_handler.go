@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+	"propulse/storage"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type LinkHandler struct {
+	linkService     service.LinkService
+	propostaService service.PropostaService
+}
+
+func NewLinkHandler(linkService service.LinkService, propostaService service.PropostaService) LinkHandler {
+	return LinkHandler{
+		linkService:     linkService,
+		propostaService: propostaService,
+	}
+}
+
+func (h *LinkHandler) CriarLink(ctx *gin.Context) {
+	var input model.CriarLink
+	// O corpo é opcional: sem ele valem a validade padrão e visualizações ilimitadas.
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := model.ValidarStructCriarLink(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	link, token, err := h.linkService.CriarLink(ctx.Request.Context(), ctx.Param("id"), input)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao criar link de compartilhamento", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"link":  link,
+		"token": token,
+		"url":   h.linkService.URL(token),
+	})
+}
+
+func (h *LinkHandler) ListarLinks(ctx *gin.Context) {
+	links, err := h.linkService.ListarLinks(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao listar links da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, links)
+}
+
+func (h *LinkHandler) RevogarLink(ctx *gin.Context) {
+	link, err := h.linkService.RevogarLink(ctx.Request.Context(), ctx.Param("id"), ctx.Param("linkId"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "link não encontrado"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao revogar link", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, link)
+}
+
+// AbrirLink é a rota pública do link compartilhado. Serve o PDF para
+// visualização no navegador ou, com ?formato=html, o HTML da proposta.
+// Requisições Range que continuam um download não contam como nova visualização.
+func (h *LinkHandler) AbrirLink(ctx *gin.Context) {
+	formato := ctx.DefaultQuery("formato", model.LinkFormatoPDF)
+	if formato != model.LinkFormatoPDF && formato != model.LinkFormatoHTML {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "formato deve ser pdf ou html"})
+		return
+	}
+	rangeHeader := ctx.GetHeader("Range")
+	contar := rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+
+	link, err := h.linkService.Resolver(ctx.Request.Context(), ctx.Param("token"), contar)
+	if errors.Is(err, service.ErrLinkInvalido) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrLinkExpirado) {
+		ctx.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao validar link"})
+		return
+	}
+
+	ctx.Header("X-Robots-Tag", "noindex")
+	ctx.Header("Referrer-Policy", "no-referrer")
+
+	if formato == model.LinkFormatoHTML {
+		proposta, err := h.propostaService.FindByID(ctx.Request.Context(), link.PropostaId.String())
+		if err != nil || proposta.Html == "" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não disponível"})
+			return
+		}
+		ctx.Header("Cache-Control", "private, no-store")
+		// O HTML vem da IA e é servido na origem da API: o sandbox o isola em
+		// uma origem opaca, sem scripts, formulários nem acesso a cookies.
+		ctx.Header("Content-Security-Policy", "sandbox")
+		ctx.Header("X-Content-Type-Options", "nosniff")
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(proposta.Html))
+		return
+	}
+
+	proposta, arquivo, info, err := h.propostaService.AbrirPDF(ctx.Request.Context(), link.PropostaId.String())
+	if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, service.ErrPDFIndisponivel) || errors.Is(err, storage.ErrNaoEncontrado) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "PDF da proposta não encontrado"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao abrir PDF do link compartilhado", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao abrir PDF"})
+		return
+	}
+	defer arquivo.Close()
+	servirPDF(ctx, proposta, arquivo, info, "inline")
+}
+
+func (h *LinkHandler) RegisterRoutes(router *gin.Engine) {
+	linkRoutes := router.Group("/proposta", AutenticacaoAPI())
+	{
+		linkRoutes.POST("/:id/links", h.CriarLink)
+		linkRoutes.GET("/:id/links", h.ListarLinks)
+		linkRoutes.DELETE("/:id/links/:linkId", h.RevogarLink)
+	}
+	router.GET("/p/:token", h.AbrirLink)
+}
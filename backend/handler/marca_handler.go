@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type MarcaHandler struct {
+	marcaService service.MarcaService
+}
+
+func NewMarcaHandler(marcaService service.MarcaService) MarcaHandler {
+	return MarcaHandler{
+		marcaService: marcaService,
+	}
+}
+
+// bindMarca lê e valida o corpo usado na criação e na atualização.
+func bindMarca(ctx *gin.Context) (*model.Marca, bool) {
+	var marca model.Marca
+	if err := ctx.ShouldBindJSON(&marca); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if err := model.ValidarStructMarca(&marca); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &marca, true
+}
+
+func (h *MarcaHandler) CriarMarca(ctx *gin.Context) {
+	input, ok := bindMarca(ctx)
+	if !ok {
+		return
+	}
+	marca, err := h.marcaService.CriarMarca(ctx.Request.Context(), *input)
+	if errors.Is(err, model.ErrMarcaDuplicada) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, marca)
+}
+
+func (h *MarcaHandler) ListarMarcas(ctx *gin.Context) {
+	marcas, err := h.marcaService.ListarMarcas(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, marcas)
+}
+
+func (h *MarcaHandler) FindMarca(ctx *gin.Context) {
+	marca, err := h.marcaService.FindMarca(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": model.ErrMarcaNaoEncontrada.Error()})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao buscar marca", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, marca)
+}
+
+func (h *MarcaHandler) AtualizarMarca(ctx *gin.Context) {
+	input, ok := bindMarca(ctx)
+	if !ok {
+		return
+	}
+	marca, err := h.marcaService.AtualizarMarca(ctx.Request.Context(), ctx.Param("id"), *input)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": model.ErrMarcaNaoEncontrada.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrMarcaDuplicada) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, marca)
+}
+
+func (h *MarcaHandler) DeleteMarca(ctx *gin.Context) {
+	err := h.marcaService.DeleteMarca(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": model.ErrMarcaNaoEncontrada.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrMarcaEmUso) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *MarcaHandler) RegisterRoutes(router *gin.Engine) {
+	marcaRoutes := router.Group("/marcas", AutenticacaoAPI())
+	{
+		marcaRoutes.POST("/", h.CriarMarca)
+		marcaRoutes.GET("/", h.ListarMarcas)
+		marcaRoutes.GET("/:id", h.FindMarca)
+		marcaRoutes.PUT("/:id", h.AtualizarMarca)
+		marcaRoutes.DELETE("/:id", h.DeleteMarca)
+	}
+}
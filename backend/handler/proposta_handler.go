@@ -1,22 +1,32 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"propulse/model"
 	"propulse/service"
 	"propulse/shared/logger"
+	"propulse/storage"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+const sseHeartbeat = 15 * time.Second
+
 type PropostaHandler struct {
-	propostaService service.PropostaService
+	propostaService     service.PropostaService
+	idempotenciaService service.IdempotenciaService
 }
 
-func NewPropostaHandler(service service.PropostaService) PropostaHandler {
+func NewPropostaHandler(service service.PropostaService, idempotencia service.IdempotenciaService) PropostaHandler {
 	return PropostaHandler{
-		propostaService: service,
+		propostaService:     service,
+		idempotenciaService: idempotencia,
 	}
 }
 
@@ -29,36 +39,78 @@ func (p *PropostaHandler) CriarProposta(ctx *gin.Context) {
 		return
 	}
 	err = model.ValidarStructProposta(&proposta)
+	if errors.Is(err, model.ErrItemInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		logger.Error("Erro ao passar no validador de Struct da Proposta", err)
 		ctx.JSON(http.StatusBadRequest, err)
 		return
 	}
-	propostaOutput, err := p.propostaService.CriarProposta(proposta)
+	propostaOutput, job, err := p.propostaService.CriarProposta(ctx.Request.Context(), proposta)
+	if errors.Is(err, model.ErrTransicaoInvalida) || errors.Is(err, model.ErrValidadeInvalida) ||
+		errors.Is(err, model.ErrClienteNaoEncontrado) || errors.Is(err, model.ErrDadosCliente) ||
+		errors.Is(err, model.ErrMarcaNaoEncontrada) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, err)
 		return
 	}
 	omitHTML(propostaOutput)
-	ctx.JSON(http.StatusCreated, propostaOutput)
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"jobId":    job.Id,
+		"proposta": propostaOutput,
+	})
 }
 
-func (p *PropostaHandler) GetAllPropostas(ctx *gin.Context) {
-	listasDePropostas, err := p.propostaService.GetAllPropostas()
+func (p *PropostaHandler) ListarPropostas(ctx *gin.Context) {
+	var filtro model.FiltroPropostas
+	if err := ctx.ShouldBindQuery(&filtro); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := model.ValidarStructFiltroPropostas(&filtro); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pagina, err := p.propostaService.ListarPropostas(ctx.Request.Context(), filtro)
+	if errors.Is(err, model.ErrFiltroInvalido) || errors.Is(err, model.ErrCursorInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		logger.Error("Erro ao buscar propostas", err)
-		ctx.JSON(http.StatusInternalServerError, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, pagina)
+}
+
+func (p *PropostaHandler) BuscarPropostas(ctx *gin.Context) {
+	var busca model.BuscaPropostas
+	if err := ctx.ShouldBindQuery(&busca); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := model.ValidarStructBuscaPropostas(&busca); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	for i := range *listasDePropostas {
-		omitHTML(&(*listasDePropostas)[i])
+	pagina, err := p.propostaService.BuscarPropostas(ctx.Request.Context(), busca)
+	if err != nil {
+		logger.Error("Erro ao buscar propostas", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	ctx.JSON(http.StatusOK, listasDePropostas)
+	ctx.JSON(http.StatusOK, pagina)
 }
 
 func (p *PropostaHandler) FindByID(ctx *gin.Context) {
 	ParamID := ctx.Param("id")
-	proposta, err := p.propostaService.FindByID(ParamID)
+	proposta, err := p.propostaService.FindByID(ctx.Request.Context(), ParamID)
 	if err != nil {
 		logger.Error("Erro para encontrar proposta", err)
 		ctx.JSON(http.StatusBadRequest, err)
@@ -80,7 +132,20 @@ func (p *PropostaHandler) UpdateProposta(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	proposta, err := p.propostaService.UpdateProposta(id, update)
+	update.Ator = atorDaRequisicao(ctx)
+	proposta, err := p.propostaService.UpdateProposta(ctx.Request.Context(), id, update)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if errors.Is(err, model.ErrStatusInvalido) || errors.Is(err, model.ErrValidadeInvalida) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrTransicaoInvalida) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -91,7 +156,11 @@ func (p *PropostaHandler) UpdateProposta(ctx *gin.Context) {
 
 func (p *PropostaHandler) DeleteProposta(ctx *gin.Context) {
 	idParam := ctx.Param("id")
-	err := p.propostaService.DeleteProposta(idParam)
+	err := p.propostaService.DeleteProposta(ctx.Request.Context(), idParam)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, err)
 		return
@@ -109,12 +178,28 @@ func (p *PropostaHandler) RegerarProposta(ctx *gin.Context) {
 		return
 	}
 	err = model.ValidarStructRegerarProposta(&propostaInput)
+	if errors.Is(err, model.ErrItemInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		logger.Error("Erro ao passar no validador de Struct da Proposta", err)
 		ctx.JSON(http.StatusBadRequest, err)
 		return
 	}
-	propostaOutput, err := p.propostaService.RegerarProposta(idParam, propostaInput)
+	propostaOutput, err := p.propostaService.RegerarProposta(ctx.Request.Context(), idParam, propostaInput)
+	if errors.Is(err, model.ErrClienteNaoEncontrado) || errors.Is(err, model.ErrMarcaNaoEncontrada) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrConteudoBloqueado) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrCircuitoAberto) {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, err)
 		return
@@ -123,18 +208,209 @@ func (p *PropostaHandler) RegerarProposta(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, propostaOutput)
 }
 
+func (p *PropostaHandler) FindJobByID(ctx *gin.Context) {
+	job, err := p.propostaService.FindJobByID(ctx.Request.Context(), ctx.Param("id"), ctx.Param("jobId"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "job não encontrado"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao buscar job de geração", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// EventosGeracao mantém um stream SSE com o progresso da geração da proposta.
+// O primeiro evento ("job") traz o estado atual do último job, e o stream
+// termina quando o PDF é salvo ou o cliente desconecta.
+func (p *PropostaHandler) EventosGeracao(ctx *gin.Context) {
+	job, eventos, cancelar, err := p.propostaService.AssinarEventos(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		logger.Error("Erro ao assinar eventos da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer cancelar()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	if job != nil {
+		ctx.SSEvent("job", job)
+		if job.Status == model.JobSucceeded || job.Status == model.JobFailed {
+			ctx.Writer.Flush()
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case evento, ok := <-eventos:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(evento.Tipo, evento)
+			return !evento.Final
+		case <-heartbeat.C:
+			ctx.SSEvent("ping", time.Now())
+			return true
+		}
+	})
+}
+
+func (p *PropostaHandler) ListarVersoes(ctx *gin.Context) {
+	versoes, err := p.propostaService.ListarVersoes(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao listar versões da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, versoes)
+}
+
+func (p *PropostaHandler) FindVersao(ctx *gin.Context) {
+	versao, err := p.propostaService.FindVersao(ctx.Request.Context(), ctx.Param("id"), ctx.Param("n"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "versão não encontrada"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao buscar versão da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, versao)
+}
+
+func (p *PropostaHandler) RestaurarVersao(ctx *gin.Context) {
+	proposta, err := p.propostaService.RestaurarVersao(ctx.Request.Context(), ctx.Param("id"), ctx.Param("n"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "versão não encontrada"})
+		return
+	}
+	if errors.Is(err, model.ErrConteudoBloqueado) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao restaurar versão da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	omitHTML(proposta)
+	ctx.JSON(http.StatusOK, proposta)
+}
+
+func (p *PropostaHandler) ListarArtefatos(ctx *gin.Context) {
+	artefatos, err := p.propostaService.ListarArtefatos(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao listar artefatos da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, artefatos)
+}
+
+func (p *PropostaHandler) ListarHistorico(ctx *gin.Context) {
+	historico, err := p.propostaService.ListarHistorico(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao listar histórico de status da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, historico)
+}
+
+// BaixarPDF serve o PDF atual da proposta. http.ServeContent cuida de Range,
+// If-Range e If-None-Match. Com ?inline=1 o navegador exibe o PDF em vez de baixá-lo.
+func (p *PropostaHandler) BaixarPDF(ctx *gin.Context) {
+	proposta, arquivo, info, err := p.propostaService.AbrirPDF(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "proposta não encontrada"})
+		return
+	}
+	if errors.Is(err, service.ErrPDFIndisponivel) || errors.Is(err, storage.ErrNaoEncontrado) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "PDF da proposta não encontrado"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao abrir PDF da proposta", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer arquivo.Close()
+
+	disposicao := "attachment"
+	if ctx.Query("inline") == "1" {
+		disposicao = "inline"
+	}
+	servirPDF(ctx, proposta, arquivo, info, disposicao)
+}
+
+// servirPDF escreve o PDF com os headers de download; o ETag vem do SHA-256
+// gravado na geração quando disponível.
+func servirPDF(ctx *gin.Context, proposta *model.Proposta, arquivo io.ReadSeeker, info storage.Info, disposicao string) {
+	etag := info.ETag
+	if proposta.ArquivoSha256 != nil {
+		etag = `"` + *proposta.ArquivoSha256 + `"`
+	}
+	ctx.Header("Content-Type", "application/pdf")
+	ctx.Header("Content-Disposition", contentDisposition(disposicao, proposta.NomeArquivoPDF()))
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, no-cache")
+	http.ServeContent(ctx.Writer, ctx.Request, "", info.ModificadoEm, arquivo)
+}
+
 func (h *PropostaHandler) RegisterRoutes(router *gin.Engine) {
 	propostaRoutes := router.Group("/proposta")
 	{
-		propostaRoutes.POST("/", h.CriarProposta)
-		propostaRoutes.POST("/:id/regerar", h.RegerarProposta)
-		propostaRoutes.GET("/", h.GetAllPropostas)
+		idempotencia := Idempotencia(h.idempotenciaService)
+		propostaRoutes.POST("/", idempotencia, h.CriarProposta)
+		propostaRoutes.POST("/:id/regerar", idempotencia, h.RegerarProposta)
+		propostaRoutes.GET("/", h.ListarPropostas)
+		propostaRoutes.GET("/busca", h.BuscarPropostas)
 		propostaRoutes.GET("/:id", h.FindByID)
+		propostaRoutes.GET("/:id/jobs/:jobId", h.FindJobByID)
+		propostaRoutes.GET("/:id/eventos", h.EventosGeracao)
+		propostaRoutes.GET("/:id/versoes", h.ListarVersoes)
+		propostaRoutes.GET("/:id/versoes/:n", h.FindVersao)
+		propostaRoutes.POST("/:id/versoes/:n/restaurar", h.RestaurarVersao)
+		propostaRoutes.GET("/:id/artefatos", h.ListarArtefatos)
+		propostaRoutes.GET("/:id/historico", h.ListarHistorico)
+		propostaRoutes.GET("/:id/pdf", AutenticacaoAPI(), h.BaixarPDF)
 		propostaRoutes.PATCH("/:id", h.UpdateProposta)
 		propostaRoutes.DELETE("/:id", h.DeleteProposta)
 	}
 }
 
+// atorDaRequisicao identifica quem fez a mudança pelo header X-Ator; sem ele a
+// mudança é atribuída à API.
+func atorDaRequisicao(ctx *gin.Context) string {
+	ator := strings.TrimSpace(ctx.GetHeader("X-Ator"))
+	if ator == "" || len(ator) > 255 {
+		return model.AtorAPI
+	}
+	return ator
+}
+
 func omitHTML(p *model.Proposta) {
 	if p == nil {
 		return
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RelatorioHandler struct {
+	relatorioService service.RelatorioService
+}
+
+func NewRelatorioHandler(relatorioService service.RelatorioService) RelatorioHandler {
+	return RelatorioHandler{
+		relatorioService: relatorioService,
+	}
+}
+
+func (h *RelatorioHandler) Pipeline(ctx *gin.Context) {
+	var filtro model.FiltroPipeline
+	if err := ctx.ShouldBindQuery(&filtro); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := model.ValidarStructFiltroPipeline(&filtro); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	relatorio, err := h.relatorioService.Pipeline(ctx.Request.Context(), filtro)
+	if errors.Is(err, model.ErrFiltroInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao gerar relatório do pipeline", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, relatorio)
+}
+
+func (h *RelatorioHandler) RegisterRoutes(router *gin.Engine) {
+	relatorioRoutes := router.Group("/relatorios", AutenticacaoAPI())
+	{
+		relatorioRoutes.GET("/pipeline", h.Pipeline)
+	}
+}
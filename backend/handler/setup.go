@@ -1,16 +1,99 @@
 package handler
 
 import (
+	"context"
+	"propulse/email"
 	"propulse/repository"
 	"propulse/service"
+	"propulse/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func SetupServices(db *pgxpool.Pool, router *gin.Engine) {
+// SetupServices registra as rotas e inicia os workers em segundo plano. Os
+// workers de geração e de webhook retornados encerram quando ctx for cancelado.
+func SetupServices(ctx context.Context, db *pgxpool.Pool, router *gin.Engine) (*service.GeracaoWorker, *service.WebhookWorker, error) {
 	PropostaRepo := repository.NewPropostaRepository(db)
-	PropostaService := service.NewPropostaService(PropostaRepo)
-	PropostaHandler := NewPropostaHandler(PropostaService)
+	GeracaoJobRepo := repository.NewGeracaoJobRepository(db)
+	EventoBroker := service.NewEventoBroker()
+	IAClient := service.NewIAClient(service.IAClientConfigFromEnv())
+	Gerador, err := service.NewGenerator(IAClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	PropostaVersaoRepo := repository.NewPropostaVersaoRepository(db)
+	ArtefatoRepo := repository.NewArtefatoRepository(db)
+	StatusHistoricoRepo := repository.NewStatusHistoricoRepository(db)
+	Store, err := storage.NewStoreFromEnv(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	ClienteRepo := repository.NewClienteRepository(db)
+	MarcaRepo := repository.NewMarcaRepository(db)
+	PropostaService := service.NewPropostaService(PropostaRepo, GeracaoJobRepo, PropostaVersaoRepo, ArtefatoRepo, StatusHistoricoRepo, ClienteRepo, MarcaRepo, EventoBroker, Gerador, Store)
+	GeracaoWorker, err := service.NewGeracaoWorker(PropostaService, GeracaoJobRepo)
+	if err != nil {
+		return nil, nil, err
+	}
+	IdempotenciaRepo := repository.NewIdempotenciaRepository(db)
+	IdempotenciaService, err := service.NewIdempotenciaService(IdempotenciaRepo)
+	if err != nil {
+		return nil, nil, err
+	}
+	IdempotenciaService.IniciarLimpeza(ctx)
+	PropostaHandler := NewPropostaHandler(PropostaService, IdempotenciaService)
 	PropostaHandler.RegisterRoutes(router)
-}
\ No newline at end of file
+	ClienteService := service.NewClienteService(ClienteRepo)
+	ClienteHandler := NewClienteHandler(ClienteService)
+	ClienteHandler.RegisterRoutes(router)
+	MarcaService := service.NewMarcaService(MarcaRepo)
+	MarcaHandler := NewMarcaHandler(MarcaService)
+	MarcaHandler.RegisterRoutes(router)
+	ExportacaoService := service.NewExportacaoService(PropostaRepo)
+	ExportacaoHandler := NewExportacaoHandler(ExportacaoService)
+	ExportacaoHandler.RegisterRoutes(router)
+	RelatorioRepo := repository.NewRelatorioRepository(db)
+	RelatorioService := service.NewRelatorioService(RelatorioRepo)
+	RelatorioHandler := NewRelatorioHandler(RelatorioService)
+	RelatorioHandler.RegisterRoutes(router)
+	LinkRepo := repository.NewLinkRepository(db)
+	LinkService, err := service.NewLinkService(LinkRepo, PropostaService)
+	if err != nil {
+		return nil, nil, err
+	}
+	LinkHandler := NewLinkHandler(LinkService, PropostaService)
+	LinkHandler.RegisterRoutes(router)
+	AceiteRepo := repository.NewAceiteRepository(db)
+	AceiteService := service.NewAceiteService(AceiteRepo, LinkService, PropostaService, Gerador)
+	AceiteHandler := NewAceiteHandler(AceiteService)
+	AceiteHandler.RegisterRoutes(router)
+	Mailer, err := email.NewMailerFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	EnvioRepo := repository.NewEnvioRepository(db)
+	EnvioService := service.NewEnvioService(EnvioRepo, PropostaService, LinkService, Mailer)
+	EnvioHandler := NewEnvioHandler(EnvioService)
+	EnvioHandler.RegisterRoutes(router)
+	WebhookRepo := repository.NewWebhookRepository(db)
+	WebhookService := service.NewWebhookService(WebhookRepo)
+	WebhookHandler := NewWebhookHandler(WebhookService)
+	WebhookHandler.RegisterRoutes(router)
+	WebhookWorker := service.NewWebhookWorker(WebhookRepo)
+	WebhookWorker.Start(ctx)
+	TravaRepo := repository.NewTravaRepository(db)
+	ColetorOrfaos := service.NewColetorOrfaos(Store, ArtefatoRepo, TravaRepo)
+	ColetorOrfaos.Iniciar(ctx)
+	AgendadorExpiracao := service.NewAgendadorExpiracao(PropostaRepo, TravaRepo)
+	AgendadorExpiracao.Iniciar(ctx)
+	DiagnosticoHandler := NewDiagnosticoHandler(IAClient, ColetorOrfaos)
+	DiagnosticoHandler.RegisterRoutes(router)
+	if LocalStore, ok := Store.(*storage.LocalStore); ok {
+		ArquivoHandler := NewArquivoHandler(LocalStore)
+		ArquivoHandler.RegisterRoutes(router)
+	}
+
+	GeracaoWorker.Start(ctx)
+	return GeracaoWorker, WebhookWorker, nil
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"propulse/model"
+	"propulse/service"
+	"propulse/shared/logger"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) CriarWebhook(ctx *gin.Context) {
+	var input model.CriarWebhook
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := model.ValidarStructCriarWebhook(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	webhook, err := h.webhookService.CriarWebhook(ctx.Request.Context(), input)
+	if errors.Is(err, model.ErrDestinoWebhookInvalido) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao criar webhook", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// O segredo só é devolvido na criação; nas demais rotas fica omitido.
+	ctx.JSON(http.StatusCreated, gin.H{
+		"webhook": webhook,
+		"segredo": webhook.Segredo,
+	})
+}
+
+func (h *WebhookHandler) ListarWebhooks(ctx *gin.Context) {
+	webhooks, err := h.webhookService.ListarWebhooks(ctx.Request.Context())
+	if err != nil {
+		logger.Error("Erro ao listar webhooks", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) FindWebhook(ctx *gin.Context) {
+	webhook, err := h.webhookService.FindWebhook(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "webhook não encontrado"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(ctx *gin.Context) {
+	err := h.webhookService.DeleteWebhook(ctx.Request.Context(), ctx.Param("id"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "webhook não encontrado"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListarEntregas(ctx *gin.Context) {
+	status := ctx.Query("status")
+	if status != "" && !model.EntregaStatusValido(status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "status de entrega inválido"})
+		return
+	}
+	limite := 0
+	if valor := ctx.Query("limite"); valor != "" {
+		var err error
+		if limite, err = strconv.Atoi(valor); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limite inválido"})
+			return
+		}
+	}
+	entregas, err := h.webhookService.ListarEntregas(ctx.Request.Context(), ctx.Param("id"), status, limite)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "webhook não encontrado"})
+		return
+	}
+	if err != nil {
+		logger.Error("Erro ao listar entregas do webhook", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, entregas)
+}
+
+func (h *WebhookHandler) ReenviarEntrega(ctx *gin.Context) {
+	entrega, err := h.webhookService.ReenviarEntrega(ctx.Request.Context(), ctx.Param("id"), ctx.Param("entregaId"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "entrega não encontrada"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, entrega)
+}
+
+func (h *WebhookHandler) RegisterRoutes(router *gin.Engine) {
+	webhookRoutes := router.Group("/webhooks", AutenticacaoAPI())
+	{
+		webhookRoutes.POST("/", h.CriarWebhook)
+		webhookRoutes.GET("/", h.ListarWebhooks)
+		webhookRoutes.GET("/:id", h.FindWebhook)
+		webhookRoutes.DELETE("/:id", h.DeleteWebhook)
+		webhookRoutes.GET("/:id/entregas", h.ListarEntregas)
+		webhookRoutes.POST("/:id/entregas/:entregaId/reenviar", h.ReenviarEntrega)
+	}
+}
@@ -0,0 +1,16 @@
+CREATE TABLE geracao_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL REFERENCES propostas(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+    tentativas INT NOT NULL DEFAULT 0,
+    max_tentativas INT NOT NULL DEFAULT 3,
+    ultimo_erro TEXT,
+    proxima_execucao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_update TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finalizado_em TIMESTAMPTZ
+);
+
+CREATE INDEX idx_geracao_jobs_fila ON geracao_jobs (status, proxima_execucao);
+CREATE INDEX idx_geracao_jobs_proposta ON geracao_jobs (proposta_id);
@@ -0,0 +1,11 @@
+CREATE TABLE idempotency_keys (
+    chave VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT,
+    content_type VARCHAR(255),
+    resposta BYTEA,
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expira_em TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expira_em ON idempotency_keys (expira_em);
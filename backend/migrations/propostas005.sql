@@ -0,0 +1,18 @@
+CREATE TABLE proposta_versoes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL REFERENCES propostas(id) ON DELETE CASCADE,
+    numero INT NOT NULL,
+    origem VARCHAR(30) NOT NULL,
+    restaurada_de INT,
+    titulo VARCHAR(100) NOT NULL,
+    nome_empresa VARCHAR(255) NOT NULL,
+    nome_cliente VARCHAR(255) NOT NULL,
+    prompt TEXT NOT NULL,
+    cores TEXT[] NOT NULL,
+    logo VARCHAR(255),
+    logo_cliente VARCHAR(255),
+    html TEXT NOT NULL,
+    arquivo_final VARCHAR(255),
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (proposta_id, numero)
+);
@@ -0,0 +1,18 @@
+ALTER TABLE propostas
+ADD COLUMN arquivo_sha256 CHAR(64),
+ADD COLUMN arquivo_tamanho BIGINT;
+
+ALTER TABLE proposta_versoes
+ADD COLUMN arquivo_sha256 CHAR(64),
+ADD COLUMN arquivo_tamanho BIGINT;
+
+CREATE TABLE proposta_artefatos (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL REFERENCES propostas(id) ON DELETE CASCADE,
+    caminho VARCHAR(255) NOT NULL UNIQUE,
+    sha256 CHAR(64) NOT NULL,
+    tamanho BIGINT NOT NULL,
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_proposta_artefatos_proposta ON proposta_artefatos (proposta_id, data_criacao DESC);
@@ -0,0 +1,15 @@
+-- arquivo_final passa a guardar a chave do objeto no storage (ex.: "propostas/proposta_<id>.pdf")
+-- em vez do caminho em disco relativo ao container ("uploads/propostas/...").
+ALTER TABLE proposta_artefatos RENAME COLUMN caminho TO chave;
+
+UPDATE propostas
+SET arquivo_final = regexp_replace(arquivo_final, '^(\./)?uploads/', '')
+WHERE arquivo_final ~ '^(\./)?uploads/';
+
+UPDATE proposta_versoes
+SET arquivo_final = regexp_replace(arquivo_final, '^(\./)?uploads/', '')
+WHERE arquivo_final ~ '^(\./)?uploads/';
+
+UPDATE proposta_artefatos
+SET chave = regexp_replace(chave, '^(\./)?uploads/', '')
+WHERE chave ~ '^(\./)?uploads/';
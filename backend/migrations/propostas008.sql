@@ -0,0 +1,11 @@
+CREATE TABLE proposta_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL REFERENCES propostas(id) ON DELETE CASCADE,
+    expira_em TIMESTAMPTZ NOT NULL,
+    max_visualizacoes INTEGER CHECK (max_visualizacoes > 0),
+    visualizacoes INTEGER NOT NULL DEFAULT 0,
+    revogado_em TIMESTAMPTZ,
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_proposta_links_proposta ON proposta_links (proposta_id, data_criacao DESC);
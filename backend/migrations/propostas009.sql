@@ -0,0 +1,4 @@
+-- Índices usados pelo coletor de órfãos para conferir se uma chave do storage
+-- ainda é referenciada.
+CREATE INDEX idx_propostas_arquivo_final ON propostas (arquivo_final);
+CREATE INDEX idx_proposta_versoes_arquivo_final ON proposta_versoes (arquivo_final);
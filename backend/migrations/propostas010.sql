@@ -0,0 +1,16 @@
+CREATE TABLE proposta_status_historico (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL REFERENCES propostas(id) ON DELETE CASCADE,
+    de_status VARCHAR(50),
+    para_status VARCHAR(50) NOT NULL,
+    ator VARCHAR(255) NOT NULL,
+    motivo TEXT,
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_proposta_status_historico_proposta ON proposta_status_historico (proposta_id, data_criacao);
+
+-- Registro inicial para as propostas que já existiam antes do histórico.
+INSERT INTO proposta_status_historico (proposta_id, de_status, para_status, ator, motivo, data_criacao)
+SELECT id, NULL, status, 'sistema', 'registro inicial do histórico', data_criacao
+FROM propostas;
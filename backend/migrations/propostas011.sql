@@ -0,0 +1,14 @@
+CREATE TABLE proposta_aceites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL UNIQUE REFERENCES propostas(id) ON DELETE CASCADE,
+    link_id UUID NOT NULL REFERENCES proposta_links(id) ON DELETE CASCADE,
+    decisao VARCHAR(20) NOT NULL CHECK (decisao IN ('aceitar', 'recusar')),
+    nome VARCHAR(255) NOT NULL,
+    comentario TEXT,
+    ip TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    documento_sha256 CHAR(64) NOT NULL,
+    certificado_chave VARCHAR(255),
+    certificado_sha256 CHAR(64),
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,4 @@
+ALTER TABLE propostas ADD COLUMN valido_ate TIMESTAMPTZ;
+
+-- Atende a varredura do agendador de expiração, que só olha propostas enviadas.
+CREATE INDEX idx_propostas_enviadas_valido_ate ON propostas (valido_ate) WHERE status = 'enviado';
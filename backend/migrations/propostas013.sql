@@ -0,0 +1,16 @@
+CREATE TABLE proposta_envios (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL REFERENCES propostas(id) ON DELETE CASCADE,
+    para TEXT[] NOT NULL,
+    cc TEXT[] NOT NULL DEFAULT '{}',
+    assunto TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pendente', 'enviado', 'falhou')),
+    message_id TEXT,
+    erro TEXT,
+    documento_sha256 VARCHAR(64),
+    ator VARCHAR(255) NOT NULL,
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    data_envio TIMESTAMPTZ
+);
+
+CREATE INDEX idx_proposta_envios_proposta ON proposta_envios (proposta_id, data_criacao);
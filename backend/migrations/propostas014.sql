@@ -0,0 +1,30 @@
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    url TEXT NOT NULL,
+    eventos TEXT[] NOT NULL,
+    segredo TEXT NOT NULL,
+    ativo BOOLEAN NOT NULL DEFAULT true,
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Log persistente de entregas. proposta_id não referencia propostas porque os
+-- eventos de exclusão precisam sobreviver à proposta.
+CREATE TABLE webhook_entregas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    evento_id UUID NOT NULL,
+    evento VARCHAR(100) NOT NULL,
+    proposta_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pendente', 'entregando', 'entregue', 'falhou')),
+    tentativas INT NOT NULL DEFAULT 0,
+    proxima_tentativa TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ultimo_status_http INT,
+    ultimo_erro TEXT,
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_update TIMESTAMPTZ NOT NULL DEFAULT now(),
+    entregue_em TIMESTAMPTZ
+);
+
+CREATE INDEX idx_webhook_entregas_fila ON webhook_entregas (proxima_tentativa) WHERE status IN ('pendente', 'entregando');
+CREATE INDEX idx_webhook_entregas_webhook ON webhook_entregas (webhook_id, data_criacao DESC);
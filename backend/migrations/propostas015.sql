@@ -0,0 +1,6 @@
+-- Índices da listagem paginada: cada ordenação aceita em sort usa (coluna, id)
+-- como chave do cursor.
+CREATE INDEX idx_propostas_data_criacao ON propostas (data_criacao, id);
+CREATE INDEX idx_propostas_last_update ON propostas (last_update, id);
+CREATE INDEX idx_propostas_titulo ON propostas (titulo, id);
+CREATE INDEX idx_propostas_status ON propostas (status);
@@ -0,0 +1,33 @@
+-- Texto visível do HTML gerado: remove estilos, scripts, tags e as entidades
+-- mais comuns. Usada pela busca e pelos trechos destacados.
+CREATE FUNCTION proposta_texto_html(html TEXT) RETURNS TEXT AS $$
+    SELECT replace(replace(replace(
+        regexp_replace(
+            regexp_replace(coalesce(html, ''), '<(style|script)[^>]*>.*?</\1>', ' ', 'gi'),
+            '<[^>]*>', ' ', 'g'),
+        '&nbsp;', ' '), '&amp;', '&'), '&quot;', '"')
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE propostas ADD COLUMN busca TSVECTOR;
+
+-- O título pesa mais que empresa e cliente, que pesam mais que o prompt e o
+-- conteúdo gerado.
+CREATE FUNCTION propostas_atualizar_busca() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.busca :=
+        setweight(to_tsvector('portuguese', coalesce(NEW.titulo, '')), 'A') ||
+        setweight(to_tsvector('portuguese', coalesce(NEW.nome_empresa, '') || ' ' || coalesce(NEW.nome_cliente, '')), 'B') ||
+        setweight(to_tsvector('portuguese', coalesce(NEW.prompt, '')), 'C') ||
+        setweight(to_tsvector('portuguese', proposta_texto_html(NEW.html)), 'D');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Cobre a criação, a geração e a regeneração do HTML e a restauração de versões.
+CREATE TRIGGER trg_propostas_busca
+    BEFORE INSERT OR UPDATE OF titulo, nome_empresa, nome_cliente, prompt, html ON propostas
+    FOR EACH ROW EXECUTE FUNCTION propostas_atualizar_busca();
+
+UPDATE propostas SET titulo = titulo;
+
+CREATE INDEX idx_propostas_busca ON propostas USING GIN (busca);
@@ -0,0 +1,19 @@
+CREATE TABLE clientes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    nome_empresa VARCHAR(255) NOT NULL,
+    -- Só os 14 caracteres, sem pontuação; aceita o formato alfanumérico.
+    cnpj VARCHAR(14) UNIQUE,
+    contatos JSONB NOT NULL DEFAULT '[]',
+    emails TEXT[] NOT NULL DEFAULT '{}',
+    logo VARCHAR(255),
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_update TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_clientes_nome_empresa ON clientes (lower(nome_empresa));
+
+-- Propostas antigas continuam sem cliente. Um cliente com propostas não pode
+-- ser removido.
+ALTER TABLE propostas ADD COLUMN cliente_id UUID REFERENCES clientes(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_propostas_cliente ON propostas (cliente_id);
@@ -0,0 +1,15 @@
+-- Itens da tabela de preços da proposta. Só as entradas são gravadas;
+-- subtotais, descontos, impostos e totais são calculados pelo backend.
+CREATE TABLE proposta_itens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposta_id UUID NOT NULL REFERENCES propostas(id) ON DELETE CASCADE,
+    posicao INT NOT NULL,
+    descricao TEXT NOT NULL,
+    quantidade NUMERIC(14,4) NOT NULL CHECK (quantidade > 0),
+    unidade VARCHAR(20) NOT NULL DEFAULT 'un',
+    preco_unitario NUMERIC(14,2) NOT NULL CHECK (preco_unitario >= 0),
+    -- percentuais
+    desconto NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (desconto BETWEEN 0 AND 100),
+    imposto NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (imposto BETWEEN 0 AND 100),
+    UNIQUE (proposta_id, posicao)
+);
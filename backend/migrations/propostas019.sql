@@ -0,0 +1,22 @@
+CREATE TABLE marcas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    nome VARCHAR(100) NOT NULL,
+    cores_primarias TEXT[] NOT NULL,
+    cores_secundarias TEXT[] NOT NULL DEFAULT '{}',
+    logo VARCHAR(255),
+    fonte VARCHAR(100),
+    rodape TEXT,
+    contato JSONB NOT NULL DEFAULT '{}',
+    data_criacao TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_update TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_marcas_nome ON marcas (lower(nome));
+
+-- marca guarda o estilo efetivo (kit com as sobrescritas da proposta) com que
+-- a proposta foi gerada; editar o kit não altera propostas já criadas. Uma
+-- marca com propostas não pode ser removida.
+ALTER TABLE propostas ADD COLUMN marca_id UUID REFERENCES marcas(id) ON DELETE RESTRICT;
+ALTER TABLE propostas ADD COLUMN marca JSONB;
+
+CREATE INDEX idx_propostas_marca ON propostas (marca_id);
@@ -0,0 +1,27 @@
+-- Corrige a remoção de <style> e <script> de proposta_texto_html. Em uma ARE
+-- do Postgres o primeiro quantificador ([^>]*) torna a expressão toda gulosa,
+-- então o .*? da versão anterior não era preguiçoso e, com dois blocos, apagava
+-- também o texto entre eles. O conteúdo do bloco agora não pode conter "</",
+-- e o casamento termina no primeiro fechamento.
+CREATE OR REPLACE FUNCTION proposta_texto_html(html TEXT) RETURNS TEXT AS $$
+    SELECT replace(replace(replace(
+        regexp_replace(
+            regexp_replace(coalesce(html, ''), '<(style|script)[^>]*>[^<]*(<[^/][^<]*)*</\1>', ' ', 'gi'),
+            '<[^>]*>', ' ', 'g'),
+        '&nbsp;', ' '), '&amp;', '&'), '&quot;', '"')
+$$ LANGUAGE SQL IMMUTABLE;
+
+DO $$
+DECLARE
+    texto TEXT := proposta_texto_html(
+        '<style>h1 { color: red; }</style><p>Primeiro</p><style type="text/css">p { margin: 0; }</style>'
+        || '<p>Segundo</p><script>if (a < b) { x(); }</script><p>Terceiro</p>');
+BEGIN
+    IF texto NOT LIKE '%Primeiro%Segundo%Terceiro%' OR texto LIKE '%color%' OR texto LIKE '%margin%' OR texto LIKE '%x()%' THEN
+        RAISE EXCEPTION 'proposta_texto_html removeu texto visível ou manteve estilos: %', texto;
+    END IF;
+END;
+$$;
+
+-- Recalcula a busca das propostas existentes com a função corrigida.
+UPDATE propostas SET titulo = titulo;
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	DecisaoAceitar = "aceitar"
+	DecisaoRecusar = "recusar"
+)
+
+var ErrDocumentoDivergente = errors.New("o documento foi alterado depois de exibido ao cliente")
+
+var ErrAceiteJaRegistrado = errors.New("a proposta já possui uma decisão registrada")
+
+// RegistrarAceite é o que o cliente envia pelo link público. DocumentoSha256 é
+// o hash do PDF exibido na página de aceite; quando informado, precisa bater
+// com o PDF atual da proposta.
+type RegistrarAceite struct {
+	Nome            string `json:"nome" form:"nome" validate:"required,min=3,max=255"`
+	Decisao         string `json:"decisao" form:"decisao" validate:"required,oneof=aceitar recusar"`
+	Comentario      string `json:"comentario" form:"comentario" validate:"max=2000"`
+	DocumentoSha256 string `json:"documentoSha256" form:"documentoSha256" validate:"omitempty,len=64,hexadecimal"`
+}
+
+func ValidarStructRegistrarAceite(a *RegistrarAceite) error {
+	return validator.New().Struct(a)
+}
+
+// StatusDestino devolve o status da proposta correspondente à decisão.
+func (a RegistrarAceite) StatusDestino() string {
+	if a.Decisao == DecisaoAceitar {
+		return StatusAprovado
+	}
+	return StatusRecusado
+}
+
+// Aceite guarda a evidência da decisão do cliente: quem, quando, de onde e
+// sobre qual documento (hash SHA-256 do PDF vigente no momento da decisão).
+type Aceite struct {
+	Id                uuid.UUID `json:"id"`
+	PropostaId        uuid.UUID `json:"propostaId"`
+	LinkId            uuid.UUID `json:"linkId"`
+	Decisao           string    `json:"decisao"`
+	Nome              string    `json:"nome"`
+	Comentario        *string   `json:"comentario,omitempty"`
+	Ip                string    `json:"ip"`
+	UserAgent         string    `json:"userAgent"`
+	DocumentoSha256   string    `json:"documentoSha256"`
+	CertificadoChave  *string   `json:"certificadoChave,omitempty"`
+	CertificadoSha256 *string   `json:"certificadoSha256,omitempty"`
+	DataCriacao       time.Time `json:"dataCriacao"`
+}
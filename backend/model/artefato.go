@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArtefatoPDF descreve um PDF renderizado e armazenado para uma proposta.
+type ArtefatoPDF struct {
+	Id          uuid.UUID `json:"id"`
+	PropostaId  uuid.UUID `json:"propostaId"`
+	Chave       string    `json:"chave"`
+	Sha256      string    `json:"sha256"`
+	Tamanho     int64     `json:"tamanho"`
+	DataCriacao time.Time `json:"dataCriacao"`
+}
+
+// PrefixoChavePDF é o início de toda chave gravada para a proposta no storage.
+// Só chaves com esse prefixo são removidas junto com a proposta.
+func PrefixoChavePDF(propostaID uuid.UUID) string {
+	return "propostas/proposta_" + propostaID.String() + "_"
+}
@@ -0,0 +1,30 @@
+package model
+
+import "github.com/go-playground/validator/v10"
+
+// BuscaPropostas são os parâmetros de GET /proposta/busca. Q aceita a sintaxe
+// de busca web do Postgres: palavras, "frases entre aspas", OR e -exclusão.
+type BuscaPropostas struct {
+	Q      string `form:"q" validate:"required,min=2,max=200"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+// ResultadoBusca é uma proposta encontrada (sem o HTML) com a relevância e um
+// trecho em que os termos buscados aparecem entre <mark> e </mark>.
+type ResultadoBusca struct {
+	Proposta Proposta `json:"proposta"`
+	Rank     float32  `json:"rank"`
+	Trecho   string   `json:"trecho"`
+}
+
+type PaginaBusca struct {
+	Itens  []ResultadoBusca `json:"itens"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+func ValidarStructBuscaPropostas(b *BuscaPropostas) error {
+	return validator.New().Struct(b)
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var ErrClienteNaoEncontrado = errors.New("cliente não encontrado")
+
+var ErrClienteDuplicado = errors.New("já existe um cliente com este CNPJ")
+
+var ErrClienteEmUso = errors.New("o cliente possui propostas e não pode ser removido")
+
+// ErrDadosCliente indica uma proposta sem empresa ou contato do cliente, nem
+// informados nem resolvidos a partir do clienteId.
+var ErrDadosCliente = errors.New("informe nomeEmpresa e nomeCliente ou um clienteId com contato cadastrado")
+
+type ContatoCliente struct {
+	Nome     string `json:"nome" validate:"required,max=255"`
+	Cargo    string `json:"cargo,omitempty" validate:"omitempty,max=100"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Telefone string `json:"telefone,omitempty" validate:"omitempty,max=30"`
+}
+
+// Cliente centraliza os dados da empresa cliente usados nas propostas. O
+// primeiro contato é o principal e vira o nomeCliente das propostas.
+type Cliente struct {
+	Id          uuid.UUID        `json:"id"`
+	NomeEmpresa string           `json:"nomeEmpresa" validate:"required,min=2,max=255"`
+	Cnpj        *string          `json:"cnpj,omitempty" validate:"omitempty,cnpj"`
+	Contatos    []ContatoCliente `json:"contatos" validate:"max=20,dive"`
+	Emails      []string         `json:"emails" validate:"max=20,dive,email,max=255"`
+	Logo        string           `json:"logo,omitempty" validate:"omitempty,url,max=255"`
+	DataCriacao time.Time        `json:"dataCriacao"`
+	LastUpdate  time.Time        `json:"lastUpdate"`
+}
+
+// ContatoPrincipal devolve o nome do primeiro contato, ou vazio.
+func (c *Cliente) ContatoPrincipal() string {
+	if len(c.Contatos) == 0 {
+		return ""
+	}
+	return c.Contatos[0].Nome
+}
+
+// Normalizar deixa o CNPJ só com os 14 caracteres, em maiúsculas, e troca
+// listas nulas por vazias.
+func (c *Cliente) Normalizar() {
+	if c.Cnpj != nil {
+		cnpj := NormalizarCNPJ(*c.Cnpj)
+		c.Cnpj = &cnpj
+		if cnpj == "" {
+			c.Cnpj = nil
+		}
+	}
+	if c.Contatos == nil {
+		c.Contatos = []ContatoCliente{}
+	}
+	if c.Emails == nil {
+		c.Emails = []string{}
+	}
+}
+
+// validateCliente é criado uma vez: o validator guarda em cache a análise das
+// structs e pode ser usado por várias goroutines.
+var validateCliente = novoValidateCliente()
+
+func novoValidateCliente() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterValidation("cnpj", func(fl validator.FieldLevel) bool {
+		return CNPJValido(fl.Field().String())
+	})
+	return validate
+}
+
+func ValidarStructCliente(c *Cliente) error {
+	return validateCliente.Struct(c)
+}
+
+// NormalizarCNPJ remove pontos, barra, hífen e espaços.
+func NormalizarCNPJ(cnpj string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '/', '-', ' ':
+			return -1
+		}
+		return r
+	}, cnpj))
+}
+
+// CNPJValido confere o tamanho e os dígitos verificadores, aceitando o CNPJ
+// numérico e o alfanumérico: os 12 primeiros caracteres podem ser letras, que
+// valem o código ASCII menos 48, e os dois últimos são sempre dígitos.
+func CNPJValido(cnpj string) bool {
+	cnpj = NormalizarCNPJ(cnpj)
+	if len(cnpj) != 14 {
+		return false
+	}
+	valores := make([]int, 14)
+	todosIguais := true
+	for i := 0; i < 14; i++ {
+		c := cnpj[i]
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'A' && c <= 'Z' && i < 12:
+		default:
+			return false
+		}
+		valores[i] = int(c) - '0'
+		todosIguais = todosIguais && c == cnpj[0]
+	}
+	if todosIguais {
+		return false
+	}
+	return digitoCNPJ(valores[:12]) == valores[12] && digitoCNPJ(valores[:13]) == valores[13]
+}
+
+// digitoCNPJ calcula o dígito verificador com pesos de 2 a 9 aplicados da
+// direita para a esquerda, em módulo 11.
+func digitoCNPJ(valores []int) int {
+	soma := 0
+	peso := 2
+	for i := len(valores) - 1; i >= 0; i-- {
+		soma += valores[i] * peso
+		peso++
+		if peso > 9 {
+			peso = 2
+		}
+	}
+	resto := soma % 11
+	if resto < 2 {
+		return 0
+	}
+	return 11 - resto
+}
@@ -0,0 +1,88 @@
+package model
+
+import "testing"
+
+func TestCNPJValido(t *testing.T) {
+	casos := []struct {
+		nome   string
+		cnpj   string
+		valido bool
+	}{
+		{"numérico formatado", "11.222.333/0001-81", true},
+		{"numérico sem pontuação", "11444777000161", true},
+		{"numérico com espaços", " 11 444 777 0001 61 ", true},
+		{"alfanumérico formatado", "12.ABC.345/01DE-35", true},
+		{"alfanumérico sem pontuação", "12ABC34501DE35", true},
+		{"alfanumérico só letras na raiz", "ABCDEFGHIJKL80", true},
+		{"alfanumérico intercalado", "A1B2C3D4E5F668", true},
+		{"alfanumérico minúsculo", "12.abc.345/01de-35", true},
+		{"minúsculas intercaladas", "a1b2c3d4e5f668", true},
+		{"primeiro dígito errado", "11.222.333/0001-71", false},
+		{"segundo dígito errado", "11.222.333/0001-82", false},
+		{"dígitos trocados", "11.222.333/0001-18", false},
+		{"alfanumérico com dígito errado", "12ABC34501DE36", false},
+		{"raiz alterada", "12ABC34501DF35", false},
+		{"todos zeros", "00.000.000/0000-00", false},
+		{"todos iguais", "11111111111111", false},
+		{"todos noves", "99999999999999", false},
+		{"letra no primeiro dígito verificador", "12ABC34501DEA5", false},
+		{"letra no segundo dígito verificador", "12ABC34501DE3A", false},
+		{"letras nos dois verificadores", "12ABC34501DEAB", false},
+		{"curto", "1122233300018", false},
+		{"longo", "112223330001811", false},
+		{"vazio", "", false},
+		{"caractere especial", "11222333#00181", false},
+		{"letra acentuada", "12ÁBC34501DE35", false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if got := CNPJValido(c.cnpj); got != c.valido {
+				t.Errorf("CNPJValido(%q) = %v, esperado %v", c.cnpj, got, c.valido)
+			}
+		})
+	}
+}
+
+func TestNormalizarCNPJ(t *testing.T) {
+	casos := []struct {
+		entrada  string
+		esperado string
+	}{
+		{"11.222.333/0001-81", "11222333000181"},
+		{"12.abc.345/01de-35", "12ABC34501DE35"},
+		{" 12 ABC 345 01DE 35 ", "12ABC34501DE35"},
+		{"11222333000181", "11222333000181"},
+		{"", ""},
+	}
+	for _, c := range casos {
+		if got := NormalizarCNPJ(c.entrada); got != c.esperado {
+			t.Errorf("NormalizarCNPJ(%q) = %q, esperado %q", c.entrada, got, c.esperado)
+		}
+	}
+}
+
+func TestValidarStructClienteCNPJ(t *testing.T) {
+	valido := "11.222.333/0001-81"
+	invalido := "11.222.333/0001-82"
+	casos := []struct {
+		nome   string
+		cnpj   *string
+		valido bool
+	}{
+		{"sem CNPJ", nil, true},
+		{"CNPJ válido", &valido, true},
+		{"CNPJ inválido", &invalido, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cliente := &Cliente{NomeEmpresa: "Cliente S.A.", Cnpj: c.cnpj}
+			// Duas chamadas seguidas usam o mesmo validator do pacote.
+			for range 2 {
+				err := ValidarStructCliente(cliente)
+				if c.valido != (err == nil) {
+					t.Fatalf("ValidarStructCliente: %v", err)
+				}
+			}
+		})
+	}
+}
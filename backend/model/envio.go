@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	EnvioPendente = "pendente"
+	EnvioEnviado  = "enviado"
+	EnvioFalhou   = "falhou"
+)
+
+var ErrTemplateInvalido = errors.New("template de assunto ou corpo inválido")
+
+// EnviarProposta é o pedido de envio da proposta por e-mail. Assunto e Corpo
+// são templates (text/template) com os campos de DadosEmailProposta; vazios,
+// valem os textos padrão. Com IncluirLink, um link de compartilhamento é
+// criado e fica disponível no corpo.
+type EnviarProposta struct {
+	Para        []string `json:"para" validate:"required,min=1,max=50,dive,email"`
+	Cc          []string `json:"cc" validate:"omitempty,max=50,dive,email"`
+	Assunto     string   `json:"assunto" validate:"omitempty,max=255"`
+	Corpo       string   `json:"corpo" validate:"omitempty,max=20000"`
+	IncluirLink bool     `json:"incluirLink"`
+}
+
+func ValidarStructEnviarProposta(e *EnviarProposta) error {
+	return validator.New().Struct(e)
+}
+
+// DadosEmailProposta são os campos disponíveis nos templates do e-mail.
+type DadosEmailProposta struct {
+	Titulo      string
+	NomeEmpresa string
+	NomeCliente string
+	ValidoAte   string
+	Link        string
+	LinkAceite  string
+}
+
+// EnvioEmail registra cada tentativa de envio da proposta por e-mail. O
+// MessageId é o do cabeçalho Message-ID, útil para rastrear a mensagem no
+// servidor de e-mail.
+type EnvioEmail struct {
+	Id              uuid.UUID  `json:"id"`
+	PropostaId      uuid.UUID  `json:"propostaId"`
+	Para            []string   `json:"para"`
+	Cc              []string   `json:"cc"`
+	Assunto         string     `json:"assunto"`
+	Status          string     `json:"status"`
+	MessageId       *string    `json:"messageId,omitempty"`
+	Erro            *string    `json:"erro,omitempty"`
+	DocumentoSha256 *string    `json:"documentoSha256,omitempty"`
+	Ator            string     `json:"ator"`
+	DataCriacao     time.Time  `json:"dataCriacao"`
+	DataEnvio       *time.Time `json:"dataEnvio,omitempty"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidarStructEnviarPropostaEnderecos(t *testing.T) {
+	muitos := []string{}
+	for n := 0; n < 51; n++ {
+		muitos = append(muitos, fmt.Sprintf("cliente%d@empresa.com", n))
+	}
+	casos := []struct {
+		nome   string
+		envio  EnviarProposta
+		valido bool
+	}{
+		{"um destinatário", EnviarProposta{Para: []string{"maria@cliente.com"}}, true},
+		{"com cópia", EnviarProposta{Para: []string{"maria@cliente.com"}, Cc: []string{"compras@cliente.com.br"}}, true},
+		{"sem destinatários", EnviarProposta{}, false},
+		{"lista vazia", EnviarProposta{Para: []string{}}, false},
+		{"sem arroba", EnviarProposta{Para: []string{"maria.cliente.com"}}, false},
+		{"com nome", EnviarProposta{Para: []string{"Maria <maria@cliente.com>"}}, false},
+		{"CRLF com Bcc injetado", EnviarProposta{Para: []string{"maria@cliente.com\r\nBcc: intruso@externo.com"}}, false},
+		{"LF com cabeçalho injetado", EnviarProposta{Para: []string{"maria@cliente.com\nSubject: falso"}}, false},
+		{"CR no fim", EnviarProposta{Para: []string{"maria@cliente.com\r"}}, false},
+		{"CRLF na cópia", EnviarProposta{Para: []string{"maria@cliente.com"}, Cc: []string{"compras@cliente.com\r\nBcc: intruso@externo.com"}}, false},
+		{"vírgula com dois endereços", EnviarProposta{Para: []string{"maria@cliente.com, intruso@externo.com"}}, false},
+		{"acima do limite", EnviarProposta{Para: muitos}, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			err := ValidarStructEnviarProposta(&c.envio)
+			if c.valido && err != nil {
+				t.Errorf("envio válido recusado: %v", err)
+			}
+			if !c.valido && err == nil {
+				t.Errorf("envio inválido aceito: %+v", c.envio)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+type GeracaoJob struct {
+	Id              uuid.UUID  `json:"id"`
+	PropostaId      uuid.UUID  `json:"propostaId"`
+	Status          string     `json:"status"`
+	Tentativas      int        `json:"tentativas"`
+	MaxTentativas   int        `json:"maxTentativas"`
+	UltimoErro      *string    `json:"ultimoErro,omitempty"`
+	ProximaExecucao time.Time  `json:"proximaExecucao"`
+	DataCriacao     time.Time  `json:"dataCriacao"`
+	LastUpdate      time.Time  `json:"lastUpdate"`
+	FinalizadoEm    *time.Time `json:"finalizadoEm,omitempty"`
+}
+
+const (
+	EventoQueued       = "queued"
+	EventoCallingIA    = "calling_ia"
+	EventoRenderingPDF = "rendering_pdf"
+	EventoSaved        = "saved"
+	EventoFailed       = "failed"
+)
+
+type EventoGeracao struct {
+	Tipo       string    `json:"tipo"`
+	PropostaId uuid.UUID `json:"propostaId"`
+	JobId      uuid.UUID `json:"jobId"`
+	Tentativa  int       `json:"tentativa"`
+	Mensagem   string    `json:"mensagem,omitempty"`
+	// Final marca o último evento da geração: saved, ou failed sem nova
+	// tentativa agendada.
+	Final bool      `json:"final"`
+	Data  time.Time `json:"data"`
+}
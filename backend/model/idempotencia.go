@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type RegistroIdempotencia struct {
+	Chave       string
+	RequestHash string
+	StatusCode  *int
+	ContentType *string
+	Resposta    []byte
+	DataCriacao time.Time
+	ExpiraEm    time.Time
+}
+
+// Concluido indica se a requisição original já terminou e tem resposta gravada.
+func (r *RegistroIdempotencia) Concluido() bool {
+	return r.StatusCode != nil
+}
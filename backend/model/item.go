@@ -0,0 +1,174 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	MoedaPadrao   = "BRL"
+	UnidadePadrao = "un"
+
+	ItensMaximo = 200
+)
+
+var ErrItemInvalido = errors.New("item inválido")
+
+var (
+	cem = decimal.NewFromInt(100)
+	// Limites das colunas NUMERIC(14,4) e NUMERIC(14,2) de proposta_itens.
+	quantidadeMaxima    = decimal.New(1, 10)
+	precoUnitarioMaximo = decimal.New(1, 12)
+)
+
+// ItemProposta é uma linha da tabela de preços. Desconto e Imposto são
+// percentuais (0 a 100). Os valores calculados são preenchidos por
+// CalcularTotais e ignorados na entrada; os números trafegam como string no
+// JSON para não passar por ponto flutuante.
+type ItemProposta struct {
+	Descricao     string          `json:"descricao"`
+	Quantidade    decimal.Decimal `json:"quantidade"`
+	Unidade       string          `json:"unidade"`
+	PrecoUnitario decimal.Decimal `json:"precoUnitario"`
+	Desconto      decimal.Decimal `json:"desconto"`
+	Imposto       decimal.Decimal `json:"imposto"`
+
+	Subtotal      decimal.Decimal `json:"subtotal"`
+	ValorDesconto decimal.Decimal `json:"valorDesconto"`
+	ValorImposto  decimal.Decimal `json:"valorImposto"`
+	Total         decimal.Decimal `json:"total"`
+}
+
+// TotaisProposta soma os valores calculados dos itens.
+type TotaisProposta struct {
+	Moeda     string          `json:"moeda"`
+	Subtotal  decimal.Decimal `json:"subtotal"`
+	Descontos decimal.Decimal `json:"descontos"`
+	Impostos  decimal.Decimal `json:"impostos"`
+	Total     decimal.Decimal `json:"total"`
+}
+
+// Normalizar limpa a descrição e aplica a unidade padrão.
+func (i *ItemProposta) Normalizar() {
+	i.Descricao = strings.TrimSpace(i.Descricao)
+	i.Unidade = strings.TrimSpace(i.Unidade)
+	if i.Unidade == "" {
+		i.Unidade = UnidadePadrao
+	}
+}
+
+// Calcular preenche os valores do item em reais, arredondando cada etapa para
+// centavos: o subtotal é quantidade × preço, o desconto incide sobre o
+// subtotal e o imposto sobre o valor já com desconto.
+func (i *ItemProposta) Calcular() {
+	i.Subtotal = i.Quantidade.Mul(i.PrecoUnitario).Round(2)
+	i.ValorDesconto = i.Subtotal.Mul(i.Desconto).Div(cem).Round(2)
+	base := i.Subtotal.Sub(i.ValorDesconto)
+	i.ValorImposto = base.Mul(i.Imposto).Div(cem).Round(2)
+	i.Total = base.Add(i.ValorImposto)
+}
+
+// CalcularTotais calcula cada item e os totais da proposta. Sem itens, Totais
+// fica nulo.
+func (p *Proposta) CalcularTotais() {
+	if len(p.Itens) == 0 {
+		p.Totais = nil
+		return
+	}
+	totais := TotaisProposta{Moeda: MoedaPadrao}
+	for n := range p.Itens {
+		item := &p.Itens[n]
+		item.Calcular()
+		totais.Subtotal = totais.Subtotal.Add(item.Subtotal)
+		totais.Descontos = totais.Descontos.Add(item.ValorDesconto)
+		totais.Impostos = totais.Impostos.Add(item.ValorImposto)
+		totais.Total = totais.Total.Add(item.Total)
+	}
+	p.Totais = &totais
+}
+
+// ValidarItens normaliza os itens e confere os valores numéricos, que o
+// validator não alcança. Os erros indicam a posição do item, a partir de 1.
+func ValidarItens(itens []ItemProposta) error {
+	if len(itens) > ItensMaximo {
+		return fmt.Errorf("%w: no máximo %d itens por proposta", ErrItemInvalido, ItensMaximo)
+	}
+	for n := range itens {
+		item := &itens[n]
+		item.Normalizar()
+		if err := validarItem(item); err != nil {
+			return fmt.Errorf("%w: item %d: %s", ErrItemInvalido, n+1, err.Error())
+		}
+	}
+	return nil
+}
+
+func validarItem(item *ItemProposta) error {
+	switch {
+	case item.Descricao == "":
+		return errors.New("descricao é obrigatória")
+	case len([]rune(item.Descricao)) > 500:
+		return errors.New("descricao deve ter no máximo 500 caracteres")
+	case len([]rune(item.Unidade)) > 20:
+		return errors.New("unidade deve ter no máximo 20 caracteres")
+	case !item.Quantidade.IsPositive():
+		return errors.New("quantidade deve ser maior que zero")
+	case item.Quantidade.GreaterThanOrEqual(quantidadeMaxima):
+		return errors.New("quantidade acima do limite")
+	case !casasDecimais(item.Quantidade, 4):
+		return errors.New("quantidade aceita no máximo 4 casas decimais")
+	case item.PrecoUnitario.IsNegative():
+		return errors.New("precoUnitario não pode ser negativo")
+	case item.PrecoUnitario.GreaterThanOrEqual(precoUnitarioMaximo):
+		return errors.New("precoUnitario acima do limite")
+	case !casasDecimais(item.PrecoUnitario, 2):
+		return errors.New("precoUnitario aceita no máximo 2 casas decimais")
+	}
+	for _, percentual := range []struct {
+		campo string
+		valor decimal.Decimal
+	}{{"desconto", item.Desconto}, {"imposto", item.Imposto}} {
+		if percentual.valor.IsNegative() || percentual.valor.GreaterThan(cem) {
+			return fmt.Errorf("%s deve ser um percentual entre 0 e 100", percentual.campo)
+		}
+		if !casasDecimais(percentual.valor, 2) {
+			return fmt.Errorf("%s aceita no máximo 2 casas decimais", percentual.campo)
+		}
+	}
+	return nil
+}
+
+func casasDecimais(d decimal.Decimal, casas int32) bool {
+	return d.Equal(d.Truncate(casas))
+}
+
+// FormatarBRL escreve o valor em reais no padrão brasileiro, ex.: R$ 1.234,56.
+func FormatarBRL(valor decimal.Decimal) string {
+	// O sinal é o do valor já arredondado, para -0,001 não virar "-R$ 0,00".
+	valor = valor.Round(2)
+	texto := valor.Abs().StringFixed(2)
+	inteiro, centavos := texto[:len(texto)-3], texto[len(texto)-2:]
+	var b strings.Builder
+	if valor.IsNegative() {
+		b.WriteString("-")
+	}
+	b.WriteString("R$ ")
+	for n, r := range inteiro {
+		if n > 0 && (len(inteiro)-n)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(",")
+	b.WriteString(centavos)
+	return b.String()
+}
+
+// FormatarNumero escreve quantidades e percentuais sem zeros à direita e com
+// vírgula decimal, ex.: 2,5.
+func FormatarNumero(valor decimal.Decimal) string {
+	return strings.Replace(valor.String(), ".", ",", 1)
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func dec(valor string) decimal.Decimal {
+	return decimal.RequireFromString(valor)
+}
+
+func TestItemPropostaCalcular(t *testing.T) {
+	casos := []struct {
+		nome                                         string
+		quantidade, preco, desconto, imposto         string
+		subtotal, valorDesconto, valorImposto, total string
+	}{
+		{"sem desconto nem imposto", "3", "100.00", "0", "0", "300.00", "0.00", "0.00", "300.00"},
+		{"subtotal arredondado", "1.5", "0.33", "0", "0", "0.50", "0.00", "0.00", "0.50"},
+		{"cada etapa arredondada", "0.3333", "10.00", "15", "7.5", "3.33", "0.50", "0.21", "3.04"},
+		{"imposto sobre o valor com desconto", "2", "50.00", "10", "10", "100.00", "10.00", "9.00", "99.00"},
+		{"meio centavo arredonda para cima", "1", "0.05", "10", "0", "0.05", "0.01", "0.00", "0.04"},
+		{"desconto de 100%", "3", "50.00", "100", "18", "150.00", "150.00", "0.00", "0.00"},
+		{"imposto de 100%", "1", "19.99", "0", "100", "19.99", "0.00", "19.99", "39.98"},
+		{"preço zero", "10", "0", "5", "5", "0.00", "0.00", "0.00", "0.00"},
+		{"valores no limite das colunas", "9999999999.9999", "999999999999.99", "10", "0",
+			"9999999999999800000000.00", "999999999999980000000.00", "0.00", "8999999999999820000000.00"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			item := ItemProposta{Quantidade: dec(c.quantidade), PrecoUnitario: dec(c.preco), Desconto: dec(c.desconto), Imposto: dec(c.imposto)}
+			item.Calcular()
+			for _, v := range []struct {
+				campo    string
+				obtido   decimal.Decimal
+				esperado string
+			}{
+				{"subtotal", item.Subtotal, c.subtotal},
+				{"valorDesconto", item.ValorDesconto, c.valorDesconto},
+				{"valorImposto", item.ValorImposto, c.valorImposto},
+				{"total", item.Total, c.total},
+			} {
+				if !v.obtido.Equal(dec(v.esperado)) {
+					t.Errorf("%s = %s, esperado %s", v.campo, v.obtido, v.esperado)
+				}
+			}
+		})
+	}
+}
+
+func TestPropostaCalcularTotais(t *testing.T) {
+	p := &Proposta{Itens: []ItemProposta{
+		{Descricao: "Consultoria", Quantidade: dec("0.3333"), PrecoUnitario: dec("10.00"), Desconto: dec("15"), Imposto: dec("7.5")},
+		{Descricao: "Licença", Quantidade: dec("2"), PrecoUnitario: dec("50.00"), Desconto: dec("10"), Imposto: dec("10")},
+		{Descricao: "Brinde", Quantidade: dec("3"), PrecoUnitario: dec("50.00"), Desconto: dec("100")},
+	}}
+	p.CalcularTotais()
+	if p.Totais == nil {
+		t.Fatal("Totais nulo com itens")
+	}
+	esperado := TotaisProposta{Moeda: MoedaPadrao, Subtotal: dec("253.33"), Descontos: dec("160.50"), Impostos: dec("9.21"), Total: dec("102.04")}
+	if p.Totais.Moeda != esperado.Moeda || !p.Totais.Subtotal.Equal(esperado.Subtotal) || !p.Totais.Descontos.Equal(esperado.Descontos) ||
+		!p.Totais.Impostos.Equal(esperado.Impostos) || !p.Totais.Total.Equal(esperado.Total) {
+		t.Errorf("totais %+v, esperado %+v", *p.Totais, esperado)
+	}
+	// O total é a soma dos totais já arredondados de cada item.
+	if soma := p.Totais.Subtotal.Sub(p.Totais.Descontos).Add(p.Totais.Impostos); !soma.Equal(p.Totais.Total) {
+		t.Errorf("subtotal - descontos + impostos = %s, total %s", soma, p.Totais.Total)
+	}
+
+	p.Itens = nil
+	p.CalcularTotais()
+	if p.Totais != nil {
+		t.Errorf("Totais %+v sem itens, esperado nulo", *p.Totais)
+	}
+}
+
+func TestValidarItens(t *testing.T) {
+	valido := func() ItemProposta {
+		return ItemProposta{Descricao: "Consultoria", Quantidade: dec("1"), PrecoUnitario: dec("100.00")}
+	}
+	casos := []struct {
+		nome   string
+		mudar  func(*ItemProposta)
+		valido bool
+	}{
+		{"válido", func(i *ItemProposta) {}, true},
+		{"desconto de 100%", func(i *ItemProposta) { i.Desconto = dec("100") }, true},
+		{"sem descrição", func(i *ItemProposta) { i.Descricao = "  " }, false},
+		{"quantidade zero", func(i *ItemProposta) { i.Quantidade = dec("0") }, false},
+		{"quantidade negativa", func(i *ItemProposta) { i.Quantidade = dec("-1") }, false},
+		{"quantidade acima do limite", func(i *ItemProposta) { i.Quantidade = dec("10000000000") }, false},
+		{"quantidade com 5 casas", func(i *ItemProposta) { i.Quantidade = dec("1.00001") }, false},
+		{"preço negativo", func(i *ItemProposta) { i.PrecoUnitario = dec("-0.01") }, false},
+		{"preço acima do limite", func(i *ItemProposta) { i.PrecoUnitario = dec("1000000000000") }, false},
+		{"preço com 3 casas", func(i *ItemProposta) { i.PrecoUnitario = dec("1.001") }, false},
+		{"desconto acima de 100%", func(i *ItemProposta) { i.Desconto = dec("100.01") }, false},
+		{"imposto negativo", func(i *ItemProposta) { i.Imposto = dec("-1") }, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			item := valido()
+			c.mudar(&item)
+			err := ValidarItens([]ItemProposta{valido(), item})
+			if c.valido && err != nil {
+				t.Errorf("erro inesperado: %v", err)
+			}
+			if !c.valido && !errors.Is(err, ErrItemInvalido) {
+				t.Errorf("erro %v, esperado %v", err, ErrItemInvalido)
+			}
+		})
+	}
+}
+
+func TestFormatarBRL(t *testing.T) {
+	casos := []struct {
+		valor    string
+		esperado string
+	}{
+		{"0", "R$ 0,00"},
+		{"5", "R$ 5,00"},
+		{"0.5", "R$ 0,50"},
+		{"999.99", "R$ 999,99"},
+		{"1000", "R$ 1.000,00"},
+		{"1234.56", "R$ 1.234,56"},
+		{"123456.7", "R$ 123.456,70"},
+		{"1234567.89", "R$ 1.234.567,89"},
+		{"0.005", "R$ 0,01"},
+		{"1234567.894", "R$ 1.234.567,89"},
+		{"1234567.895", "R$ 1.234.567,90"},
+		{"999999.999", "R$ 1.000.000,00"},
+		{"-5", "-R$ 5,00"},
+		{"-1234.5", "-R$ 1.234,50"},
+		{"-1234567.89", "-R$ 1.234.567,89"},
+		{"-0.001", "R$ 0,00"},
+		{"9999999999999800000000", "R$ 9.999.999.999.999.800.000.000,00"},
+	}
+	for _, c := range casos {
+		t.Run(c.valor, func(t *testing.T) {
+			if got := FormatarBRL(dec(c.valor)); got != c.esperado {
+				t.Errorf("FormatarBRL(%s) = %q, esperado %q", c.valor, got, c.esperado)
+			}
+		})
+	}
+}
+
+func TestFormatarNumero(t *testing.T) {
+	casos := []struct {
+		valor    string
+		esperado string
+	}{
+		{"2.5", "2,5"},
+		{"2.50", "2,5"},
+		{"10", "10"},
+		{"0.3333", "0,3333"},
+		{"7.5", "7,5"},
+		{"1000.25", "1000,25"},
+	}
+	for _, c := range casos {
+		if got := FormatarNumero(dec(c.valor)); got != c.esperado {
+			t.Errorf("FormatarNumero(%s) = %q, esperado %q", c.valor, got, c.esperado)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	LinkFormatoPDF  = "pdf"
+	LinkFormatoHTML = "html"
+)
+
+// LinkCompartilhamento é um link público e assinado para um cliente sem acesso
+// à API visualizar a proposta. O token em si não é gravado: ele é derivado do
+// Id e da expiração e validado por HMAC.
+type LinkCompartilhamento struct {
+	Id               uuid.UUID  `json:"id"`
+	PropostaId       uuid.UUID  `json:"propostaId"`
+	ExpiraEm         time.Time  `json:"expiraEm"`
+	MaxVisualizacoes *int       `json:"maxVisualizacoes,omitempty"`
+	Visualizacoes    int        `json:"visualizacoes"`
+	RevogadoEm       *time.Time `json:"revogadoEm,omitempty"`
+	DataCriacao      time.Time  `json:"dataCriacao"`
+}
+
+type CriarLink struct {
+	ValidadeHoras    int  `json:"validadeHoras" validate:"omitempty,min=1,max=8760"`
+	MaxVisualizacoes *int `json:"maxVisualizacoes" validate:"omitempty,min=1"`
+}
+
+// Ativo indica se o link ainda pode ser usado no instante informado.
+func (l *LinkCompartilhamento) Ativo(agora time.Time) bool {
+	if l.RevogadoEm != nil || !agora.Before(l.ExpiraEm) {
+		return false
+	}
+	return l.MaxVisualizacoes == nil || l.Visualizacoes < *l.MaxVisualizacoes
+}
+
+func ValidarStructCriarLink(l *CriarLink) error {
+	return validator.New().Struct(l)
+}
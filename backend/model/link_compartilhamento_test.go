@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkAtivo(t *testing.T) {
+	agora := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	um, dois := 1, 2
+	casos := []struct {
+		nome     string
+		link     LinkCompartilhamento
+		esperado bool
+	}{
+		{"dentro da validade, sem limite", LinkCompartilhamento{ExpiraEm: agora.Add(time.Hour), Visualizacoes: 50}, true},
+		{"expira exatamente agora", LinkCompartilhamento{ExpiraEm: agora}, false},
+		{"expirado", LinkCompartilhamento{ExpiraEm: agora.Add(-time.Hour)}, false},
+		{"revogado", LinkCompartilhamento{ExpiraEm: agora.Add(time.Hour), RevogadoEm: &agora}, false},
+		{"com visualizações restantes", LinkCompartilhamento{ExpiraEm: agora.Add(time.Hour), MaxVisualizacoes: &dois, Visualizacoes: 1}, true},
+		{"visualizações esgotadas", LinkCompartilhamento{ExpiraEm: agora.Add(time.Hour), MaxVisualizacoes: &um, Visualizacoes: 1}, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if obtido := c.link.Ativo(agora); obtido != c.esperado {
+				t.Errorf("Ativo = %v, esperado %v", obtido, c.esperado)
+			}
+		})
+	}
+}
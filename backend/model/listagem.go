@@ -0,0 +1,166 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	ListagemLimitePadrao = 20
+	ListagemLimiteMaximo = 100
+
+	OrdenacaoPadrao = "-dataCriacao"
+)
+
+var ErrCursorInvalido = errors.New("cursor inválido")
+
+var ErrFiltroInvalido = errors.New("filtro inválido")
+
+// FiltroPropostas são os parâmetros de GET /proposta/. A paginação é por
+// offset ou por cursor (keyset), nunca os dois. Sort aceita o campo com "-"
+// na frente para ordem decrescente.
+type FiltroPropostas struct {
+	Limit         int      `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset        int      `form:"offset" validate:"omitempty,min=0,excluded_with=Cursor"`
+	Cursor        string   `form:"cursor"`
+	Status        []string `form:"status" collection_format:"csv" validate:"omitempty,dive,oneof=rascunho enviado aprovado recusado expirado cancelado"`
+	NomeEmpresa   string   `form:"nomeEmpresa" validate:"omitempty,max=200"`
+	ClienteId     string   `form:"clienteId" validate:"omitempty,uuid"`
+	CriadoDe      string   `form:"criadoDe"`
+	CriadoAte     string   `form:"criadoAte"`
+	AtualizadoDe  string   `form:"atualizadoDe"`
+	AtualizadoAte string   `form:"atualizadoAte"`
+	Sort          string   `form:"sort" validate:"omitempty,oneof=dataCriacao -dataCriacao lastUpdate -lastUpdate titulo -titulo"`
+}
+
+// IntervaloDatas é um filtro de datas já convertido; os limites nulos não
+// restringem a consulta.
+type IntervaloDatas struct {
+	De  *time.Time
+	Ate *time.Time
+}
+
+// CursorPropostas marca a última proposta de uma página. Valor é a chave de
+// ordenação serializada e o Id desempata propostas com o mesmo valor.
+type CursorPropostas struct {
+	Sort  string    `json:"s"`
+	Valor string    `json:"v"`
+	Id    uuid.UUID `json:"id"`
+}
+
+// PaginaPropostas é o envelope da listagem. Total conta todas as propostas
+// que atendem aos filtros, independente da página.
+type PaginaPropostas struct {
+	Itens         []Proposta `json:"itens"`
+	Total         int        `json:"total"`
+	Limit         int        `json:"limit"`
+	Offset        int        `json:"offset"`
+	ProximoCursor string     `json:"proximoCursor,omitempty"`
+}
+
+func ValidarStructFiltroPropostas(f *FiltroPropostas) error {
+	return validator.New().Struct(f)
+}
+
+// Normalizar aplica os padrões de limite e ordenação.
+func (f *FiltroPropostas) Normalizar() {
+	if f.Limit == 0 {
+		f.Limit = ListagemLimitePadrao
+	}
+	if f.Sort == "" {
+		f.Sort = OrdenacaoPadrao
+	}
+}
+
+// Criacao e Atualizacao convertem os intervalos informados. Aceitam RFC 3339
+// ou só a data (AAAA-MM-DD); no limite final, só a data inclui o dia inteiro.
+func (f *FiltroPropostas) Criacao() (IntervaloDatas, error) {
+	return parseIntervalo("criadoDe", f.CriadoDe, "criadoAte", f.CriadoAte)
+}
+
+func (f *FiltroPropostas) Atualizacao() (IntervaloDatas, error) {
+	return parseIntervalo("atualizadoDe", f.AtualizadoDe, "atualizadoAte", f.AtualizadoAte)
+}
+
+func parseIntervalo(campoDe string, de string, campoAte string, ate string) (IntervaloDatas, error) {
+	var intervalo IntervaloDatas
+	var err error
+	if intervalo.De, err = parseDataFiltro(campoDe, de, false); err != nil {
+		return intervalo, err
+	}
+	if intervalo.Ate, err = parseDataFiltro(campoAte, ate, true); err != nil {
+		return intervalo, err
+	}
+	if intervalo.De != nil && intervalo.Ate != nil && intervalo.Ate.Before(*intervalo.De) {
+		return intervalo, fmt.Errorf("%w: %s é anterior a %s", ErrFiltroInvalido, campoAte, campoDe)
+	}
+	return intervalo, nil
+}
+
+func parseDataFiltro(campo string, valor string, fimDoDia bool) (*time.Time, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, valor); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, valor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s deve ser uma data (AAAA-MM-DD) ou data e hora RFC 3339", ErrFiltroInvalido, campo)
+	}
+	if fimDoDia {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// CampoOrdenacao separa o campo e a direção de Sort.
+func (f *FiltroPropostas) CampoOrdenacao() (campo string, decrescente bool) {
+	return strings.TrimPrefix(f.Sort, "-"), strings.HasPrefix(f.Sort, "-")
+}
+
+// ValorOrdenacao devolve a chave de ordenação de p serializada para o cursor.
+func ValorOrdenacao(p *Proposta, campo string) string {
+	switch campo {
+	case "titulo":
+		return p.Titulo
+	case "lastUpdate":
+		return p.LastUpdate.Format(time.RFC3339Nano)
+	default:
+		return p.DataCriacao.Format(time.RFC3339Nano)
+	}
+}
+
+func (c CursorPropostas) Codificar() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodificarCursor lê o cursor e confere que ele foi gerado com a mesma
+// ordenação da requisição atual.
+func DecodificarCursor(valor string, sort string) (*CursorPropostas, error) {
+	b, err := base64.RawURLEncoding.DecodeString(valor)
+	if err != nil {
+		return nil, ErrCursorInvalido
+	}
+	var c CursorPropostas
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrCursorInvalido
+	}
+	if c.Sort != sort {
+		return nil, fmt.Errorf("%w: gerado para sort=%s", ErrCursorInvalido, c.Sort)
+	}
+	if campo := strings.TrimPrefix(sort, "-"); campo != "titulo" {
+		if _, err := time.Parse(time.RFC3339Nano, c.Valor); err != nil {
+			return nil, ErrCursorInvalido
+		}
+	}
+	return &c, nil
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorPropostasIdaEVolta(t *testing.T) {
+	casos := []struct {
+		nome   string
+		cursor CursorPropostas
+	}{
+		{"data de criação decrescente", CursorPropostas{Sort: "-dataCriacao", Valor: "2025-03-10T14:05:06.123456Z", Id: uuid.New()}},
+		{"última atualização crescente", CursorPropostas{Sort: "lastUpdate", Valor: "2025-03-10T11:05:06.000001-03:00", Id: uuid.New()}},
+		{"título com acentos e aspas", CursorPropostas{Sort: "titulo", Valor: `Proposta "Ação" – São Paulo`, Id: uuid.New()}},
+		{"título vazio decrescente", CursorPropostas{Sort: "-titulo", Valor: "", Id: uuid.New()}},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			codificado := c.cursor.Codificar()
+			if strings.ContainsAny(codificado, "+/=") {
+				t.Errorf("cursor %q não é seguro para URL", codificado)
+			}
+			decodificado, err := DecodificarCursor(codificado, c.cursor.Sort)
+			if err != nil {
+				t.Fatalf("DecodificarCursor: %v", err)
+			}
+			if *decodificado != c.cursor {
+				t.Errorf("cursor decodificado %+v, esperado %+v", *decodificado, c.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodificarCursorAdulterado(t *testing.T) {
+	valido := CursorPropostas{Sort: "-dataCriacao", Valor: "2025-03-10T14:05:06Z", Id: uuid.New()}.Codificar()
+	bruto := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+	casos := []struct {
+		nome  string
+		valor string
+		sort  string
+	}{
+		{"vazio", "", "-dataCriacao"},
+		{"fora do base64", "não é base64!", "-dataCriacao"},
+		{"base64 com padding", base64.URLEncoding.EncodeToString([]byte(`{"s":"-dataCriacao"}`)), "-dataCriacao"},
+		{"truncado", valido[:len(valido)/2], "-dataCriacao"},
+		{"não é JSON", bruto("proposta"), "-dataCriacao"},
+		{"id inválido", bruto(`{"s":"-dataCriacao","v":"2025-03-10T14:05:06Z","id":"123"}`), "-dataCriacao"},
+		{"data inválida", bruto(`{"s":"-dataCriacao","v":"ontem","id":"` + uuid.NewString() + `"}`), "-dataCriacao"},
+		{"injeção no valor", bruto(`{"s":"lastUpdate","v":"2025-01-01'); DROP TABLE propostas;--","id":"` + uuid.NewString() + `"}`), "lastUpdate"},
+		{"outra ordenação", valido, "dataCriacao"},
+		{"sort trocado no payload", bruto(`{"s":"titulo","v":"2025-03-10T14:05:06Z","id":"` + uuid.NewString() + `"}`), "-dataCriacao"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cursor, err := DecodificarCursor(c.valor, c.sort)
+			if !errors.Is(err, ErrCursorInvalido) {
+				t.Errorf("DecodificarCursor(%q) = %+v, %v; esperado %v", c.valor, cursor, err, ErrCursorInvalido)
+			}
+		})
+	}
+}
+
+// paginaKeyset reproduz a consulta da listagem sobre uma lista: ordena por
+// (dataCriacao, id) na direção pedida e devolve as propostas depois do cursor.
+func paginaKeyset(t *testing.T, propostas []Proposta, decrescente bool, cursor *CursorPropostas, limite int) []Proposta {
+	t.Helper()
+	comparar := func(data time.Time, id uuid.UUID, outra time.Time, outroId uuid.UUID) int {
+		if c := data.Compare(outra); c != 0 {
+			return c
+		}
+		return bytes.Compare(id[:], outroId[:])
+	}
+	ordenadas := slices.Clone(propostas)
+	slices.SortFunc(ordenadas, func(a, b Proposta) int {
+		c := comparar(a.DataCriacao, a.Id, b.DataCriacao, b.Id)
+		if decrescente {
+			return -c
+		}
+		return c
+	})
+	pagina := []Proposta{}
+	for _, p := range ordenadas {
+		if cursor != nil {
+			valor, err := time.Parse(time.RFC3339Nano, cursor.Valor)
+			if err != nil {
+				t.Fatalf("valor do cursor: %v", err)
+			}
+			c := comparar(p.DataCriacao, p.Id, valor, cursor.Id)
+			if (decrescente && c >= 0) || (!decrescente && c <= 0) {
+				continue
+			}
+		}
+		if len(pagina) == limite {
+			break
+		}
+		pagina = append(pagina, p)
+	}
+	return pagina
+}
+
+func TestCursorDesempataDataCriacaoIgual(t *testing.T) {
+	mesmoInstante := time.Date(2025, 3, 10, 14, 5, 6, 123456000, time.UTC)
+	propostas := []Proposta{}
+	for n := 0; n < 7; n++ {
+		propostas = append(propostas, Proposta{Id: uuid.New(), DataCriacao: mesmoInstante})
+	}
+	propostas = append(propostas,
+		Proposta{Id: uuid.New(), DataCriacao: mesmoInstante.Add(time.Microsecond)},
+		Proposta{Id: uuid.New(), DataCriacao: mesmoInstante.Add(-time.Microsecond)},
+		Proposta{Id: uuid.New(), DataCriacao: mesmoInstante.Add(-time.Hour)},
+	)
+
+	for _, sort := range []string{"-dataCriacao", "dataCriacao"} {
+		for _, limite := range []int{1, 2, 3, 7} {
+			filtro := FiltroPropostas{Sort: sort, Limit: limite}
+			campo, decrescente := filtro.CampoOrdenacao()
+			esperadas := paginaKeyset(t, propostas, decrescente, nil, len(propostas))
+
+			vistas := []Proposta{}
+			var cursor *CursorPropostas
+			for paginas := 0; ; paginas++ {
+				if paginas > len(propostas) {
+					t.Fatalf("sort=%s limite=%d: paginação não termina", sort, limite)
+				}
+				pagina := paginaKeyset(t, propostas, decrescente, cursor, limite)
+				vistas = append(vistas, pagina...)
+				if len(pagina) < limite {
+					break
+				}
+				ultima := &pagina[len(pagina)-1]
+				codificado := CursorPropostas{Sort: sort, Valor: ValorOrdenacao(ultima, campo), Id: ultima.Id}.Codificar()
+				var err error
+				if cursor, err = DecodificarCursor(codificado, sort); err != nil {
+					t.Fatalf("DecodificarCursor: %v", err)
+				}
+			}
+
+			if len(vistas) != len(esperadas) {
+				t.Fatalf("sort=%s limite=%d: %d propostas percorridas, esperado %d", sort, limite, len(vistas), len(esperadas))
+			}
+			for n := range esperadas {
+				if vistas[n].Id != esperadas[n].Id {
+					t.Fatalf("sort=%s limite=%d: posição %d com %s, esperado %s", sort, limite, n, vistas[n].Id, esperadas[n].Id)
+				}
+			}
+		}
+	}
+}
+
+func TestValorOrdenacaoPreservaPrecisao(t *testing.T) {
+	p := &Proposta{
+		Titulo:      "Proposta",
+		DataCriacao: time.Date(2025, 3, 10, 14, 5, 6, 123456000, time.UTC),
+		LastUpdate:  time.Date(2025, 3, 11, 9, 0, 0, 1000, time.UTC),
+	}
+	for _, campo := range []string{"dataCriacao", "lastUpdate"} {
+		valor := ValorOrdenacao(p, campo)
+		lido, err := time.Parse(time.RFC3339Nano, valor)
+		if err != nil {
+			t.Fatalf("%s: %v", campo, err)
+		}
+		esperado := p.DataCriacao
+		if campo == "lastUpdate" {
+			esperado = p.LastUpdate
+		}
+		if !lido.Equal(esperado) {
+			t.Errorf("%s: %s volta como %s", campo, esperado, lido)
+		}
+	}
+	if valor := ValorOrdenacao(p, "titulo"); valor != p.Titulo {
+		t.Errorf("titulo: %q", valor)
+	}
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var ErrMarcaNaoEncontrada = errors.New("marca não encontrada")
+
+var ErrMarcaDuplicada = errors.New("já existe uma marca com este nome")
+
+var ErrMarcaEmUso = errors.New("a marca possui propostas e não pode ser removida")
+
+type ContatoMarca struct {
+	Email    string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Telefone string `json:"telefone,omitempty" validate:"omitempty,max=30"`
+	Site     string `json:"site,omitempty" validate:"omitempty,url,max=255"`
+	Endereco string `json:"endereco,omitempty" validate:"omitempty,max=255"`
+}
+
+// Linha junta os contatos preenchidos em uma linha, como no rodapé.
+func (c ContatoMarca) Linha() string {
+	partes := []string{}
+	for _, parte := range []string{c.Email, c.Telefone, c.Site, c.Endereco} {
+		if parte != "" {
+			partes = append(partes, parte)
+		}
+	}
+	return strings.Join(partes, " · ")
+}
+
+// EstiloMarca é a parte da identidade visual que não tem campo próprio na
+// proposta. Na proposta, guarda os valores efetivos com que ela foi gerada.
+type EstiloMarca struct {
+	CoresSecundarias []string     `json:"coresSecundarias,omitempty" validate:"max=10,dive,hexcolor"`
+	Fonte            string       `json:"fonte,omitempty" validate:"omitempty,max=100,excludesall=;{}<>"`
+	Rodape           string       `json:"rodape,omitempty" validate:"omitempty,max=500"`
+	Contato          ContatoMarca `json:"contato"`
+}
+
+// Marca é um kit de identidade visual reutilizável. As cores primárias e o
+// logo viram cores e logo das propostas que a referenciam.
+type Marca struct {
+	Id             uuid.UUID `json:"id"`
+	Nome           string    `json:"nome" validate:"required,min=2,max=100"`
+	CoresPrimarias []string  `json:"coresPrimarias" validate:"required,min=1,max=10,dive,hexcolor"`
+	Logo           string    `json:"logo,omitempty" validate:"omitempty,url,max=255"`
+	EstiloMarca
+	DataCriacao time.Time `json:"dataCriacao"`
+	LastUpdate  time.Time `json:"lastUpdate"`
+}
+
+// Normalizar limpa os textos e troca a lista nula de cores secundárias por vazia.
+func (m *Marca) Normalizar() {
+	m.Nome = strings.TrimSpace(m.Nome)
+	m.Fonte = strings.TrimSpace(m.Fonte)
+	m.Rodape = strings.TrimSpace(m.Rodape)
+	if m.CoresSecundarias == nil {
+		m.CoresSecundarias = []string{}
+	}
+}
+
+func ValidarStructMarca(m *Marca) error {
+	validate := validator.New()
+	validate.RegisterValidation("hexcolor", HexColor)
+	return validate.Struct(m)
+}
+
+// Sobre devolve base com os campos preenchidos de e no lugar dos dela; é como
+// uma proposta sobrescreve campos individuais do kit.
+func (e EstiloMarca) Sobre(base EstiloMarca) EstiloMarca {
+	if len(e.CoresSecundarias) > 0 {
+		base.CoresSecundarias = e.CoresSecundarias
+	}
+	if e.Fonte != "" {
+		base.Fonte = e.Fonte
+	}
+	if e.Rodape != "" {
+		base.Rodape = e.Rodape
+	}
+	if e.Contato.Email != "" {
+		base.Contato.Email = e.Contato.Email
+	}
+	if e.Contato.Telefone != "" {
+		base.Contato.Telefone = e.Contato.Telefone
+	}
+	if e.Contato.Site != "" {
+		base.Contato.Site = e.Contato.Site
+	}
+	if e.Contato.Endereco != "" {
+		base.Contato.Endereco = e.Contato.Endereco
+	}
+	return base
+}
+
+// AplicarMarca completa a proposta com o kit. Cores, logo e os campos de
+// Marca informados na proposta têm precedência; os vazios vêm do kit.
+func (p *Proposta) AplicarMarca(m *Marca) {
+	p.MarcaId = &m.Id
+	if len(p.Cores) == 0 {
+		p.Cores = m.CoresPrimarias
+	}
+	if p.Logo == "" {
+		p.Logo = m.Logo
+	}
+	estilo := m.EstiloMarca
+	if p.Marca != nil {
+		estilo = p.Marca.Sobre(estilo)
+	}
+	p.Marca = &estilo
+}
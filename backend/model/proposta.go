@@ -2,7 +2,9 @@ package model
 
 import (
 	"regexp"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 
@@ -17,33 +19,156 @@ var hexColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}){1,2}$`)
 type Proposta struct {
 	Id           uuid.UUID `json:"id" validate:"uuid"`
 	Titulo       string    `json:"titulo" validate:"required,min=3,max=100"`
-	NomeEmpresa  string    `json:"nomeEmpresa" validate:"required"`
-	NomeCliente  string    `json:"nomeCliente" validate:"required"`
+	NomeEmpresa  string    `json:"nomeEmpresa" validate:"required_without=ClienteId"`
+	NomeCliente  string    `json:"nomeCliente"`
 	Prompt       string    `json:"prompt" validate:"required,min=20"`
-	Cores        []string  `json:"cores" validate:"required,dive,hexcolor"`
+	Cores        []string  `json:"cores" validate:"required_without=MarcaId,dive,hexcolor"`
 	Logo         string    `json:"logo" validate:"omitempty,url"`
 	LogoCliente  string    `json:"logoCliente" validate:"omitempty,url"`
 	Html         string    `json:"html,omitempty"`
-	Status       string    `json:"status" validate:"required,oneof=rascunho enviado aprovado"`
+	Status       string    `json:"status" validate:"omitempty,oneof=rascunho enviado aprovado recusado expirado cancelado"`
 	ArquivoFinal string    `json:"arquivoFinal"`
 	DataCriacao  time.Time `json:"dataCriacao"`
 	LastUpdate   time.Time `json:"lastUpdate"`
+
+	ArquivoSha256  *string `json:"arquivoSha256,omitempty"`
+	ArquivoTamanho *int64  `json:"arquivoTamanho,omitempty"`
+
+	// ValidoAte é o fim da validade comercial; uma proposta enviada que passa
+	// dessa data é expirada automaticamente. Na criação pode ser informada
+	// diretamente ou por ValidadeDias, contada a partir da criação.
+	ValidoAte    *time.Time `json:"validoAte,omitempty"`
+	ValidadeDias *int       `json:"validadeDias,omitempty" validate:"omitempty,min=1,max=3650,excluded_with=ValidoAte"`
+
+	// ClienteId vincula a proposta a um cadastro de cliente. Informado, a
+	// empresa vem do cliente e nomeCliente e logoCliente, quando vazios, vêm
+	// do contato principal e do logo dele.
+	ClienteId *uuid.UUID `json:"clienteId,omitempty"`
+
+	// Itens é a tabela de preços da proposta. Totais é sempre calculado pelo
+	// backend a partir dos itens e não é lido da entrada.
+	Itens  []ItemProposta  `json:"itens,omitempty"`
+	Totais *TotaisProposta `json:"totais,omitempty"`
+
+	// MarcaId referencia um kit de identidade visual. Informado, cores, logo
+	// e os campos de Marca que vierem vazios são preenchidos pelo kit; Marca
+	// passa a guardar os valores efetivos enviados à IA.
+	MarcaId *uuid.UUID   `json:"marcaId,omitempty"`
+	Marca   *EstiloMarca `json:"marca,omitempty"`
+}
+
+// AplicarCliente preenche os dados do cliente na proposta, mantendo o contato
+// e o logo informados explicitamente.
+func (p *Proposta) AplicarCliente(c *Cliente) {
+	p.ClienteId = &c.Id
+	p.NomeEmpresa = c.NomeEmpresa
+	if p.NomeCliente == "" {
+		p.NomeCliente = c.ContatoPrincipal()
+	}
+	if p.LogoCliente == "" {
+		p.LogoCliente = c.Logo
+	}
+}
+
+// Vencida indica se a validade da proposta já passou em agora.
+func (p *Proposta) Vencida(agora time.Time) bool {
+	return p.ValidoAte != nil && !agora.Before(*p.ValidoAte)
+}
+
+// NomeArquivoPDF monta o nome do PDF baixado ou anexado a partir do título e
+// do cliente, ex.: "Proposta Comercial - Maria.pdf".
+func (p *Proposta) NomeArquivoPDF() string {
+	partes := []string{}
+	for _, parte := range []string{p.Titulo, p.NomeCliente} {
+		parte = strings.Join(strings.Fields(parte), " ")
+		if parte != "" {
+			partes = append(partes, parte)
+		}
+	}
+	nome := strings.Join(partes, " - ")
+	nome = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), unicode.IsControl(r):
+			return '_'
+		}
+		return r
+	}, nome)
+	if nome == "" {
+		nome = "proposta_" + p.Id.String()
+	}
+	return nome + ".pdf"
 }
 
 type PropostaUpdate struct {
-	Titulo       *string `json:"titulo" validate:"omitempty,min=3,max=100"`
-	Html         *string `json:"html,omitempty" validate:"omitempty"`
-	Status       *string `json:"status" validate:"omitempty,oneof=rascunho enviado aprovado"`
-	ArquivoFinal *string `json:"arquivoFinal"`
+	Titulo *string `json:"titulo" validate:"omitempty,min=3,max=100"`
+	Html   *string `json:"html,omitempty" validate:"omitempty"`
+	Status *string `json:"status" validate:"omitempty,oneof=rascunho enviado aprovado recusado expirado cancelado"`
+	// Motivo acompanha a mudança de status no histórico.
+	Motivo *string `json:"motivo" validate:"omitempty,max=1000"`
+	// ValidoAte prorroga (ou antecipa) a validade; permite reenviar uma
+	// proposta expirada.
+	ValidoAte *time.Time `json:"validoAte"`
+
+	// Ator identifica quem fez a mudança de status; preenchido pelo handler.
+	Ator string `json:"-"`
+
+	// Artefato é preenchido internamente quando um novo PDF é salvo e é o único
+	// caminho que grava arquivo_final, com checksum e tamanho. A chave não é
+	// aceita do cliente, que poderia apontar para o PDF de outra proposta.
+	Artefato *ArtefatoPDF `json:"-"`
 }
 
+// RegerarProposta traz os dados para gerar o conteúdo de novo. Em uma
+// proposta vinculada a um cliente (ou com ClienteId informado), empresa,
+// contato e logo do cliente podem ser omitidos e são resolvidos do cadastro;
+// o mesmo vale para cores e logo com um kit de marca.
 type RegerarProposta struct {
-	NomeEmpresa string   `json:"nomeEmpresa" validate:"required"`
-	NomeCliente string   `json:"nomeCliente" validate:"required"`
-	Prompt      string   `json:"prompt" validate:"required,min=20"`
-	Cores       []string `json:"cores" validate:"required,dive,hexcolor"`
-	Logo        string   `json:"logo" validate:"omitempty,url"`
-	LogoCliente string   `json:"logoCliente" validate:"omitempty,url"`
+	ClienteId   *uuid.UUID `json:"clienteId"`
+	NomeEmpresa string     `json:"nomeEmpresa"`
+	NomeCliente string     `json:"nomeCliente"`
+	Prompt      string     `json:"prompt" validate:"required,min=20"`
+	Cores       []string   `json:"cores" validate:"omitempty,dive,hexcolor"`
+	Logo        string     `json:"logo" validate:"omitempty,url"`
+	LogoCliente string     `json:"logoCliente" validate:"omitempty,url"`
+	// MarcaId troca o kit de marca; Marca sobrescreve campos do kit (ou, sem
+	// kit, os atuais).
+	MarcaId *uuid.UUID   `json:"marcaId"`
+	Marca   *EstiloMarca `json:"marca"`
+	// Itens substitui a tabela de preços; omitido, os itens atuais são
+	// mantidos e uma lista vazia remove todos.
+	Itens *[]ItemProposta `json:"itens"`
+}
+
+// AplicarEm copia os dados de regeneração para a proposta, mantendo, se
+// vazios, a empresa, o contato, as cores, os logos e o estilo atuais.
+func (r RegerarProposta) AplicarEm(p *Proposta) {
+	if r.NomeEmpresa != "" {
+		p.NomeEmpresa = r.NomeEmpresa
+	}
+	if r.NomeCliente != "" {
+		p.NomeCliente = r.NomeCliente
+	}
+	p.Prompt = r.Prompt
+	if len(r.Cores) > 0 {
+		p.Cores = r.Cores
+	}
+	if r.Logo != "" {
+		p.Logo = r.Logo
+	}
+	if r.LogoCliente != "" {
+		p.LogoCliente = r.LogoCliente
+	}
+	if r.Marca != nil {
+		estilo := *r.Marca
+		if p.Marca != nil {
+			estilo = r.Marca.Sobre(*p.Marca)
+		}
+		p.Marca = &estilo
+	}
+	if r.Itens != nil {
+		p.Itens = *r.Itens
+	}
+	p.CalcularTotais()
 }
 
 func HexColor(fl validator.FieldLevel) bool {
@@ -69,6 +194,10 @@ func ValidarStructProposta(p *Proposta) error {
 			)
 		}
 		return err
+	} else if err := ValidarItens(p.Itens); err != nil {
+		// Os itens são validados fora do validator, que não conhece decimal.Decimal.
+		logger.Error("Erro de validação nos itens", err)
+		return err
 	} else {
 		logger.Info("Validação concluída com sucesso!")
 		return nil
@@ -93,8 +222,12 @@ func ValidarStructRegerarProposta(p *RegerarProposta) error {
 			)
 		}
 		return err
-	} else {
-		logger.Info("Validação concluída com sucesso!")
-		return nil
+	} else if p.Itens != nil {
+		if err := ValidarItens(*p.Itens); err != nil {
+			logger.Error("Erro de validação nos itens", err)
+			return err
+		}
 	}
+	logger.Info("Validação concluída com sucesso!")
+	return nil
 }
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	VersaoGeracao     = "geracao"
+	VersaoRegeneracao = "regeneracao"
+	VersaoEdicaoHtml  = "edicao_html"
+	VersaoRestauracao = "restauracao"
+	VersaoAceite      = "aceite"
+)
+
+type PropostaVersao struct {
+	Id           uuid.UUID `json:"id"`
+	PropostaId   uuid.UUID `json:"propostaId"`
+	Numero       int       `json:"numero"`
+	Origem       string    `json:"origem"`
+	RestauradaDe *int      `json:"restauradaDe,omitempty"`
+	Titulo       string    `json:"titulo"`
+	NomeEmpresa  string    `json:"nomeEmpresa"`
+	NomeCliente  string    `json:"nomeCliente"`
+	Prompt       string    `json:"prompt"`
+	Cores        []string  `json:"cores"`
+	Logo         string    `json:"logo"`
+	LogoCliente  string    `json:"logoCliente"`
+	Html         string    `json:"html,omitempty"`
+	ArquivoFinal string    `json:"arquivoFinal"`
+	DataCriacao  time.Time `json:"dataCriacao"`
+
+	ArquivoSha256  *string `json:"arquivoSha256,omitempty"`
+	ArquivoTamanho *int64  `json:"arquivoTamanho,omitempty"`
+}
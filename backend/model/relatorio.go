@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	PeriodoSemana = "semana"
+	PeriodoMes    = "mes"
+
+	// PeriodosPadrao é quantos períodos o relatório cobre quando "de" não é informado.
+	PeriodosPadrao = 12
+)
+
+// FiltroPipeline são os parâmetros de GET /relatorios/pipeline. As propostas
+// entram no período em que foram criadas (coorte), então as métricas de um
+// período continuam mudando enquanto as propostas dele avançam no funil.
+type FiltroPipeline struct {
+	Periodo     string `form:"periodo" validate:"omitempty,oneof=semana mes"`
+	De          string `form:"de"`
+	Ate         string `form:"ate"`
+	NomeEmpresa string `form:"nomeEmpresa" validate:"omitempty,max=200"`
+}
+
+func ValidarStructFiltroPipeline(f *FiltroPipeline) error {
+	return validator.New().Struct(f)
+}
+
+// Intervalo converte de e ate no mesmo formato dos filtros da listagem.
+func (f *FiltroPipeline) Intervalo() (IntervaloDatas, error) {
+	return parseIntervalo("de", f.De, "ate", f.Ate)
+}
+
+// MetricasPipeline agrega as propostas criadas em um período, ou em todo o
+// intervalo quando Inicio é nulo. As taxas e a mediana são nulas quando não
+// há base para calculá-las.
+type MetricasPipeline struct {
+	Inicio                   *time.Time     `json:"inicio,omitempty"`
+	Total                    int            `json:"total"`
+	PorStatus                map[string]int `json:"porStatus"`
+	Enviadas                 int            `json:"enviadas"`
+	Aprovadas                int            `json:"aprovadas"`
+	Recusadas                int            `json:"recusadas"`
+	TaxaConversao            *float64       `json:"taxaConversao"`
+	MedianaHorasAteAprovacao *float64       `json:"medianaHorasAteAprovacao"`
+	Regeneracoes             int            `json:"regeneracoes"`
+	Geracoes                 int            `json:"geracoes"`
+	FalhasGeracao            int            `json:"falhasGeracao"`
+	TaxaFalhaGeracao         *float64       `json:"taxaFalhaGeracao"`
+}
+
+type RelatorioPipeline struct {
+	Periodo     string             `json:"periodo"`
+	De          time.Time          `json:"de"`
+	Ate         time.Time          `json:"ate"`
+	NomeEmpresa string             `json:"nomeEmpresa,omitempty"`
+	Totais      MetricasPipeline   `json:"totais"`
+	Periodos    []MetricasPipeline `json:"periodos"`
+}
+
+// InicioPeriodo trunca t (em UTC) para o início da semana, na segunda-feira,
+// ou do mês, como o date_trunc do Postgres.
+func InicioPeriodo(t time.Time, periodo string) time.Time {
+	t = t.UTC()
+	dia := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if periodo == PeriodoMes {
+		return dia.AddDate(0, 0, 1-dia.Day())
+	}
+	return dia.AddDate(0, 0, -((int(dia.Weekday()) + 6) % 7))
+}
+
+// ProximoPeriodo avança inicio em uma semana ou um mês.
+func ProximoPeriodo(inicio time.Time, periodo string) time.Time {
+	if periodo == PeriodoMes {
+		return inicio.AddDate(0, 1, 0)
+	}
+	return inicio.AddDate(0, 0, 7)
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInicioPeriodo(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	casos := []struct {
+		nome     string
+		t        time.Time
+		periodo  string
+		esperado time.Time
+	}{
+		{"domingo no fim do dia fica na semana anterior", time.Date(2025, 3, 9, 23, 59, 59, 999999999, time.UTC), PeriodoSemana, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"segunda à meia-noite abre a semana", time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), PeriodoSemana, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"domingo à noite em BRT já é segunda em UTC", time.Date(2025, 3, 9, 22, 0, 0, 0, saoPaulo), PeriodoSemana, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"segunda cedo em UTC ainda é domingo em BRT, mas conta em UTC", time.Date(2025, 3, 10, 2, 59, 0, 0, time.UTC), PeriodoSemana, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"semana que atravessa o ano", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), PeriodoSemana, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{"último instante do mês", time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC), PeriodoMes, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"primeiro instante do mês", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), PeriodoMes, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"fim de janeiro em BRT já é fevereiro em UTC", time.Date(2025, 1, 31, 22, 0, 0, 0, saoPaulo), PeriodoMes, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"29 de fevereiro em ano bissexto", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), PeriodoMes, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"dezembro", time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC), PeriodoMes, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			obtido := InicioPeriodo(c.t, c.periodo)
+			if !obtido.Equal(c.esperado) || obtido.Location() != time.UTC {
+				t.Errorf("InicioPeriodo(%s, %s) = %s, esperado %s", c.t, c.periodo, obtido, c.esperado)
+			}
+		})
+	}
+}
+
+func TestProximoPeriodo(t *testing.T) {
+	casos := []struct {
+		nome     string
+		inicio   time.Time
+		periodo  string
+		esperado time.Time
+	}{
+		{"semana", time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), PeriodoSemana, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"semana que vira o ano", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), PeriodoSemana, time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"janeiro para fevereiro", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), PeriodoMes, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"fevereiro bissexto para março", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), PeriodoMes, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"dezembro para janeiro", time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), PeriodoMes, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			obtido := ProximoPeriodo(c.inicio, c.periodo)
+			if !obtido.Equal(c.esperado) {
+				t.Errorf("ProximoPeriodo(%s, %s) = %s, esperado %s", c.inicio, c.periodo, obtido, c.esperado)
+			}
+			if InicioPeriodo(obtido, c.periodo) != obtido {
+				t.Errorf("ProximoPeriodo(%s, %s) = %s não é início de período", c.inicio, c.periodo, obtido)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StatusRascunho  = "rascunho"
+	StatusEnviado   = "enviado"
+	StatusAprovado  = "aprovado"
+	StatusRecusado  = "recusado"
+	StatusExpirado  = "expirado"
+	StatusCancelado = "cancelado"
+)
+
+// Atores padrão registrados no histórico quando a mudança não vem de um usuário identificado.
+const (
+	AtorAPI     = "api"
+	AtorSistema = "sistema"
+	AtorCliente = "cliente"
+)
+
+var ErrStatusInvalido = errors.New("status inválido")
+
+var ErrTransicaoInvalida = errors.New("transição de status não permitida")
+
+var ErrValidadeInvalida = errors.New("a validade da proposta deve ser uma data futura")
+
+var ErrConteudoBloqueado = errors.New("o conteúdo da proposta não pode ser alterado no status atual")
+
+// transicoesStatus define a máquina de estados da proposta. Aprovada, recusada
+// e cancelada são estados finais; uma proposta enviada pode voltar a rascunho
+// para revisão, e uma expirada pode ser reaberta como rascunho.
+var transicoesStatus = map[string][]string{
+	StatusRascunho:  {StatusEnviado, StatusCancelado},
+	StatusEnviado:   {StatusRascunho, StatusAprovado, StatusRecusado, StatusExpirado, StatusCancelado},
+	StatusExpirado:  {StatusRascunho, StatusCancelado},
+	StatusAprovado:  {},
+	StatusRecusado:  {},
+	StatusCancelado: {},
+}
+
+func StatusValido(status string) bool {
+	_, ok := transicoesStatus[status]
+	return ok
+}
+
+// ValidarTransicao confere se a proposta pode ir do status atual para o novo,
+// incluindo as guardas do estado de destino.
+func ValidarTransicao(p *Proposta, novo string) error {
+	if !StatusValido(novo) {
+		return fmt.Errorf("%w: %s", ErrStatusInvalido, novo)
+	}
+	if !slices.Contains(transicoesStatus[p.Status], novo) {
+		return fmt.Errorf("%w: %s -> %s", ErrTransicaoInvalida, p.Status, novo)
+	}
+	if novo == StatusEnviado && p.ArquivoFinal == "" {
+		return fmt.Errorf("%w: a proposta só pode ser enviada depois que o PDF for gerado", ErrTransicaoInvalida)
+	}
+	if (novo == StatusEnviado || novo == StatusAprovado) && p.Vencida(time.Now()) {
+		return fmt.Errorf("%w: a validade da proposta terminou em %s", ErrTransicaoInvalida, p.ValidoAte.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// ValidarAlteracaoConteudo confere se a regeneração ou a restauração de uma
+// versão podem substituir o HTML e o PDF da proposta. Depois da decisão do
+// cliente o documento é a evidência do aceite, e cancelada e expirada precisam
+// voltar a rascunho antes de mudar.
+func ValidarAlteracaoConteudo(status string) error {
+	switch status {
+	case StatusAprovado, StatusRecusado, StatusCancelado, StatusExpirado:
+		return fmt.Errorf("%w: a proposta está %s", ErrConteudoBloqueado, status)
+	}
+	return nil
+}
+
+// TransicaoStatus é uma linha de proposta_status_historico. DeStatus é nulo no
+// registro de criação da proposta.
+type TransicaoStatus struct {
+	Id          uuid.UUID `json:"id"`
+	PropostaId  uuid.UUID `json:"propostaId"`
+	DeStatus    *string   `json:"deStatus"`
+	ParaStatus  string    `json:"paraStatus"`
+	Ator        string    `json:"ator"`
+	Motivo      *string   `json:"motivo,omitempty"`
+	DataCriacao time.Time `json:"dataCriacao"`
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var todosStatus = []string{StatusRascunho, StatusEnviado, StatusAprovado, StatusRecusado, StatusExpirado, StatusCancelado}
+
+func TestValidarTransicaoTodasAsCombinacoes(t *testing.T) {
+	permitidas := map[[2]string]bool{
+		{StatusRascunho, StatusEnviado}:   true,
+		{StatusRascunho, StatusCancelado}: true,
+		{StatusEnviado, StatusRascunho}:   true,
+		{StatusEnviado, StatusAprovado}:   true,
+		{StatusEnviado, StatusRecusado}:   true,
+		{StatusEnviado, StatusExpirado}:   true,
+		{StatusEnviado, StatusCancelado}:  true,
+		{StatusExpirado, StatusRascunho}:  true,
+		{StatusExpirado, StatusCancelado}: true,
+	}
+	for _, de := range todosStatus {
+		for _, para := range todosStatus {
+			t.Run(de+"->"+para, func(t *testing.T) {
+				// Com PDF e sem validade, só a máquina de estados decide.
+				p := &Proposta{Status: de, ArquivoFinal: "propostas/proposta.pdf"}
+				err := ValidarTransicao(p, para)
+				if permitidas[[2]string{de, para}] {
+					if err != nil {
+						t.Errorf("transição permitida recusada: %v", err)
+					}
+				} else if !errors.Is(err, ErrTransicaoInvalida) {
+					t.Errorf("erro %v, esperado %v", err, ErrTransicaoInvalida)
+				}
+			})
+		}
+	}
+}
+
+func TestValidarTransicaoGuardas(t *testing.T) {
+	ontem := time.Now().Add(-24 * time.Hour)
+	amanha := time.Now().Add(24 * time.Hour)
+	casos := []struct {
+		nome     string
+		proposta Proposta
+		novo     string
+		esperado error
+	}{
+		{"status desconhecido", Proposta{Status: StatusRascunho}, "arquivado", ErrStatusInvalido},
+		{"status vazio", Proposta{Status: StatusRascunho}, "", ErrStatusInvalido},
+		{"envio sem PDF", Proposta{Status: StatusRascunho}, StatusEnviado, ErrTransicaoInvalida},
+		{"envio com PDF", Proposta{Status: StatusRascunho, ArquivoFinal: "x.pdf"}, StatusEnviado, nil},
+		{"envio com validade vencida", Proposta{Status: StatusRascunho, ArquivoFinal: "x.pdf", ValidoAte: &ontem}, StatusEnviado, ErrTransicaoInvalida},
+		{"envio dentro da validade", Proposta{Status: StatusRascunho, ArquivoFinal: "x.pdf", ValidoAte: &amanha}, StatusEnviado, nil},
+		{"reenvio de expirada sem reabrir", Proposta{Status: StatusExpirado, ArquivoFinal: "x.pdf", ValidoAte: &amanha}, StatusEnviado, ErrTransicaoInvalida},
+		{"aprovação vencida", Proposta{Status: StatusEnviado, ArquivoFinal: "x.pdf", ValidoAte: &ontem}, StatusAprovado, ErrTransicaoInvalida},
+		{"aprovação dentro da validade", Proposta{Status: StatusEnviado, ArquivoFinal: "x.pdf", ValidoAte: &amanha}, StatusAprovado, nil},
+		{"recusa vencida", Proposta{Status: StatusEnviado, ArquivoFinal: "x.pdf", ValidoAte: &ontem}, StatusRecusado, nil},
+		{"expiração vencida", Proposta{Status: StatusEnviado, ArquivoFinal: "x.pdf", ValidoAte: &ontem}, StatusExpirado, nil},
+		{"cancelamento vencido", Proposta{Status: StatusEnviado, ArquivoFinal: "x.pdf", ValidoAte: &ontem}, StatusCancelado, nil},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			err := ValidarTransicao(&c.proposta, c.novo)
+			if c.esperado == nil && err != nil {
+				t.Errorf("erro inesperado: %v", err)
+			}
+			if c.esperado != nil && !errors.Is(err, c.esperado) {
+				t.Errorf("erro %v, esperado %v", err, c.esperado)
+			}
+		})
+	}
+}
+
+func TestStatusValido(t *testing.T) {
+	for _, status := range todosStatus {
+		if !StatusValido(status) {
+			t.Errorf("StatusValido(%q) = false", status)
+		}
+	}
+	for _, status := range []string{"", "Rascunho", "arquivado", " enviado"} {
+		if StatusValido(status) {
+			t.Errorf("StatusValido(%q) = true", status)
+		}
+	}
+}
+
+func TestValidarAlteracaoConteudo(t *testing.T) {
+	alteraveis := map[string]bool{StatusRascunho: true, StatusEnviado: true}
+	for _, status := range todosStatus {
+		t.Run(status, func(t *testing.T) {
+			err := ValidarAlteracaoConteudo(status)
+			if alteraveis[status] {
+				if err != nil {
+					t.Errorf("alteração recusada: %v", err)
+				}
+			} else if !errors.Is(err, ErrConteudoBloqueado) {
+				t.Errorf("erro %v, esperado %v", err, ErrConteudoBloqueado)
+			}
+		})
+	}
+}
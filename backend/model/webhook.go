@@ -0,0 +1,169 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// Eventos do ciclo de vida da proposta enviados aos webhooks.
+const (
+	EventoPropostaCriada        = "proposta.criada"
+	EventoPropostaGerada        = "proposta.gerada"
+	EventoPropostaGeracaoFalhou = "proposta.geracao_falhou"
+	EventoPropostaEnviada       = "proposta.enviada"
+	EventoPropostaAprovada      = "proposta.aprovada"
+	EventoPropostaRecusada      = "proposta.recusada"
+	EventoPropostaExpirada      = "proposta.expirada"
+	EventoPropostaCancelada     = "proposta.cancelada"
+	EventoPropostaExcluida      = "proposta.excluida"
+)
+
+// EventosWebhook lista os eventos que uma assinatura pode receber.
+var EventosWebhook = []string{
+	EventoPropostaCriada,
+	EventoPropostaGerada,
+	EventoPropostaGeracaoFalhou,
+	EventoPropostaEnviada,
+	EventoPropostaAprovada,
+	EventoPropostaRecusada,
+	EventoPropostaExpirada,
+	EventoPropostaCancelada,
+	EventoPropostaExcluida,
+}
+
+// eventosPorStatus associa o status de destino de uma transição ao evento
+// publicado. A volta para rascunho não gera evento.
+var eventosPorStatus = map[string]string{
+	StatusEnviado:   EventoPropostaEnviada,
+	StatusAprovado:  EventoPropostaAprovada,
+	StatusRecusado:  EventoPropostaRecusada,
+	StatusExpirado:  EventoPropostaExpirada,
+	StatusCancelado: EventoPropostaCancelada,
+}
+
+// EventoDoStatus devolve o evento publicado quando a proposta entra em status.
+func EventoDoStatus(status string) (string, bool) {
+	evento, ok := eventosPorStatus[status]
+	return evento, ok
+}
+
+const (
+	EntregaPendente   = "pendente"
+	EntregaEntregando = "entregando"
+	EntregaEntregue   = "entregue"
+	EntregaFalhou     = "falhou"
+)
+
+// EntregaStatusValido indica se status é um dos status de entrega, para
+// filtros vindos da query string.
+func EntregaStatusValido(status string) bool {
+	switch status {
+	case EntregaPendente, EntregaEntregando, EntregaEntregue, EntregaFalhou:
+		return true
+	}
+	return false
+}
+
+// ErrDestinoWebhookInvalido indica uma URL de webhook que não resolve ou que
+// resolve para um endereço interno (loopback, rede privada, link-local,
+// metadados de nuvem...).
+var ErrDestinoWebhookInvalido = errors.New("a url do webhook não aponta para um destino público")
+
+// faixasProibidasWebhook completa as verificações de netip.Addr com faixas
+// especiais que não são roteáveis na internet.
+var faixasProibidasWebhook = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// DestinoWebhookPermitido indica se uma entrega pode ser enviada para ip. São
+// recusados loopback, redes privadas, link-local (inclusive 169.254.169.254),
+// multicast e as demais faixas especiais.
+func DestinoWebhookPermitido(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, faixa := range faixasProibidasWebhook {
+		if faixa.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Webhook é uma assinatura de eventos. O segredo assina o corpo de cada
+// entrega com HMAC-SHA256 e só é devolvido na criação.
+type Webhook struct {
+	Id          uuid.UUID `json:"id"`
+	Url         string    `json:"url"`
+	Eventos     []string  `json:"eventos"`
+	Segredo     string    `json:"-"`
+	Ativo       bool      `json:"ativo"`
+	DataCriacao time.Time `json:"dataCriacao"`
+}
+
+type CriarWebhook struct {
+	Url     string   `json:"url" validate:"required,http_url,max=2000"`
+	Eventos []string `json:"eventos" validate:"required,min=1,dive,evento_webhook"`
+	Segredo string   `json:"segredo" validate:"omitempty,min=16,max=255"`
+}
+
+func ValidarStructCriarWebhook(w *CriarWebhook) error {
+	validate := validator.New()
+	validate.RegisterValidation("http_url", func(fl validator.FieldLevel) bool {
+		u, err := url.Parse(fl.Field().String())
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	})
+	validate.RegisterValidation("evento_webhook", func(fl validator.FieldLevel) bool {
+		return slices.Contains(EventosWebhook, fl.Field().String())
+	})
+	return validate.Struct(w)
+}
+
+// EventoWebhook é o corpo JSON enviado em cada entrega. O Id identifica o
+// evento e se repete nas entregas de assinaturas diferentes e nos reenvios,
+// permitindo ao receptor descartar duplicatas.
+type EventoWebhook struct {
+	Id         uuid.UUID `json:"id"`
+	Evento     string    `json:"evento"`
+	PropostaId uuid.UUID `json:"propostaId"`
+	Data       time.Time `json:"data"`
+	Dados      any       `json:"dados"`
+}
+
+// EntregaWebhook é uma linha do log persistente de entregas.
+type EntregaWebhook struct {
+	Id               uuid.UUID       `json:"id"`
+	WebhookId        uuid.UUID       `json:"webhookId"`
+	EventoId         uuid.UUID       `json:"eventoId"`
+	Evento           string          `json:"evento"`
+	PropostaId       uuid.UUID       `json:"propostaId"`
+	Payload          json.RawMessage `json:"payload"`
+	Status           string          `json:"status"`
+	Tentativas       int             `json:"tentativas"`
+	ProximaTentativa time.Time       `json:"proximaTentativa"`
+	UltimoStatusHttp *int            `json:"ultimoStatusHttp,omitempty"`
+	UltimoErro       *string         `json:"ultimoErro,omitempty"`
+	DataCriacao      time.Time       `json:"dataCriacao"`
+	LastUpdate       time.Time       `json:"lastUpdate"`
+	EntregueEm       *time.Time      `json:"entregueEm,omitempty"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestDestinoWebhookPermitido(t *testing.T) {
+	casos := []struct {
+		ip       string
+		esperado bool
+	}{
+		{"93.184.215.14", true},
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.0.10", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+	for _, c := range casos {
+		t.Run(c.ip, func(t *testing.T) {
+			if got := DestinoWebhookPermitido(netip.MustParseAddr(c.ip)); got != c.esperado {
+				t.Errorf("DestinoWebhookPermitido(%s) = %v, esperado %v", c.ip, got, c.esperado)
+			}
+		})
+	}
+}
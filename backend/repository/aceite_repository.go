@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"propulse/model"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const aceiteColunas = `id, proposta_id, link_id, decisao, nome, comentario, ip, user_agent, documento_sha256, certificado_chave, certificado_sha256, data_criacao`
+
+type AceiteRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewAceiteRepository(connection *pgxpool.Pool) AceiteRepository {
+	return AceiteRepository{
+		connection: connection,
+	}
+}
+
+func scanAceite(row pgx.Row) (*model.Aceite, error) {
+	var a model.Aceite
+	err := row.Scan(
+		&a.Id,
+		&a.PropostaId,
+		&a.LinkId,
+		&a.Decisao,
+		&a.Nome,
+		&a.Comentario,
+		&a.Ip,
+		&a.UserAgent,
+		&a.DocumentoSha256,
+		&a.CertificadoChave,
+		&a.CertificadoSha256,
+		&a.DataCriacao,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RegistrarAceite grava a evidência e move o status da proposta na mesma
+// transação. A linha da proposta é bloqueada para validar a transição e conferir
+// que o documento decidido pelo cliente ainda é o PDF vigente.
+func (ar *AceiteRepository) RegistrarAceite(ctx context.Context, aceite model.Aceite, novoStatus string, ator string) (*model.Aceite, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tx, err := ar.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de aceite", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	atual, err := scanProposta(tx.QueryRow(ctx, `SELECT `+propostaColunas+` FROM propostas WHERE id = $1 FOR UPDATE`, aceite.PropostaId))
+	if err != nil {
+		return nil, err
+	}
+	if atual.ArquivoSha256 == nil || *atual.ArquivoSha256 != aceite.DocumentoSha256 {
+		return nil, model.ErrDocumentoDivergente
+	}
+	if err := model.ValidarTransicao(atual, novoStatus); err != nil {
+		return nil, err
+	}
+
+	p, err := scanProposta(tx.QueryRow(ctx, `UPDATE propostas SET status = $1, last_update = $2 WHERE id = $3 RETURNING `+propostaColunas, novoStatus, time.Now(), aceite.PropostaId))
+	if err != nil {
+		logger.Error("Erro ao atualizar status no aceite", err, zap.String("proposta_id", aceite.PropostaId.String()))
+		return nil, err
+	}
+	if err := inserirTransicao(ctx, tx, aceite.PropostaId, &atual.Status, novoStatus, ator, aceite.Comentario); err != nil {
+		logger.Error("Erro ao registrar transição de status do aceite", err)
+		return nil, err
+	}
+	if err := inserirEventoStatus(ctx, tx, p); err != nil {
+		logger.Error("Erro ao publicar evento do aceite", err)
+		return nil, err
+	}
+
+	query := `INSERT INTO proposta_aceites (proposta_id, link_id, decisao, nome, comentario, ip, user_agent, documento_sha256)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING ` + aceiteColunas
+
+	a, err := scanAceite(tx.QueryRow(ctx, query,
+		aceite.PropostaId,
+		aceite.LinkId,
+		aceite.Decisao,
+		aceite.Nome,
+		aceite.Comentario,
+		aceite.Ip,
+		aceite.UserAgent,
+		aceite.DocumentoSha256,
+	))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, model.ErrAceiteJaRegistrado
+	}
+	if err != nil {
+		logger.Error("Erro ao gravar evidência do aceite", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar aceite", err)
+		return nil, err
+	}
+	logger.Info("Decisão do cliente registrada", zap.String("proposta_id", a.PropostaId.String()), zap.String("decisao", a.Decisao))
+	return a, nil
+}
+
+func (ar *AceiteRepository) FindAceiteByProposta(ctx context.Context, propostaID uuid.UUID) (*model.Aceite, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	a, err := scanAceite(ar.connection.QueryRow(ctx, `SELECT `+aceiteColunas+` FROM proposta_aceites WHERE proposta_id = $1`, propostaID))
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// RegistrarCertificado torna o PDF com a página de certificado o arquivo atual
+// da proposta, registrando artefato e versão, e o vincula ao aceite.
+func (ar *AceiteRepository) RegistrarCertificado(ctx context.Context, aceite *model.Aceite, artefato model.ArtefatoPDF) (*model.Aceite, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tx, err := ar.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação do certificado", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE propostas
+        SET arquivo_final = $1, arquivo_sha256 = $2, arquivo_tamanho = $3, last_update = $4
+        WHERE id = $5
+        RETURNING ` + propostaColunas
+
+	p, err := scanProposta(tx.QueryRow(ctx, query, artefato.Chave, artefato.Sha256, artefato.Tamanho, time.Now(), aceite.PropostaId))
+	if err != nil {
+		logger.Error("Erro ao atualizar PDF da proposta com o certificado", err)
+		return nil, err
+	}
+	if _, err := inserirArtefato(ctx, tx, p.Id, artefato); err != nil {
+		logger.Error("Erro ao registrar artefato do certificado", err)
+		return nil, err
+	}
+	if _, err := inserirVersao(ctx, tx, p, model.VersaoAceite, nil); err != nil {
+		logger.Error("Erro ao registrar versão do certificado", err)
+		return nil, err
+	}
+
+	query = `UPDATE proposta_aceites
+        SET certificado_chave = $1, certificado_sha256 = $2
+        WHERE id = $3
+        RETURNING ` + aceiteColunas
+
+	a, err := scanAceite(tx.QueryRow(ctx, query, artefato.Chave, artefato.Sha256, aceite.Id))
+	if err != nil {
+		logger.Error("Erro ao vincular certificado ao aceite", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar certificado do aceite", err)
+		return nil, err
+	}
+	return a, nil
+}
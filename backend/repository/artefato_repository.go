@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/shared/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ArtefatoRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewArtefatoRepository(connection *pgxpool.Pool) ArtefatoRepository {
+	return ArtefatoRepository{
+		connection: connection,
+	}
+}
+
+// inserirArtefato registra o PDF salvo na transação que passa a referenciá-lo.
+func inserirArtefato(ctx context.Context, tx pgx.Tx, propostaID uuid.UUID, artefato model.ArtefatoPDF) (*model.ArtefatoPDF, error) {
+	query := `INSERT INTO proposta_artefatos (proposta_id, chave, sha256, tamanho)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, proposta_id, chave, sha256, tamanho, data_criacao`
+
+	var a model.ArtefatoPDF
+	err := tx.QueryRow(ctx, query, propostaID, artefato.Chave, artefato.Sha256, artefato.Tamanho).Scan(
+		&a.Id,
+		&a.PropostaId,
+		&a.Chave,
+		&a.Sha256,
+		&a.Tamanho,
+		&a.DataCriacao,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (ar *ArtefatoRepository) ListarArtefatos(ctx context.Context, propostaID uuid.UUID) ([]model.ArtefatoPDF, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, proposta_id, chave, sha256, tamanho, data_criacao
+        FROM proposta_artefatos
+        WHERE proposta_id = $1
+        ORDER BY data_criacao DESC`
+
+	rows, err := ar.connection.Query(ctx, query, propostaID)
+	if err != nil {
+		logger.Error("Erro ao listar artefatos da proposta", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	artefatos := []model.ArtefatoPDF{}
+	for rows.Next() {
+		var a model.ArtefatoPDF
+		if err := rows.Scan(&a.Id, &a.PropostaId, &a.Chave, &a.Sha256, &a.Tamanho, &a.DataCriacao); err != nil {
+			logger.Error("Erro ao fazer scan do artefato", err)
+			return nil, err
+		}
+		artefatos = append(artefatos, a)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração dos artefatos", err)
+		return nil, err
+	}
+	return artefatos, nil
+}
+
+// FiltrarNaoReferenciadas devolve, dentre as chaves informadas, as que não são
+// referenciadas por nenhuma proposta, versão ou artefato registrado.
+func (ar *ArtefatoRepository) FiltrarNaoReferenciadas(ctx context.Context, chaves []string) ([]string, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT c.chave FROM unnest($1::text[]) AS c(chave)
+        WHERE NOT EXISTS (SELECT 1 FROM proposta_artefatos a WHERE a.chave = c.chave)
+            AND NOT EXISTS (SELECT 1 FROM propostas p WHERE p.arquivo_final = c.chave)
+            AND NOT EXISTS (SELECT 1 FROM proposta_versoes v WHERE v.arquivo_final = c.chave)`
+
+	rows, err := ar.connection.Query(ctx, query, chaves)
+	if err != nil {
+		logger.Error("Erro ao verificar referências de artefatos", err)
+		return nil, err
+	}
+	orfas, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		logger.Error("Erro ao ler referências de artefatos", err)
+		return nil, err
+	}
+	return orfas, nil
+}
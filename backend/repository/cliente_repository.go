@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"propulse/model"
+	"propulse/shared/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const clienteColunas = `id, nome_empresa, cnpj, contatos, emails, logo, data_criacao, last_update`
+
+type ClienteRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewClienteRepository(connection *pgxpool.Pool) ClienteRepository {
+	return ClienteRepository{
+		connection: connection,
+	}
+}
+
+func scanCliente(row pgx.Row) (*model.Cliente, error) {
+	var c model.Cliente
+	var logo *string
+	err := row.Scan(
+		&c.Id,
+		&c.NomeEmpresa,
+		&c.Cnpj,
+		&c.Contatos,
+		&c.Emails,
+		&logo,
+		&c.DataCriacao,
+		&c.LastUpdate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if logo != nil {
+		c.Logo = *logo
+	}
+	return &c, nil
+}
+
+// erroCliente traduz as violações de restrição da tabela clientes.
+func erroCliente(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return model.ErrClienteDuplicado
+		case "23503":
+			return model.ErrClienteEmUso
+		}
+	}
+	return err
+}
+
+func nuloSeVazio(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func (cr *ClienteRepository) CriarCliente(ctx context.Context, cliente model.Cliente) (*model.Cliente, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO clientes (nome_empresa, cnpj, contatos, emails, logo)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING ` + clienteColunas
+
+	c, err := scanCliente(cr.connection.QueryRow(ctx, query, cliente.NomeEmpresa, cliente.Cnpj, cliente.Contatos, cliente.Emails, nuloSeVazio(cliente.Logo)))
+	if err != nil {
+		if err = erroCliente(err); !errors.Is(err, model.ErrClienteDuplicado) {
+			logger.Error("Erro ao criar cliente", err)
+		}
+		return nil, err
+	}
+	logger.Info("Cliente criado", zap.String("id", c.Id.String()))
+	return c, nil
+}
+
+// ListarClientes devolve os clientes em ordem alfabética. busca filtra por
+// parte do nome da empresa ou pelo início do CNPJ.
+func (cr *ClienteRepository) ListarClientes(ctx context.Context, busca string) ([]model.Cliente, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + clienteColunas + ` FROM clientes
+        WHERE $1 = '' OR nome_empresa ILIKE '%' || $1 || '%' OR ($2 <> '' AND cnpj LIKE $2 || '%')
+        ORDER BY lower(nome_empresa), id`
+
+	rows, err := cr.connection.Query(ctx, query, escaparLike(busca), escaparLike(model.NormalizarCNPJ(busca)))
+	if err != nil {
+		logger.Error("Erro ao listar clientes", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	clientes := []model.Cliente{}
+	for rows.Next() {
+		c, err := scanCliente(rows)
+		if err != nil {
+			logger.Error("Erro ao fazer scan do cliente", err)
+			return nil, err
+		}
+		clientes = append(clientes, *c)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração dos clientes", err)
+		return nil, err
+	}
+	return clientes, nil
+}
+
+func (cr *ClienteRepository) FindCliente(ctx context.Context, id uuid.UUID) (*model.Cliente, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	return scanCliente(cr.connection.QueryRow(ctx, `SELECT `+clienteColunas+` FROM clientes WHERE id = $1`, id))
+}
+
+// AtualizarCliente substitui os dados do cliente. As propostas já criadas
+// mantêm os nomes com que foram geradas.
+func (cr *ClienteRepository) AtualizarCliente(ctx context.Context, id uuid.UUID, cliente model.Cliente) (*model.Cliente, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE clientes
+        SET nome_empresa = $1, cnpj = $2, contatos = $3, emails = $4, logo = $5, last_update = now()
+        WHERE id = $6
+        RETURNING ` + clienteColunas
+
+	c, err := scanCliente(cr.connection.QueryRow(ctx, query, cliente.NomeEmpresa, cliente.Cnpj, cliente.Contatos, cliente.Emails, nuloSeVazio(cliente.Logo), id))
+	if err != nil {
+		if err = erroCliente(err); !errors.Is(err, model.ErrClienteDuplicado) && !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Erro ao atualizar cliente", err, zap.String("id", id.String()))
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (cr *ClienteRepository) DeleteCliente(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tag, err := cr.connection.Exec(ctx, `DELETE FROM clientes WHERE id = $1`, id)
+	if err != nil {
+		if err = erroCliente(err); !errors.Is(err, model.ErrClienteEmUso) {
+			logger.Error("Erro ao remover cliente", err, zap.String("id", id.String()))
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"propulse/shared/env"
+	"time"
+)
+
+var dbTimeout = env.Duration("DB_TIMEOUT", 5*time.Second)
+
+// exportTimeout limita as consultas que alimentam exportações em streaming.
+var exportTimeout = env.Duration("EXPORT_TIMEOUT", 5*time.Minute)
+
+// comTimeout limita uma operação de banco ao DB_TIMEOUT sem ignorar o
+// cancelamento ou um prazo menor já presente no contexto de quem chama.
+func comTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, dbTimeout)
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/shared/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const envioColunas = `id, proposta_id, para, cc, assunto, status, message_id, erro, documento_sha256, ator, data_criacao, data_envio`
+
+type EnvioRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewEnvioRepository(connection *pgxpool.Pool) EnvioRepository {
+	return EnvioRepository{
+		connection: connection,
+	}
+}
+
+func scanEnvio(row pgx.Row) (*model.EnvioEmail, error) {
+	var e model.EnvioEmail
+	err := row.Scan(
+		&e.Id,
+		&e.PropostaId,
+		&e.Para,
+		&e.Cc,
+		&e.Assunto,
+		&e.Status,
+		&e.MessageId,
+		&e.Erro,
+		&e.DocumentoSha256,
+		&e.Ator,
+		&e.DataCriacao,
+		&e.DataEnvio,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// RegistrarEnvio grava a tentativa como pendente antes de falar com o servidor
+// SMTP, para que um envio interrompido no meio ainda fique registrado.
+func (er *EnvioRepository) RegistrarEnvio(ctx context.Context, envio model.EnvioEmail) (*model.EnvioEmail, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	if envio.Cc == nil {
+		envio.Cc = []string{}
+	}
+	query := `INSERT INTO proposta_envios (proposta_id, para, cc, assunto, status, documento_sha256, ator)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING ` + envioColunas
+
+	e, err := scanEnvio(er.connection.QueryRow(ctx, query,
+		envio.PropostaId,
+		envio.Para,
+		envio.Cc,
+		envio.Assunto,
+		model.EnvioPendente,
+		envio.DocumentoSha256,
+		envio.Ator,
+	))
+	if err != nil {
+		logger.Error("Erro ao registrar envio da proposta", err, zap.String("proposta_id", envio.PropostaId.String()))
+		return nil, err
+	}
+	return e, nil
+}
+
+func (er *EnvioRepository) ConcluirEnvio(ctx context.Context, id uuid.UUID, messageID string) (*model.EnvioEmail, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE proposta_envios SET status = $1, message_id = $2, data_envio = now()
+        WHERE id = $3
+        RETURNING ` + envioColunas
+
+	e, err := scanEnvio(er.connection.QueryRow(ctx, query, model.EnvioEnviado, messageID, id))
+	if err != nil {
+		logger.Error("Erro ao concluir envio da proposta", err, zap.String("envio_id", id.String()))
+		return nil, err
+	}
+	return e, nil
+}
+
+func (er *EnvioRepository) FalharEnvio(ctx context.Context, id uuid.UUID, erro string) (*model.EnvioEmail, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE proposta_envios SET status = $1, erro = $2
+        WHERE id = $3
+        RETURNING ` + envioColunas
+
+	e, err := scanEnvio(er.connection.QueryRow(ctx, query, model.EnvioFalhou, erro, id))
+	if err != nil {
+		logger.Error("Erro ao registrar falha no envio da proposta", err, zap.String("envio_id", id.String()))
+		return nil, err
+	}
+	return e, nil
+}
+
+func (er *EnvioRepository) ListarEnvios(ctx context.Context, propostaID uuid.UUID) ([]model.EnvioEmail, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + envioColunas + ` FROM proposta_envios
+        WHERE proposta_id = $1
+        ORDER BY data_criacao DESC`
+
+	rows, err := er.connection.Query(ctx, query, propostaID)
+	if err != nil {
+		logger.Error("Erro ao listar envios da proposta", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	envios := []model.EnvioEmail{}
+	for rows.Next() {
+		e, err := scanEnvio(rows)
+		if err != nil {
+			logger.Error("Erro ao fazer scan do envio", err)
+			return nil, err
+		}
+		envios = append(envios, *e)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração dos envios", err)
+		return nil, err
+	}
+	return envios, nil
+}
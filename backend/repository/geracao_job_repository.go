@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"propulse/model"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const geracaoJobColunas = `id, proposta_id, status, tentativas, max_tentativas, ultimo_erro, proxima_execucao, data_criacao, last_update, finalizado_em`
+
+type GeracaoJobRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewGeracaoJobRepository(connection *pgxpool.Pool) GeracaoJobRepository {
+	return GeracaoJobRepository{
+		connection: connection,
+	}
+}
+
+func scanGeracaoJob(row pgx.Row) (*model.GeracaoJob, error) {
+	var j model.GeracaoJob
+	err := row.Scan(
+		&j.Id,
+		&j.PropostaId,
+		&j.Status,
+		&j.Tentativas,
+		&j.MaxTentativas,
+		&j.UltimoErro,
+		&j.ProximaExecucao,
+		&j.DataCriacao,
+		&j.LastUpdate,
+		&j.FinalizadoEm,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// inserirGeracaoJob enfileira um job de geração usando a transação de quem chama,
+// para que a proposta e o seu job sejam gravados juntos.
+func inserirGeracaoJob(ctx context.Context, tx pgx.Tx, propostaID uuid.UUID, maxTentativas int) (*model.GeracaoJob, error) {
+	query := `INSERT INTO geracao_jobs (proposta_id, status, max_tentativas)
+        VALUES ($1, $2, $3)
+        RETURNING ` + geracaoJobColunas
+
+	return scanGeracaoJob(tx.QueryRow(ctx, query, propostaID, model.JobPending, maxTentativas))
+}
+
+func (jr *GeracaoJobRepository) FindJobByID(ctx context.Context, id uuid.UUID) (*model.GeracaoJob, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + geracaoJobColunas + ` FROM geracao_jobs WHERE id = $1`
+
+	job, err := scanGeracaoJob(jr.connection.QueryRow(ctx, query, id))
+	if err != nil {
+		logger.Error("Erro ao buscar job de geração", err, zap.String("id", id.String()))
+		return nil, err
+	}
+	return job, nil
+}
+
+// FindUltimoJobByProposta retorna o job mais recente da proposta, ou nil, nil se ela não tiver jobs.
+func (jr *GeracaoJobRepository) FindUltimoJobByProposta(ctx context.Context, propostaID uuid.UUID) (*model.GeracaoJob, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + geracaoJobColunas + ` FROM geracao_jobs
+        WHERE proposta_id = $1
+        ORDER BY data_criacao DESC
+        LIMIT 1`
+
+	job, err := scanGeracaoJob(jr.connection.QueryRow(ctx, query, propostaID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Erro ao buscar último job da proposta", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	return job, nil
+}
+
+// ClaimProximoJob reserva o próximo job pronto para execução. Jobs em "running"
+// sem atualização há mais que lease (o worker renova last_update com
+// RenovarLease enquanto gera) são considerados abandonados por um worker
+// que caiu e voltam a ser elegíveis se ainda tiverem tentativas; os que já
+// esgotaram ficam para EncerrarJobsEsgotados. Retorna nil, nil quando a fila
+// está vazia.
+func (jr *GeracaoJobRepository) ClaimProximoJob(ctx context.Context, lease time.Duration) (*model.GeracaoJob, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE geracao_jobs
+        SET status = $1, tentativas = tentativas + 1, last_update = now()
+        WHERE id = (
+            SELECT id FROM geracao_jobs
+            WHERE (status = $2 AND proxima_execucao <= now())
+               OR (status = $1 AND last_update < $3 AND tentativas < max_tentativas)
+            ORDER BY proxima_execucao
+            FOR UPDATE SKIP LOCKED
+            LIMIT 1
+        )
+        RETURNING ` + geracaoJobColunas
+
+	job, err := scanGeracaoJob(jr.connection.QueryRow(
+		ctx,
+		query,
+		model.JobRunning,
+		model.JobPending,
+		time.Now().Add(-lease),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Erro ao reservar job de geração", err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// RenovarLease atualiza o last_update de um job em execução, indicando que o
+// worker continua vivo e que o job não deve ser reservado de novo.
+func (jr *GeracaoJobRepository) RenovarLease(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE geracao_jobs SET last_update = now() WHERE id = $1 AND status = $2`
+
+	_, err := jr.connection.Exec(ctx, query, id, model.JobRunning)
+	if err != nil {
+		logger.Error("Erro ao renovar lease do job de geração", err, zap.String("id", id.String()))
+		return err
+	}
+	return nil
+}
+
+func (jr *GeracaoJobRepository) MarcarSucesso(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE geracao_jobs
+        SET status = $1, ultimo_erro = NULL, last_update = now(), finalizado_em = now()
+        WHERE id = $2`
+
+	_, err := jr.connection.Exec(ctx, query, model.JobSucceeded, id)
+	if err != nil {
+		logger.Error("Erro ao marcar job como concluído", err, zap.String("id", id.String()))
+		return err
+	}
+	return nil
+}
+
+// MarcarFalha registra o erro da tentativa. Com proximaExecucao preenchida o job
+// volta para a fila; sem ela o job é encerrado como failed.
+func (jr *GeracaoJobRepository) MarcarFalha(ctx context.Context, id uuid.UUID, erro string, proximaExecucao *time.Time) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	var err error
+	if proximaExecucao != nil {
+		query := `UPDATE geracao_jobs
+            SET status = $1, ultimo_erro = $2, proxima_execucao = $3, last_update = now()
+            WHERE id = $4`
+		_, err = jr.connection.Exec(ctx, query, model.JobPending, erro, *proximaExecucao, id)
+	} else {
+		err = jr.encerrarComFalha(ctx, id, erro)
+	}
+	if err != nil {
+		logger.Error("Erro ao registrar falha do job", err, zap.String("id", id.String()))
+		return err
+	}
+	return nil
+}
+
+// encerrarComFalha encerra o job sem novas tentativas e publica o evento de
+// falha da geração na mesma transação.
+func (jr *GeracaoJobRepository) encerrarComFalha(ctx context.Context, id uuid.UUID, erro string) error {
+	tx, err := jr.connection.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE geracao_jobs
+        SET status = $1, ultimo_erro = $2, last_update = now(), finalizado_em = now()
+        WHERE id = $3
+        RETURNING ` + geracaoJobColunas
+
+	job, err := scanGeracaoJob(tx.QueryRow(ctx, query, model.JobFailed, erro, id))
+	if err != nil {
+		return err
+	}
+	if err := inserirEvento(ctx, tx, model.EventoPropostaGeracaoFalhou, job.PropostaId, job); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// EncerrarJobsEsgotados encerra como failed os jobs abandonados em "running"
+// (sem atualização há mais que lease) que já usaram todas as tentativas, com o
+// evento de falha da geração. Retorna os jobs encerrados.
+func (jr *GeracaoJobRepository) EncerrarJobsEsgotados(ctx context.Context, lease time.Duration, erro string) ([]model.GeracaoJob, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	jobs := []model.GeracaoJob{}
+	for {
+		job, err := jr.encerrarProximoEsgotado(ctx, lease, erro)
+		if err != nil {
+			logger.Error("Erro ao encerrar job de geração esgotado", err)
+			return jobs, err
+		}
+		if job == nil {
+			return jobs, nil
+		}
+		jobs = append(jobs, *job)
+	}
+}
+
+func (jr *GeracaoJobRepository) encerrarProximoEsgotado(ctx context.Context, lease time.Duration, erro string) (*model.GeracaoJob, error) {
+	tx, err := jr.connection.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE geracao_jobs
+        SET status = $1, ultimo_erro = $2, last_update = now(), finalizado_em = now()
+        WHERE id = (
+            SELECT id FROM geracao_jobs
+            WHERE status = $3 AND last_update < $4 AND tentativas >= max_tentativas
+            FOR UPDATE SKIP LOCKED
+            LIMIT 1
+        )
+        RETURNING ` + geracaoJobColunas
+
+	job, err := scanGeracaoJob(tx.QueryRow(ctx, query, model.JobFailed, erro, model.JobRunning, time.Now().Add(-lease)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := inserirEvento(ctx, tx, model.EventoPropostaGeracaoFalhou, job.PropostaId, job); err != nil {
+		return nil, err
+	}
+	return job, tx.Commit(ctx)
+}
+
+// LiberarJob devolve à fila um job interrompido pelo desligamento do worker,
+// sem consumir a tentativa em andamento.
+func (jr *GeracaoJobRepository) LiberarJob(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE geracao_jobs
+        SET status = $1, tentativas = GREATEST(tentativas - 1, 0), proxima_execucao = now(), last_update = now()
+        WHERE id = $2`
+
+	_, err := jr.connection.Exec(ctx, query, model.JobPending, id)
+	if err != nil {
+		logger.Error("Erro ao devolver job para a fila", err, zap.String("id", id.String()))
+		return err
+	}
+	return nil
+}
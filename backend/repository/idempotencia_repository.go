@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"propulse/model"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type IdempotenciaRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewIdempotenciaRepository(connection *pgxpool.Pool) IdempotenciaRepository {
+	return IdempotenciaRepository{
+		connection: connection,
+	}
+}
+
+// Reservar tenta tomar posse da chave. Chaves expiradas e reservas sem resposta
+// criadas há mais que reservaTTL são reaproveitadas. Quando a chave já pertence
+// a outra requisição válida, retorna reservado=false e o registro existente.
+func (ir *IdempotenciaRepository) Reservar(ctx context.Context, chave string, requestHash string, expiraEm time.Time, reservaTTL time.Duration) (bool, *model.RegistroIdempotencia, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO idempotency_keys (chave, request_hash, expira_em)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (chave) DO UPDATE
+            SET request_hash = EXCLUDED.request_hash,
+                status_code = NULL,
+                content_type = NULL,
+                resposta = NULL,
+                data_criacao = now(),
+                expira_em = EXCLUDED.expira_em
+            WHERE idempotency_keys.expira_em < now()
+               OR (idempotency_keys.status_code IS NULL AND idempotency_keys.data_criacao < $4)
+        RETURNING chave`
+
+	var reservada string
+	err := ir.connection.QueryRow(ctx, query, chave, requestHash, expiraEm, time.Now().Add(-reservaTTL)).Scan(&reservada)
+	if err == nil {
+		return true, nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error("Erro ao reservar chave de idempotência", err, zap.String("chave", chave))
+		return false, nil, err
+	}
+
+	query = `SELECT chave, request_hash, status_code, content_type, resposta, data_criacao, expira_em
+        FROM idempotency_keys WHERE chave = $1`
+
+	var r model.RegistroIdempotencia
+	err = ir.connection.QueryRow(ctx, query, chave).Scan(
+		&r.Chave,
+		&r.RequestHash,
+		&r.StatusCode,
+		&r.ContentType,
+		&r.Resposta,
+		&r.DataCriacao,
+		&r.ExpiraEm,
+	)
+	if err != nil {
+		logger.Error("Erro ao buscar chave de idempotência", err, zap.String("chave", chave))
+		return false, nil, err
+	}
+	return false, &r, nil
+}
+
+func (ir *IdempotenciaRepository) Concluir(ctx context.Context, chave string, statusCode int, contentType string, resposta []byte) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE idempotency_keys SET status_code = $1, content_type = $2, resposta = $3 WHERE chave = $4`
+
+	_, err := ir.connection.Exec(ctx, query, statusCode, contentType, resposta, chave)
+	if err != nil {
+		logger.Error("Erro ao gravar resposta da chave de idempotência", err, zap.String("chave", chave))
+		return err
+	}
+	return nil
+}
+
+func (ir *IdempotenciaRepository) Remover(ctx context.Context, chave string) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	_, err := ir.connection.Exec(ctx, `DELETE FROM idempotency_keys WHERE chave = $1`, chave)
+	if err != nil {
+		logger.Error("Erro ao remover chave de idempotência", err, zap.String("chave", chave))
+		return err
+	}
+	return nil
+}
+
+func (ir *IdempotenciaRepository) RemoverExpiradas(ctx context.Context) (int64, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tag, err := ir.connection.Exec(ctx, `DELETE FROM idempotency_keys WHERE expira_em < now()`)
+	if err != nil {
+		logger.Error("Erro ao remover chaves de idempotência expiradas", err)
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const linkColunas = `id, proposta_id, expira_em, max_visualizacoes, visualizacoes, revogado_em, data_criacao`
+
+// linkValido é a condição para qualquer uso do link: não revogado nem expirado.
+const linkValido = `revogado_em IS NULL AND expira_em > now()`
+
+// linkComVisualizacoes é linkValido com visualizações restantes, exigido só
+// de quem conta uma nova visualização.
+const linkComVisualizacoes = linkValido + `
+            AND (max_visualizacoes IS NULL OR visualizacoes < max_visualizacoes)`
+
+type LinkRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewLinkRepository(connection *pgxpool.Pool) LinkRepository {
+	return LinkRepository{
+		connection: connection,
+	}
+}
+
+func scanLink(row pgx.Row) (*model.LinkCompartilhamento, error) {
+	var l model.LinkCompartilhamento
+	err := row.Scan(
+		&l.Id,
+		&l.PropostaId,
+		&l.ExpiraEm,
+		&l.MaxVisualizacoes,
+		&l.Visualizacoes,
+		&l.RevogadoEm,
+		&l.DataCriacao,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (lr *LinkRepository) CriarLink(ctx context.Context, propostaID uuid.UUID, expiraEm time.Time, maxVisualizacoes *int) (*model.LinkCompartilhamento, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO proposta_links (proposta_id, expira_em, max_visualizacoes)
+        VALUES ($1, $2, $3)
+        RETURNING ` + linkColunas
+
+	l, err := scanLink(lr.connection.QueryRow(ctx, query, propostaID, expiraEm, maxVisualizacoes))
+	if err != nil {
+		logger.Error("Erro ao criar link de compartilhamento", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	return l, nil
+}
+
+func (lr *LinkRepository) ListarLinks(ctx context.Context, propostaID uuid.UUID) ([]model.LinkCompartilhamento, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + linkColunas + ` FROM proposta_links
+        WHERE proposta_id = $1
+        ORDER BY data_criacao DESC`
+
+	rows, err := lr.connection.Query(ctx, query, propostaID)
+	if err != nil {
+		logger.Error("Erro ao listar links da proposta", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []model.LinkCompartilhamento{}
+	for rows.Next() {
+		l, err := scanLink(rows)
+		if err != nil {
+			logger.Error("Erro ao fazer scan do link", err)
+			return nil, err
+		}
+		links = append(links, *l)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração dos links", err)
+		return nil, err
+	}
+	return links, nil
+}
+
+// RevogarLink marca o link como revogado. Revogar de novo mantém a data original.
+func (lr *LinkRepository) RevogarLink(ctx context.Context, propostaID uuid.UUID, linkID uuid.UUID) (*model.LinkCompartilhamento, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE proposta_links
+        SET revogado_em = COALESCE(revogado_em, now())
+        WHERE id = $1 AND proposta_id = $2
+        RETURNING ` + linkColunas
+
+	l, err := scanLink(lr.connection.QueryRow(ctx, query, linkID, propostaID))
+	if err != nil {
+		logger.Error("Erro ao revogar link", err, zap.String("link_id", linkID.String()))
+		return nil, err
+	}
+	return l, nil
+}
+
+// UsarLink retorna o link se ele não estiver revogado nem expirado. Com
+// contar=true a visualização é registrada no mesmo UPDATE que confere o limite,
+// então acessos simultâneos não ultrapassam max_visualizacoes. Sem contar, o
+// limite não se aplica: a página de aceite e a continuação de um download
+// continuam valendo depois que a última visualização foi usada.
+func (lr *LinkRepository) UsarLink(ctx context.Context, linkID uuid.UUID, contar bool) (*model.LinkCompartilhamento, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	l, err := scanLink(lr.connection.QueryRow(ctx, consultaUsarLink(contar), linkID))
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func consultaUsarLink(contar bool) string {
+	if contar {
+		return `UPDATE proposta_links
+        SET visualizacoes = visualizacoes + 1
+        WHERE id = $1 AND ` + linkComVisualizacoes + `
+        RETURNING ` + linkColunas
+	}
+	return `SELECT ` + linkColunas + ` FROM proposta_links WHERE id = $1 AND ` + linkValido
+}
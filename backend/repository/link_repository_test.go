@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConsultaUsarLink(t *testing.T) {
+	casos := []struct {
+		nome          string
+		contar        bool
+		comLimite     bool
+		incrementaUso bool
+	}{
+		{"nova visualização confere o limite e conta", true, true, true},
+		{"aceite e continuação de download não conferem o limite", false, false, false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			query := consultaUsarLink(c.contar)
+			if !strings.Contains(query, "revogado_em IS NULL AND expira_em > now()") {
+				t.Errorf("consulta sem revogação e expiração: %s", query)
+			}
+			if strings.Contains(query, "visualizacoes < max_visualizacoes") != c.comLimite {
+				t.Errorf("limite de visualizações na consulta = %v, esperado %v: %s", !c.comLimite, c.comLimite, query)
+			}
+			if strings.Contains(query, "visualizacoes = visualizacoes + 1") != c.incrementaUso {
+				t.Errorf("contagem na consulta = %v, esperado %v: %s", !c.incrementaUso, c.incrementaUso, query)
+			}
+		})
+	}
+}
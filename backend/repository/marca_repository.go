@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"propulse/model"
+	"propulse/shared/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const marcaColunas = `id, nome, cores_primarias, cores_secundarias, logo, fonte, rodape, contato, data_criacao, last_update`
+
+type MarcaRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewMarcaRepository(connection *pgxpool.Pool) MarcaRepository {
+	return MarcaRepository{
+		connection: connection,
+	}
+}
+
+func scanMarca(row pgx.Row) (*model.Marca, error) {
+	var m model.Marca
+	var logo, fonte, rodape *string
+	err := row.Scan(
+		&m.Id,
+		&m.Nome,
+		&m.CoresPrimarias,
+		&m.CoresSecundarias,
+		&logo,
+		&fonte,
+		&rodape,
+		&m.Contato,
+		&m.DataCriacao,
+		&m.LastUpdate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if logo != nil {
+		m.Logo = *logo
+	}
+	if fonte != nil {
+		m.Fonte = *fonte
+	}
+	if rodape != nil {
+		m.Rodape = *rodape
+	}
+	return &m, nil
+}
+
+// erroMarca traduz as violações de restrição da tabela marcas.
+func erroMarca(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return model.ErrMarcaDuplicada
+		case "23503":
+			return model.ErrMarcaEmUso
+		}
+	}
+	return err
+}
+
+func (mr *MarcaRepository) CriarMarca(ctx context.Context, marca model.Marca) (*model.Marca, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO marcas (nome, cores_primarias, cores_secundarias, logo, fonte, rodape, contato)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING ` + marcaColunas
+
+	m, err := scanMarca(mr.connection.QueryRow(ctx, query, marca.Nome, marca.CoresPrimarias, marca.CoresSecundarias,
+		nuloSeVazio(marca.Logo), nuloSeVazio(marca.Fonte), nuloSeVazio(marca.Rodape), marca.Contato))
+	if err != nil {
+		if err = erroMarca(err); !errors.Is(err, model.ErrMarcaDuplicada) {
+			logger.Error("Erro ao criar marca", err)
+		}
+		return nil, err
+	}
+	logger.Info("Marca criada", zap.String("id", m.Id.String()))
+	return m, nil
+}
+
+// ListarMarcas devolve as marcas em ordem alfabética.
+func (mr *MarcaRepository) ListarMarcas(ctx context.Context) ([]model.Marca, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	rows, err := mr.connection.Query(ctx, `SELECT `+marcaColunas+` FROM marcas ORDER BY lower(nome), id`)
+	if err != nil {
+		logger.Error("Erro ao listar marcas", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	marcas := []model.Marca{}
+	for rows.Next() {
+		m, err := scanMarca(rows)
+		if err != nil {
+			logger.Error("Erro ao fazer scan da marca", err)
+			return nil, err
+		}
+		marcas = append(marcas, *m)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração das marcas", err)
+		return nil, err
+	}
+	return marcas, nil
+}
+
+func (mr *MarcaRepository) FindMarca(ctx context.Context, id uuid.UUID) (*model.Marca, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	return scanMarca(mr.connection.QueryRow(ctx, `SELECT `+marcaColunas+` FROM marcas WHERE id = $1`, id))
+}
+
+// AtualizarMarca substitui os dados do kit. As propostas já criadas mantêm o
+// estilo com que foram geradas até serem regeradas.
+func (mr *MarcaRepository) AtualizarMarca(ctx context.Context, id uuid.UUID, marca model.Marca) (*model.Marca, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE marcas
+        SET nome = $1, cores_primarias = $2, cores_secundarias = $3, logo = $4, fonte = $5, rodape = $6, contato = $7, last_update = now()
+        WHERE id = $8
+        RETURNING ` + marcaColunas
+
+	m, err := scanMarca(mr.connection.QueryRow(ctx, query, marca.Nome, marca.CoresPrimarias, marca.CoresSecundarias,
+		nuloSeVazio(marca.Logo), nuloSeVazio(marca.Fonte), nuloSeVazio(marca.Rodape), marca.Contato, id))
+	if err != nil {
+		if err = erroMarca(err); !errors.Is(err, model.ErrMarcaDuplicada) && !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Erro ao atualizar marca", err, zap.String("id", id.String()))
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (mr *MarcaRepository) DeleteMarca(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tag, err := mr.connection.Exec(ctx, `DELETE FROM marcas WHERE id = $1`, id)
+	if err != nil {
+		if err = erroMarca(err); !errors.Is(err, model.ErrMarcaEmUso) {
+			logger.Error("Erro ao remover marca", err, zap.String("id", id.String()))
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"propulse/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// inserirItens grava os itens da proposta na ordem recebida.
+func inserirItens(ctx context.Context, tx pgx.Tx, propostaID uuid.UUID, itens []model.ItemProposta) error {
+	query := `INSERT INTO proposta_itens (proposta_id, posicao, descricao, quantidade, unidade, preco_unitario, desconto, imposto)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	for posicao, item := range itens {
+		_, err := tx.Exec(ctx, query, propostaID, posicao, item.Descricao, item.Quantidade, item.Unidade, item.PrecoUnitario, item.Desconto, item.Imposto)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// substituirItens troca todos os itens da proposta pelos informados.
+func substituirItens(ctx context.Context, tx pgx.Tx, propostaID uuid.UUID, itens []model.ItemProposta) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM proposta_itens WHERE proposta_id = $1`, propostaID); err != nil {
+		return err
+	}
+	return inserirItens(ctx, tx, propostaID, itens)
+}
+
+// carregarItens lê os itens da proposta e calcula os totais. Aceita o pool ou
+// uma transação.
+func carregarItens(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+}, p *model.Proposta) error {
+	query := `SELECT descricao, quantidade, unidade, preco_unitario, desconto, imposto
+        FROM proposta_itens
+        WHERE proposta_id = $1
+        ORDER BY posicao`
+
+	rows, err := q.Query(ctx, query, p.Id)
+	if err != nil {
+		return err
+	}
+	itens, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.ItemProposta, error) {
+		var item model.ItemProposta
+		err := row.Scan(&item.Descricao, &item.Quantidade, &item.Unidade, &item.PrecoUnitario, &item.Desconto, &item.Imposto)
+		return item, err
+	})
+	if err != nil {
+		return err
+	}
+	p.Itens = itens
+	p.CalcularTotais()
+	return nil
+}
@@ -11,9 +11,16 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const propostaColunas = `id, titulo, nome_empresa, nome_cliente, prompt, cores, logo, logo_cliente, status, arquivo_final, data_criacao, last_update, html, arquivo_sha256, arquivo_tamanho, valido_ate, cliente_id, marca_id, marca`
+
+// propostaColunasLista é propostaColunas sem ler o HTML, que pode ser grande;
+// a coluna vazia mantém a ordem esperada por scanProposta.
+const propostaColunasLista = `id, titulo, nome_empresa, nome_cliente, prompt, cores, logo, logo_cliente, status, arquivo_final, data_criacao, last_update, '' AS html, arquivo_sha256, arquivo_tamanho, valido_ate, cliente_id, marca_id, marca`
+
 type PropostaRepository struct {
 	connection *pgxpool.Pool
 }
@@ -24,20 +31,63 @@ func NewPropostaRepository(connection *pgxpool.Pool) PropostaRepository {
 	}
 }
 
-func (pr *PropostaRepository) CriarProposta(proposta model.Proposta) (*model.Proposta, error) {
+// scanProposta lê as colunas de propostaColunas e, em seguida, as colunas
+// extras da consulta nos destinos informados.
+func scanProposta(row pgx.Row, extras ...any) (*model.Proposta, error) {
+	var p model.Proposta
+	destinos := []any{
+		&p.Id,
+		&p.Titulo,
+		&p.NomeEmpresa,
+		&p.NomeCliente,
+		&p.Prompt,
+		&p.Cores,
+		&p.Logo,
+		&p.LogoCliente,
+		&p.Status,
+		&p.ArquivoFinal,
+		&p.DataCriacao,
+		&p.LastUpdate,
+		&p.Html,
+		&p.ArquivoSha256,
+		&p.ArquivoTamanho,
+		&p.ValidoAte,
+		&p.ClienteId,
+		&p.MarcaId,
+		&p.Marca,
+	}
+	if err := row.Scan(append(destinos, extras...)...); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CriarProposta grava a proposta e enfileira o seu job de geração na mesma
+// transação, garantindo que nenhuma proposta fique sem job.
+func (pr *PropostaRepository) CriarProposta(ctx context.Context, proposta model.Proposta, maxTentativas int) (*model.Proposta, *model.GeracaoJob, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
 	currentTime := time.Now()
 	proposta.DataCriacao = currentTime
 	proposta.LastUpdate = currentTime
 	query := ` INSERT INTO propostas ( id, titulo, nome_empresa, nome_cliente, prompt, cores,
-	   logo, logo_cliente, html, status, arquivo_final, data_criacao, last_update
+	   logo, logo_cliente, html, status, arquivo_final, data_criacao, last_update, valido_ate, cliente_id, marca_id, marca
         ) VALUES (
             $1, $2, $3, $4, $5, $6,
-            $7, $8, $9, $10, $11, $12, $13
+            $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
         )
-        RETURNING id, titulo, nome_empresa, nome_cliente, prompt, cores, logo, logo_cliente, status, arquivo_final, data_criacao, last_update, html;`
+        RETURNING ` + propostaColunas
 
-	row := pr.connection.QueryRow(
-		context.Background(),
+	tx, err := pr.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de criação da proposta", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(
+		ctx,
 		query,
 		proposta.Id,
 		proposta.Titulo,
@@ -52,33 +102,83 @@ func (pr *PropostaRepository) CriarProposta(proposta model.Proposta) (*model.Pro
 		proposta.ArquivoFinal,
 		proposta.DataCriacao,
 		proposta.LastUpdate,
+		proposta.ValidoAte,
+		proposta.ClienteId,
+		proposta.MarcaId,
+		proposta.Marca,
 	)
 
-	var p model.Proposta
-	err := row.Scan(
-		&p.Id,
-		&p.Titulo,
-		&p.NomeEmpresa,
-		&p.NomeCliente,
-		&p.Prompt,
-		&p.Cores,
-		&p.Logo,
-		&p.LogoCliente,
-		&p.Status,
-		&p.ArquivoFinal,
-		&p.DataCriacao,
-		&p.LastUpdate,
-		&p.Html,
-	)
+	p, err := scanProposta(row)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	logger.Info("Proposta criada com sucesso!")
 
-	return &p, nil
+	if err := inserirItens(ctx, tx, p.Id, proposta.Itens); err != nil {
+		logger.Error("Erro ao gravar itens da proposta", err)
+		return nil, nil, err
+	}
+	p.Itens = proposta.Itens
+	p.CalcularTotais()
+
+	if err := inserirTransicao(ctx, tx, p.Id, nil, p.Status, model.AtorAPI, nil); err != nil {
+		logger.Error("Erro ao registrar status inicial da proposta", err)
+		return nil, nil, err
+	}
+	if err := inserirEventoProposta(ctx, tx, model.EventoPropostaCriada, p); err != nil {
+		logger.Error("Erro ao publicar evento de criação da proposta", err)
+		return nil, nil, err
+	}
+
+	job, err := inserirGeracaoJob(ctx, tx, p.Id, maxTentativas)
+	if err != nil {
+		logger.Error("Erro ao enfileirar job de geração", err)
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar transação de criação da proposta", err)
+		return nil, nil, err
+	}
+	logger.Info("Proposta criada com sucesso!", zap.String("id", p.Id.String()), zap.String("job_id", job.Id.String()))
+
+	return p, job, nil
 }
 
-func (pr *PropostaRepository) UpdateProposta(id uuid.UUID, update model.PropostaUpdate) (*model.Proposta, error) {
+// UpdateProposta aplica a atualização parcial. Quando origemVersao é informada,
+// um snapshot do novo conteúdo é gravado em proposta_versoes na mesma transação.
+// Uma mudança de status é validada pela máquina de estados com a linha
+// bloqueada (FOR UPDATE), então duas transições concorrentes não passam ambas,
+// e é registrada em proposta_status_historico.
+func (pr *PropostaRepository) UpdateProposta(ctx context.Context, id uuid.UUID, update model.PropostaUpdate, origemVersao string) (*model.Proposta, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tx, err := pr.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de atualização da proposta", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var statusAnterior string
+	if update.Status != nil {
+		atual, err := scanProposta(tx.QueryRow(ctx, `SELECT `+propostaColunas+` FROM propostas WHERE id = $1 FOR UPDATE`, id))
+		if err != nil {
+			return nil, err
+		}
+		statusAnterior = atual.Status
+		// Uma nova validade enviada junto vale para a transição, o que permite
+		// reenviar uma proposta expirada no mesmo PATCH.
+		if update.ValidoAte != nil {
+			atual.ValidoAte = update.ValidoAte
+		}
+		if *update.Status == atual.Status {
+			update.Status = nil
+		} else if err := model.ValidarTransicao(atual, *update.Status); err != nil {
+			return nil, err
+		}
+	}
+
 	setParts := []string{}
 	args := []any{}
 	argIndex := 1
@@ -93,9 +193,15 @@ func (pr *PropostaRepository) UpdateProposta(id uuid.UUID, update model.Proposta
 		args = append(args, *update.Status)
 		argIndex++
 	}
-	if update.ArquivoFinal != nil {
+	if update.Artefato != nil {
 		setParts = append(setParts, fmt.Sprintf("arquivo_final = $%d", argIndex))
-		args = append(args, *update.ArquivoFinal)
+		args = append(args, update.Artefato.Chave)
+		argIndex++
+		setParts = append(setParts, fmt.Sprintf("arquivo_sha256 = $%d", argIndex))
+		args = append(args, update.Artefato.Sha256)
+		argIndex++
+		setParts = append(setParts, fmt.Sprintf("arquivo_tamanho = $%d", argIndex))
+		args = append(args, update.Artefato.Tamanho)
 		argIndex++
 	}
 	if update.Html != nil {
@@ -103,6 +209,11 @@ func (pr *PropostaRepository) UpdateProposta(id uuid.UUID, update model.Proposta
 		args = append(args, *update.Html)
 		argIndex++
 	}
+	if update.ValidoAte != nil {
+		setParts = append(setParts, fmt.Sprintf("valido_ate = $%d", argIndex))
+		args = append(args, *update.ValidoAte)
+		argIndex++
+	}
 
 	setParts = append(setParts, fmt.Sprintf("last_update = $%d", argIndex))
 	args = append(args, time.Now())
@@ -111,108 +222,427 @@ func (pr *PropostaRepository) UpdateProposta(id uuid.UUID, update model.Proposta
 	args = append(args, id)
 
 	query := fmt.Sprintf(
-		"UPDATE propostas SET %s WHERE id = $%d RETURNING id, titulo, nome_empresa, nome_cliente, prompt, cores, logo, logo_cliente, status, arquivo_final, data_criacao, last_update, html",
+		"UPDATE propostas SET %s WHERE id = $%d RETURNING %s",
 		strings.Join(setParts, ", "),
 		argIndex,
+		propostaColunas,
 	)
 
-	row := pr.connection.QueryRow(context.Background(), query, args...)
-
-	var p model.Proposta
-	err := row.Scan(&p.Id, &p.Titulo, &p.NomeEmpresa, &p.NomeCliente,
-		&p.Prompt, &p.Cores, &p.Logo, &p.LogoCliente,
-		&p.Status, &p.ArquivoFinal, &p.DataCriacao, &p.LastUpdate, &p.Html)
+	p, err := scanProposta(tx.QueryRow(ctx, query, args...))
 	if err != nil {
 		return nil, err
 	}
+	if err := carregarItens(ctx, tx, p); err != nil {
+		logger.Error("Erro ao carregar itens da proposta", err, zap.String("id", id.String()))
+		return nil, err
+	}
 
-	return &p, nil
+	if update.Status != nil {
+		ator := update.Ator
+		if ator == "" {
+			ator = model.AtorAPI
+		}
+		if err := inserirTransicao(ctx, tx, p.Id, &statusAnterior, p.Status, ator, update.Motivo); err != nil {
+			logger.Error("Erro ao registrar transição de status", err, zap.String("id", id.String()))
+			return nil, err
+		}
+		if err := inserirEventoStatus(ctx, tx, p); err != nil {
+			logger.Error("Erro ao publicar evento de status", err, zap.String("id", id.String()))
+			return nil, err
+		}
+	}
+
+	if update.Artefato != nil {
+		if _, err := inserirArtefato(ctx, tx, p.Id, *update.Artefato); err != nil {
+			logger.Error("Erro ao registrar artefato da proposta", err, zap.String("id", id.String()))
+			return nil, err
+		}
+	}
+
+	if origemVersao != "" {
+		if _, err := inserirVersao(ctx, tx, p, origemVersao, nil); err != nil {
+			logger.Error("Erro ao registrar versão da proposta", err, zap.String("id", id.String()))
+			return nil, err
+		}
+	}
+	if origemVersao == model.VersaoGeracao {
+		if err := inserirEventoProposta(ctx, tx, model.EventoPropostaGerada, p); err != nil {
+			logger.Error("Erro ao publicar evento de geração", err, zap.String("id", id.String()))
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar atualização da proposta", err)
+		return nil, err
+	}
+
+	return p, nil
 }
 
-func (pr *PropostaRepository) FindByID(id uuid.UUID) (*model.Proposta, error) {
-	query := `SELECT id, titulo, nome_empresa, nome_cliente, prompt, cores, logo, logo_cliente, status, arquivo_final, data_criacao, last_update, html FROM propostas WHERE id = $1`
+func (pr *PropostaRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Proposta, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
 
-	var p model.Proposta
+	query := `SELECT ` + propostaColunas + ` FROM propostas WHERE id = $1`
 
-	err := pr.connection.QueryRow(context.Background(), query, id).Scan(
-		&p.Id,
-		&p.Titulo,
-		&p.NomeEmpresa,
-		&p.NomeCliente,
-		&p.Prompt,
-		&p.Cores,
-		&p.Logo,
-		&p.LogoCliente,
-		&p.Status,
-		&p.ArquivoFinal,
-		&p.DataCriacao,
-		&p.LastUpdate,
-		&p.Html,
-	)
+	p, err := scanProposta(pr.connection.QueryRow(ctx, query, id))
 	if err != nil {
 		logger.Error("Erro ao fazer scan da proposta", err)
-		return &p, err
+		return &model.Proposta{}, err
 	}
-	return &p, nil
+	if err := carregarItens(ctx, pr.connection, p); err != nil {
+		logger.Error("Erro ao carregar itens da proposta", err, zap.String("id", id.String()))
+		return &model.Proposta{}, err
+	}
+	return p, nil
+}
+
+// colunasOrdenacao mapeia os campos aceitos em sort para as colunas.
+var colunasOrdenacao = map[string]string{
+	"dataCriacao": "data_criacao",
+	"lastUpdate":  "last_update",
+	"titulo":      "titulo",
+}
+
+// filtroSQL monta o WHERE da listagem. Cada "$?" da condição recebe, em
+// ordem, o próximo parâmetro numerado ($1, $2, ...).
+type filtroSQL struct {
+	condicoes []string
+	args      []any
+}
+
+func (f *filtroSQL) adicionar(condicao string, valores ...any) {
+	for _, valor := range valores {
+		f.args = append(f.args, valor)
+		condicao = strings.Replace(condicao, "$?", fmt.Sprintf("$%d", len(f.args)), 1)
+	}
+	f.condicoes = append(f.condicoes, condicao)
 }
 
-func (pr *PropostaRepository) GetAllPropostas() (*[]model.Proposta, error) {
-	query := `SELECT id, titulo, nome_empresa, nome_cliente, prompt, cores, logo, logo_cliente, status, arquivo_final, data_criacao, last_update, html FROM propostas`
+func (f *filtroSQL) where() string {
+	if len(f.condicoes) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.condicoes, " AND ")
+}
+
+// escaparLike protege %, _ e \ do termo usado em ILIKE.
+func escaparLike(termo string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(termo)
+}
 
-	rows, err := pr.connection.Query(context.Background(), query)
+func filtrosListagem(filtro model.FiltroPropostas, criacao model.IntervaloDatas, atualizacao model.IntervaloDatas) *filtroSQL {
+	f := &filtroSQL{}
+	if len(filtro.Status) > 0 {
+		f.adicionar("status = ANY($?)", filtro.Status)
+	}
+	if filtro.NomeEmpresa != "" {
+		f.adicionar("nome_empresa ILIKE '%' || $? || '%'", escaparLike(filtro.NomeEmpresa))
+	}
+	if filtro.ClienteId != "" {
+		f.adicionar("cliente_id = $?", filtro.ClienteId)
+	}
+	if criacao.De != nil {
+		f.adicionar("data_criacao >= $?", *criacao.De)
+	}
+	if criacao.Ate != nil {
+		f.adicionar("data_criacao <= $?", *criacao.Ate)
+	}
+	if atualizacao.De != nil {
+		f.adicionar("last_update >= $?", *atualizacao.De)
+	}
+	if atualizacao.Ate != nil {
+		f.adicionar("last_update <= $?", *atualizacao.Ate)
+	}
+	return f
+}
+
+// ordenacaoListagem devolve a coluna e a direção do sort, e o operador que
+// avança o cursor nessa direção.
+func ordenacaoListagem(filtro model.FiltroPropostas) (coluna string, direcao string, comparacao string) {
+	campo, decrescente := filtro.CampoOrdenacao()
+	if decrescente {
+		return colunasOrdenacao[campo], "DESC", "<"
+	}
+	return colunasOrdenacao[campo], "ASC", ">"
+}
+
+// consultaPagina completa os filtros com a posição do cursor e monta a
+// consulta da página. O id entra na ordenação e na comparação do cursor para
+// desempatar propostas com o mesmo valor de ordenação.
+func consultaPagina(filtro model.FiltroPropostas, f *filtroSQL, cursor *model.CursorPropostas) (string, []any) {
+	coluna, direcao, comparacao := ordenacaoListagem(filtro)
+	if cursor != nil {
+		var valor any = cursor.Valor
+		if coluna != "titulo" {
+			valor, _ = time.Parse(time.RFC3339Nano, cursor.Valor)
+		}
+		f.adicionar(fmt.Sprintf("(%s, id) %s ($?, $?)", coluna, comparacao), valor, cursor.Id)
+	}
+
+	query := `SELECT ` + propostaColunasLista + ` FROM propostas` + f.where() +
+		fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT %d`, coluna, direcao, direcao, filtro.Limit+1)
+	if cursor == nil && filtro.Offset > 0 {
+		query += fmt.Sprintf(` OFFSET %d`, filtro.Offset)
+	}
+	return query, f.args
+}
+
+// ListarPropostas devolve uma página de propostas sem o HTML e o total de
+// propostas que atendem aos filtros. Com cursor, a página começa depois da
+// proposta marcada por ele (keyset) e o offset é ignorado. São lidas até
+// filtro.Limit+1 linhas para o chamador saber se há próxima página.
+func (pr *PropostaRepository) ListarPropostas(ctx context.Context, filtro model.FiltroPropostas, criacao model.IntervaloDatas, atualizacao model.IntervaloDatas, cursor *model.CursorPropostas) ([]model.Proposta, int, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	f := filtrosListagem(filtro, criacao, atualizacao)
+	var total int
+	if err := pr.connection.QueryRow(ctx, `SELECT count(*) FROM propostas`+f.where(), f.args...).Scan(&total); err != nil {
+		logger.Error("Erro ao contar propostas", err)
+		return nil, 0, err
+	}
+
+	query, args := consultaPagina(filtro, f, cursor)
+	rows, err := pr.connection.Query(ctx, query, args...)
 	if err != nil {
 		logger.Error("Erro ao buscar propostas", err)
-		return &[]model.Proposta{}, err
+		return nil, 0, err
 	}
-
 	defer rows.Close()
 
-	var propostas []model.Proposta
+	propostas := []model.Proposta{}
+	for rows.Next() {
+		p, err := scanProposta(rows)
+		if err != nil {
+			logger.Error("Erro ao fazer scan da proposta", err)
+			return nil, 0, err
+		}
+		propostas = append(propostas, *p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Erro durante iteração das linhas", err)
+		return nil, 0, err
+	}
+	return propostas, total, nil
+}
+
+// PercorrerPropostas chama fn para cada proposta (sem o HTML) que atende aos
+// filtros, na ordem do sort, lendo as linhas conforme são consumidas em vez
+// de carregar o resultado inteiro. Usa EXPORT_TIMEOUT no lugar de DB_TIMEOUT,
+// já que fn pode estar escrevendo em uma conexão lenta.
+func (pr *PropostaRepository) PercorrerPropostas(ctx context.Context, filtro model.FiltroPropostas, criacao model.IntervaloDatas, atualizacao model.IntervaloDatas, fn func(*model.Proposta) error) error {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	f := filtrosListagem(filtro, criacao, atualizacao)
+	coluna, direcao, _ := ordenacaoListagem(filtro)
+	query := `SELECT ` + propostaColunasLista + ` FROM propostas` + f.where() +
+		fmt.Sprintf(` ORDER BY %s %s, id %s`, coluna, direcao, direcao)
+
+	rows, err := pr.connection.Query(ctx, query, f.args...)
+	if err != nil {
+		logger.Error("Erro ao buscar propostas para exportação", err)
+		return err
+	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var p model.Proposta
-
-		err := rows.Scan(
-			&p.Id,
-			&p.Titulo,
-			&p.NomeEmpresa,
-			&p.NomeCliente,
-			&p.Prompt,
-			&p.Cores,
-			&p.Logo,
-			&p.LogoCliente,
-			&p.Status,
-			&p.ArquivoFinal,
-			&p.DataCriacao,
-			&p.LastUpdate,
-			&p.Html,
-		)
+		p, err := scanProposta(rows)
 		if err != nil {
 			logger.Error("Erro ao fazer scan da proposta", err)
-			return &[]model.Proposta{}, err
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
 		}
-		propostas = append(propostas, p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Erro durante iteração das linhas", err)
+		return err
+	}
+	return nil
+}
+
+// BuscarPropostas faz a busca textual em português sobre a coluna busca,
+// mantida por trigger, ordenando pela relevância. O trecho destacado só é
+// calculado para as propostas da página.
+func (pr *PropostaRepository) BuscarPropostas(ctx context.Context, q string, limit int, offset int) ([]model.ResultadoBusca, int, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	var total int
+	err := pr.connection.QueryRow(ctx,
+		`SELECT count(*) FROM propostas WHERE busca @@ websearch_to_tsquery('portuguese', $1)`, q).Scan(&total)
+	if err != nil {
+		logger.Error("Erro ao contar resultados da busca", err)
+		return nil, 0, err
 	}
 
+	query := `SELECT ` + propostaColunasLista + `, rank,
+            ts_headline('portuguese', titulo || ' — ' || prompt || ' ' || proposta_texto_html(html), consulta,
+                'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=25, MinWords=8, FragmentDelimiter=" … "')
+        FROM (
+            SELECT p.*, consulta, ts_rank(p.busca, consulta) AS rank
+            FROM propostas p, websearch_to_tsquery('portuguese', $1) consulta
+            WHERE p.busca @@ consulta
+            ORDER BY rank DESC, p.data_criacao DESC, p.id
+            LIMIT $2 OFFSET $3
+        ) encontradas
+        ORDER BY rank DESC, data_criacao DESC, id`
+
+	rows, err := pr.connection.Query(ctx, query, q, limit, offset)
+	if err != nil {
+		logger.Error("Erro ao buscar propostas", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	resultados := []model.ResultadoBusca{}
+	for rows.Next() {
+		var r model.ResultadoBusca
+		p, err := scanProposta(rows, &r.Rank, &r.Trecho)
+		if err != nil {
+			logger.Error("Erro ao fazer scan do resultado da busca", err)
+			return nil, 0, err
+		}
+		r.Proposta = *p
+		resultados = append(resultados, r)
+	}
 	if err = rows.Err(); err != nil {
 		logger.Error("Erro durante iteração das linhas", err)
-		return &[]model.Proposta{}, err
+		return nil, 0, err
 	}
-	return &propostas, nil
+	return resultados, total, nil
 }
 
-func (pr *PropostaRepository) DeleteProposta(id uuid.UUID) error {
-	query := `DELETE FROM propostas WHERE id = $1`
+// ExpirarVencidas move para expirado até limite propostas enviadas cuja
+// validade terminou antes de agora, registrando a transição com o ator sistema.
+// Linhas bloqueadas por outra transação, como um aceite em andamento, ficam
+// para a próxima varredura.
+func (pr *PropostaRepository) ExpirarVencidas(ctx context.Context, agora time.Time, limite int) ([]uuid.UUID, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tx, err := pr.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de expiração", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE propostas SET status = $1, last_update = $2
+        WHERE id IN (
+            SELECT id FROM propostas
+            WHERE status = $3 AND valido_ate <= $2
+            ORDER BY valido_ate
+            LIMIT $4
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + propostaColunas
+
+	rows, err := tx.Query(ctx, query, model.StatusExpirado, agora, model.StatusEnviado, limite)
+	if err != nil {
+		logger.Error("Erro ao expirar propostas vencidas", err)
+		return nil, err
+	}
+	var expiradas []*model.Proposta
+	for rows.Next() {
+		p, err := scanProposta(rows)
+		if err != nil {
+			rows.Close()
+			logger.Error("Erro ao ler propostas expiradas", err)
+			return nil, err
+		}
+		expiradas = append(expiradas, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro ao ler propostas expiradas", err)
+		return nil, err
+	}
+
+	de := model.StatusEnviado
+	motivo := "validade da proposta terminou"
+	ids := make([]uuid.UUID, 0, len(expiradas))
+	for _, p := range expiradas {
+		if err := inserirTransicao(ctx, tx, p.Id, &de, model.StatusExpirado, model.AtorSistema, &motivo); err != nil {
+			logger.Error("Erro ao registrar expiração no histórico", err, zap.String("id", p.Id.String()))
+			return nil, err
+		}
+		if err := inserirEventoStatus(ctx, tx, p); err != nil {
+			logger.Error("Erro ao publicar evento de expiração", err, zap.String("id", p.Id.String()))
+			return nil, err
+		}
+		ids = append(ids, p.Id)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar expiração das propostas", err)
+		return nil, err
+	}
+	return ids, nil
+}
+
+// DeleteProposta apaga a proposta (versões, artefatos e jobs caem em cascata) e
+// devolve as chaves de storage que ela gravou: as registradas em
+// proposta_artefatos com o prefixo da própria proposta. arquivo_final não é
+// usado, para que uma chave alheia nunca seja removida. Os objetos só devem
+// ser removidos do storage depois do commit; o que sobrar é recolhido pelo
+// coletor de órfãos.
+func (pr *PropostaRepository) DeleteProposta(ctx context.Context, id uuid.UUID) ([]string, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tx, err := pr.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de exclusão da proposta", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := scanProposta(tx.QueryRow(ctx, `SELECT `+propostaColunas+` FROM propostas WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := inserirEventoProposta(ctx, tx, model.EventoPropostaExcluida, p); err != nil {
+		logger.Error("Erro ao publicar evento de exclusão da proposta", err)
+		return nil, err
+	}
+
+	query := `SELECT DISTINCT chave FROM proposta_artefatos
+        WHERE proposta_id = $1 AND starts_with(chave, $2)`
 
-	_, err := pr.connection.Exec(context.Background(), query, id)
+	rows, err := tx.Query(ctx, query, id, model.PrefixoChavePDF(id))
 	if err != nil {
+		logger.Error("Erro ao buscar artefatos da proposta para exclusão", err)
+		return nil, err
+	}
+	chaves, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		logger.Error("Erro ao ler artefatos da proposta para exclusão", err)
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM propostas WHERE id = $1`, id); err != nil {
 		logger.Error("Erro ao realizar a exclusão da proposta", err)
-		return err
+		return nil, err
 	}
-	return nil
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar exclusão da proposta", err)
+		return nil, err
+	}
+	return chaves, nil
 }
 
-func (pr *PropostaRepository) UpdateForRegerar(id uuid.UUID, input model.RegerarProposta) (*model.Proposta, error) {
+// UpdateForRegerar grava os novos dados de entrada junto com o HTML e o PDF já
+// gerados em um único UPDATE, para que uma regeneração cancelada no meio do
+// caminho não deixe a proposta com entradas novas e conteúdo antigo.
+func (pr *PropostaRepository) UpdateForRegerar(ctx context.Context, id uuid.UUID, input model.RegerarProposta, html string, artefato model.ArtefatoPDF) (*model.Proposta, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -245,6 +675,40 @@ func (pr *PropostaRepository) UpdateForRegerar(id uuid.UUID, input model.Regerar
 		argIndex++
 	}
 
+	if input.ClienteId != nil {
+		setParts = append(setParts, fmt.Sprintf("cliente_id = $%d", argIndex))
+		args = append(args, *input.ClienteId)
+		argIndex++
+	}
+
+	if input.MarcaId != nil {
+		setParts = append(setParts, fmt.Sprintf("marca_id = $%d", argIndex))
+		args = append(args, *input.MarcaId)
+		argIndex++
+	}
+
+	if input.Marca != nil {
+		setParts = append(setParts, fmt.Sprintf("marca = $%d", argIndex))
+		args = append(args, *input.Marca)
+		argIndex++
+	}
+
+	setParts = append(setParts, fmt.Sprintf("html = $%d", argIndex))
+	args = append(args, html)
+	argIndex++
+
+	setParts = append(setParts, fmt.Sprintf("arquivo_final = $%d", argIndex))
+	args = append(args, artefato.Chave)
+	argIndex++
+
+	setParts = append(setParts, fmt.Sprintf("arquivo_sha256 = $%d", argIndex))
+	args = append(args, artefato.Sha256)
+	argIndex++
+
+	setParts = append(setParts, fmt.Sprintf("arquivo_tamanho = $%d", argIndex))
+	args = append(args, artefato.Tamanho)
+	argIndex++
+
 	now := time.Now()
 	setParts = append(setParts, fmt.Sprintf("last_update = $%d", argIndex))
 	args = append(args, now)
@@ -253,37 +717,73 @@ func (pr *PropostaRepository) UpdateForRegerar(id uuid.UUID, input model.Regerar
 	args = append(args, id)
 
 	query := fmt.Sprintf(
-		"UPDATE propostas SET %s WHERE id = $%d RETURNING id, titulo, nome_empresa, nome_cliente, prompt, cores, logo, logo_cliente, status, arquivo_final, data_criacao, last_update, html",
+		"UPDATE propostas SET %s WHERE id = $%d RETURNING %s",
 		strings.Join(setParts, ", "),
 		argIndex,
+		propostaColunas,
 	)
 
 	logger.Info("Executando UPDATE para regeneração", zap.String("query", query), zap.Int("args_count", len(args)))
 
-	row := pr.connection.QueryRow(context.Background(), query, args...)
+	tx, err := pr.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de regeneração", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
 
-	var p model.Proposta
-	err := row.Scan(
-		&p.Id,
-		&p.Titulo,
-		&p.NomeEmpresa,
-		&p.NomeCliente,
-		&p.Prompt,
-		&p.Cores,
-		&p.Logo,
-		&p.LogoCliente,
-		&p.Status,
-		&p.ArquivoFinal,
-		&p.DataCriacao,
-		&p.LastUpdate,
-		&p.Html,
-	)
+	// O status é conferido de novo com a linha travada: o cliente pode ter
+	// decidido enquanto a IA gerava o novo conteúdo.
+	if err := travarConteudoAlteravel(ctx, tx, id); err != nil {
+		return nil, err
+	}
 
+	p, err := scanProposta(tx.QueryRow(ctx, query, args...))
 	if err != nil {
 		logger.Error("Erro ao executar UPDATE para regeneração", err, zap.String("id", id.String()))
 		return nil, fmt.Errorf("falha ao atualizar proposta para regeneração: %w", err)
 	}
 
+	if input.Itens != nil {
+		if err := substituirItens(ctx, tx, p.Id, *input.Itens); err != nil {
+			logger.Error("Erro ao gravar itens da regeneração", err, zap.String("id", id.String()))
+			return nil, err
+		}
+	}
+	if err := carregarItens(ctx, tx, p); err != nil {
+		logger.Error("Erro ao carregar itens da proposta", err, zap.String("id", id.String()))
+		return nil, err
+	}
+
+	if _, err := inserirArtefato(ctx, tx, p.Id, artefato); err != nil {
+		logger.Error("Erro ao registrar artefato da regeneração", err, zap.String("id", id.String()))
+		return nil, err
+	}
+
+	if _, err := inserirVersao(ctx, tx, p, model.VersaoRegeneracao, nil); err != nil {
+		logger.Error("Erro ao registrar versão da regeneração", err, zap.String("id", id.String()))
+		return nil, err
+	}
+	if err := inserirEventoProposta(ctx, tx, model.EventoPropostaGerada, p); err != nil {
+		logger.Error("Erro ao publicar evento da regeneração", err, zap.String("id", id.String()))
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar regeneração da proposta", err, zap.String("id", id.String()))
+		return nil, err
+	}
+
 	logger.Info("Proposta atualizada com sucesso para regeneração", zap.String("id", p.Id.String()))
-	return &p, nil
+	return p, nil
+}
+
+// travarConteudoAlteravel bloqueia a linha da proposta até o fim da transação e
+// confere se o status ainda permite substituir o conteúdo.
+func travarConteudoAlteravel(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM propostas WHERE id = $1 FOR UPDATE`, id).Scan(&status); err != nil {
+		return err
+	}
+	return model.ValidarAlteracaoConteudo(status)
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"propulse/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestConsultaPaginaDesempataPorId(t *testing.T) {
+	id := uuid.New()
+	casos := []struct {
+		sort       string
+		valor      string
+		comparacao string
+		ordem      string
+		valorArg   any
+	}{
+		{"-dataCriacao", "2025-03-10T14:05:06.123456Z", "(data_criacao, id) < ($1, $2)", "ORDER BY data_criacao DESC, id DESC", time.Date(2025, 3, 10, 14, 5, 6, 123456000, time.UTC)},
+		{"dataCriacao", "2025-03-10T14:05:06.123456Z", "(data_criacao, id) > ($1, $2)", "ORDER BY data_criacao ASC, id ASC", time.Date(2025, 3, 10, 14, 5, 6, 123456000, time.UTC)},
+		{"-lastUpdate", "2025-03-11T09:00:00Z", "(last_update, id) < ($1, $2)", "ORDER BY last_update DESC, id DESC", time.Date(2025, 3, 11, 9, 0, 0, 0, time.UTC)},
+		{"titulo", "Proposta B", "(titulo, id) > ($1, $2)", "ORDER BY titulo ASC, id ASC", "Proposta B"},
+	}
+	for _, c := range casos {
+		t.Run(c.sort, func(t *testing.T) {
+			filtro := model.FiltroPropostas{Sort: c.sort, Limit: 20, Offset: 40}
+			cursor := &model.CursorPropostas{Sort: c.sort, Valor: c.valor, Id: id}
+			query, args := consultaPagina(filtro, &filtroSQL{}, cursor)
+
+			if !strings.Contains(query, c.comparacao) {
+				t.Errorf("consulta sem %q: %s", c.comparacao, query)
+			}
+			if !strings.Contains(query, c.ordem+" LIMIT 21") {
+				t.Errorf("consulta sem %q: %s", c.ordem, query)
+			}
+			if strings.Contains(query, "OFFSET") {
+				t.Errorf("offset não deve ser usado com cursor: %s", query)
+			}
+			if len(args) != 2 || args[1] != id {
+				t.Fatalf("argumentos %v", args)
+			}
+			if tempo, ok := c.valorArg.(time.Time); ok {
+				if lido, ok := args[0].(time.Time); !ok || !lido.Equal(tempo) {
+					t.Errorf("valor do cursor %v, esperado %v", args[0], tempo)
+				}
+			} else if args[0] != c.valorArg {
+				t.Errorf("valor do cursor %v, esperado %v", args[0], c.valorArg)
+			}
+		})
+	}
+}
+
+func TestConsultaPaginaNumeraArgumentosDepoisDosFiltros(t *testing.T) {
+	filtro := model.FiltroPropostas{Sort: "-dataCriacao", Limit: 10, Status: []string{model.StatusEnviado}, NomeEmpresa: "acme"}
+	f := filtrosListagem(filtro, model.IntervaloDatas{}, model.IntervaloDatas{})
+	cursor := &model.CursorPropostas{Sort: filtro.Sort, Valor: "2025-03-10T14:05:06Z", Id: uuid.New()}
+	query, args := consultaPagina(filtro, f, cursor)
+
+	if !strings.Contains(query, "(data_criacao, id) < ($3, $4)") {
+		t.Errorf("cursor deveria usar $3 e $4: %s", query)
+	}
+	if len(args) != 4 {
+		t.Errorf("%d argumentos, esperado 4", len(args))
+	}
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type PropostaVersaoRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewPropostaVersaoRepository(connection *pgxpool.Pool) PropostaVersaoRepository {
+	return PropostaVersaoRepository{
+		connection: connection,
+	}
+}
+
+// inserirVersao grava um snapshot da proposta dentro da transação de quem chama.
+// O número da versão é sequencial por proposta; a linha da proposta já está
+// bloqueada pelo UPDATE anterior na mesma transação, o que serializa a numeração.
+func inserirVersao(ctx context.Context, tx pgx.Tx, p *model.Proposta, origem string, restauradaDe *int) (*model.PropostaVersao, error) {
+	query := `INSERT INTO proposta_versoes (
+            proposta_id, numero, origem, restaurada_de, titulo, nome_empresa, nome_cliente,
+            prompt, cores, logo, logo_cliente, html, arquivo_final, arquivo_sha256, arquivo_tamanho
+        ) VALUES (
+            $1, (SELECT COALESCE(MAX(numero), 0) + 1 FROM proposta_versoes WHERE proposta_id = $1), $2, $3,
+            $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+        )
+        RETURNING id, numero, data_criacao`
+
+	v := model.PropostaVersao{
+		PropostaId:   p.Id,
+		Origem:       origem,
+		RestauradaDe: restauradaDe,
+		Titulo:       p.Titulo,
+		NomeEmpresa:  p.NomeEmpresa,
+		NomeCliente:  p.NomeCliente,
+		Prompt:       p.Prompt,
+		Cores:        p.Cores,
+		Logo:         p.Logo,
+		LogoCliente:  p.LogoCliente,
+		Html:         p.Html,
+		ArquivoFinal: p.ArquivoFinal,
+
+		ArquivoSha256:  p.ArquivoSha256,
+		ArquivoTamanho: p.ArquivoTamanho,
+	}
+	err := tx.QueryRow(ctx, query,
+		p.Id,
+		origem,
+		restauradaDe,
+		p.Titulo,
+		p.NomeEmpresa,
+		p.NomeCliente,
+		p.Prompt,
+		p.Cores,
+		p.Logo,
+		p.LogoCliente,
+		p.Html,
+		p.ArquivoFinal,
+		p.ArquivoSha256,
+		p.ArquivoTamanho,
+	).Scan(&v.Id, &v.Numero, &v.DataCriacao)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (vr *PropostaVersaoRepository) ListarVersoes(ctx context.Context, propostaID uuid.UUID) ([]model.PropostaVersao, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, proposta_id, numero, origem, restaurada_de, titulo, nome_empresa, nome_cliente,
+            prompt, cores, logo, logo_cliente, arquivo_final, data_criacao, arquivo_sha256, arquivo_tamanho
+        FROM proposta_versoes
+        WHERE proposta_id = $1
+        ORDER BY numero DESC`
+
+	rows, err := vr.connection.Query(ctx, query, propostaID)
+	if err != nil {
+		logger.Error("Erro ao listar versões da proposta", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	versoes := []model.PropostaVersao{}
+	for rows.Next() {
+		var v model.PropostaVersao
+		err := rows.Scan(
+			&v.Id,
+			&v.PropostaId,
+			&v.Numero,
+			&v.Origem,
+			&v.RestauradaDe,
+			&v.Titulo,
+			&v.NomeEmpresa,
+			&v.NomeCliente,
+			&v.Prompt,
+			&v.Cores,
+			&v.Logo,
+			&v.LogoCliente,
+			&v.ArquivoFinal,
+			&v.DataCriacao,
+			&v.ArquivoSha256,
+			&v.ArquivoTamanho,
+		)
+		if err != nil {
+			logger.Error("Erro ao fazer scan da versão", err)
+			return nil, err
+		}
+		versoes = append(versoes, v)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração das versões", err)
+		return nil, err
+	}
+	return versoes, nil
+}
+
+func findVersao(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, propostaID uuid.UUID, numero int) (*model.PropostaVersao, error) {
+	query := `SELECT id, proposta_id, numero, origem, restaurada_de, titulo, nome_empresa, nome_cliente,
+            prompt, cores, logo, logo_cliente, html, arquivo_final, data_criacao, arquivo_sha256, arquivo_tamanho
+        FROM proposta_versoes
+        WHERE proposta_id = $1 AND numero = $2`
+
+	var v model.PropostaVersao
+	err := q.QueryRow(ctx, query, propostaID, numero).Scan(
+		&v.Id,
+		&v.PropostaId,
+		&v.Numero,
+		&v.Origem,
+		&v.RestauradaDe,
+		&v.Titulo,
+		&v.NomeEmpresa,
+		&v.NomeCliente,
+		&v.Prompt,
+		&v.Cores,
+		&v.Logo,
+		&v.LogoCliente,
+		&v.Html,
+		&v.ArquivoFinal,
+		&v.DataCriacao,
+		&v.ArquivoSha256,
+		&v.ArquivoTamanho,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (vr *PropostaVersaoRepository) FindVersao(ctx context.Context, propostaID uuid.UUID, numero int) (*model.PropostaVersao, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	v, err := findVersao(ctx, vr.connection, propostaID, numero)
+	if err != nil {
+		logger.Error("Erro ao buscar versão da proposta", err, zap.String("proposta_id", propostaID.String()), zap.Int("numero", numero))
+		return nil, err
+	}
+	return v, nil
+}
+
+// RestaurarVersao copia o conteúdo da versão para a proposta e registra uma nova
+// versão de origem "restauracao", preservando o histórico linear.
+func (vr *PropostaVersaoRepository) RestaurarVersao(ctx context.Context, propostaID uuid.UUID, numero int) (*model.Proposta, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tx, err := vr.connection.Begin(ctx)
+	if err != nil {
+		logger.Error("Erro ao iniciar transação de restauração", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := travarConteudoAlteravel(ctx, tx, propostaID); err != nil {
+		return nil, err
+	}
+	v, err := findVersao(ctx, tx, propostaID, numero)
+	if err != nil {
+		logger.Error("Erro ao buscar versão para restauração", err, zap.String("proposta_id", propostaID.String()), zap.Int("numero", numero))
+		return nil, err
+	}
+
+	query := `UPDATE propostas
+        SET titulo = $1, nome_empresa = $2, nome_cliente = $3, prompt = $4, cores = $5,
+            logo = $6, logo_cliente = $7, html = $8, arquivo_final = $9,
+            arquivo_sha256 = $10, arquivo_tamanho = $11, last_update = $12
+        WHERE id = $13
+        RETURNING ` + propostaColunas
+
+	p, err := scanProposta(tx.QueryRow(ctx, query,
+		v.Titulo,
+		v.NomeEmpresa,
+		v.NomeCliente,
+		v.Prompt,
+		v.Cores,
+		v.Logo,
+		v.LogoCliente,
+		v.Html,
+		v.ArquivoFinal,
+		v.ArquivoSha256,
+		v.ArquivoTamanho,
+		time.Now(),
+		propostaID,
+	))
+	if err != nil {
+		logger.Error("Erro ao restaurar versão da proposta", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	// As versões não guardam itens; a proposta restaurada mantém os atuais.
+	if err := carregarItens(ctx, tx, p); err != nil {
+		logger.Error("Erro ao carregar itens da proposta", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+
+	if _, err := inserirVersao(ctx, tx, p, model.VersaoRestauracao, &v.Numero); err != nil {
+		logger.Error("Erro ao registrar versão de restauração", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("Erro ao confirmar restauração da proposta", err)
+		return nil, err
+	}
+	logger.Info("Versão da proposta restaurada", zap.String("proposta_id", propostaID.String()), zap.Int("numero", numero))
+	return p, nil
+}
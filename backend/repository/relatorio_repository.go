@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unidadesPeriodo traduz o período do relatório para o date_trunc.
+var unidadesPeriodo = map[string]string{
+	model.PeriodoSemana: "week",
+	model.PeriodoMes:    "month",
+}
+
+type RelatorioRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewRelatorioRepository(connection *pgxpool.Pool) RelatorioRepository {
+	return RelatorioRepository{
+		connection: connection,
+	}
+}
+
+// escopoPipeline seleciona as propostas criadas no intervalo, já com o
+// período (em UTC) a que pertencem. $1 é a unidade do date_trunc, $2 e $3 os
+// limites de criação e $4 o trecho do nome da empresa.
+const escopoPipeline = `base AS (
+        SELECT p.id, p.status, p.data_criacao, date_trunc($1, p.data_criacao, 'UTC') AS periodo
+        FROM propostas p
+        WHERE p.data_criacao >= $2 AND p.data_criacao <= $3
+          AND ($4 = '' OR p.nome_empresa ILIKE '%' || $4 || '%')
+    )`
+
+// Pipeline devolve as métricas de cada período com propostas e, com Inicio
+// nulo, as do intervalo inteiro (GROUPING SETS), para que a mediana total seja
+// calculada sobre todas as propostas e não a partir das medianas dos períodos.
+// As taxas ficam para o chamador.
+func (rr *RelatorioRepository) Pipeline(ctx context.Context, periodo string, de time.Time, ate time.Time, nomeEmpresa string) ([]model.MetricasPipeline, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	unidade := unidadesPeriodo[periodo]
+	empresa := escaparLike(nomeEmpresa)
+
+	query := `WITH ` + escopoPipeline + `,
+    marcos AS (
+        SELECT h.proposta_id,
+            min(h.data_criacao) FILTER (WHERE h.para_status = 'enviado') AS enviada_em,
+            min(h.data_criacao) FILTER (WHERE h.para_status = 'aprovado') AS aprovada_em,
+            bool_or(h.para_status = 'recusado') AS recusada
+        FROM proposta_status_historico h
+        JOIN base b ON b.id = h.proposta_id
+        GROUP BY h.proposta_id
+    ),
+    regeneracoes AS (
+        SELECT v.proposta_id, count(*) AS total
+        FROM proposta_versoes v
+        JOIN base b ON b.id = v.proposta_id
+        WHERE v.origem = 'regeneracao'
+        GROUP BY v.proposta_id
+    ),
+    jobs AS (
+        SELECT j.proposta_id,
+            count(*) FILTER (WHERE j.status IN ('succeeded', 'failed')) AS finalizados,
+            count(*) FILTER (WHERE j.status = 'failed') AS falhos
+        FROM geracao_jobs j
+        JOIN base b ON b.id = j.proposta_id
+        GROUP BY j.proposta_id
+    )
+    SELECT b.periodo,
+        count(*),
+        count(m.enviada_em),
+        count(m.aprovada_em),
+        count(*) FILTER (WHERE m.recusada),
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY extract(epoch FROM m.aprovada_em - b.data_criacao) / 3600),
+        coalesce(sum(r.total), 0)::int,
+        coalesce(sum(j.finalizados), 0)::int,
+        coalesce(sum(j.falhos), 0)::int
+    FROM base b
+    LEFT JOIN marcos m ON m.proposta_id = b.id
+    LEFT JOIN regeneracoes r ON r.proposta_id = b.id
+    LEFT JOIN jobs j ON j.proposta_id = b.id
+    GROUP BY GROUPING SETS ((b.periodo), ())
+    ORDER BY b.periodo NULLS FIRST`
+
+	rows, err := rr.connection.Query(ctx, query, unidade, de, ate, empresa)
+	if err != nil {
+		logger.Error("Erro ao calcular o relatório do pipeline", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	metricas := []model.MetricasPipeline{}
+	for rows.Next() {
+		var m model.MetricasPipeline
+		err := rows.Scan(
+			&m.Inicio,
+			&m.Total,
+			&m.Enviadas,
+			&m.Aprovadas,
+			&m.Recusadas,
+			&m.MedianaHorasAteAprovacao,
+			&m.Regeneracoes,
+			&m.Geracoes,
+			&m.FalhasGeracao,
+		)
+		if err != nil {
+			logger.Error("Erro ao fazer scan das métricas do pipeline", err)
+			return nil, err
+		}
+		m.PorStatus = map[string]int{}
+		metricas = append(metricas, m)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração das métricas do pipeline", err)
+		return nil, err
+	}
+
+	// Contagem por status atual, no mesmo escopo e com os mesmos grupos.
+	query = `WITH ` + escopoPipeline + `
+    SELECT b.periodo, b.status, count(*)
+    FROM base b
+    GROUP BY GROUPING SETS ((b.periodo, b.status), (b.status))`
+
+	rows, err = rr.connection.Query(ctx, query, unidade, de, ate, empresa)
+	if err != nil {
+		logger.Error("Erro ao contar propostas por status", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inicio *time.Time
+		var status string
+		var total int
+		if err := rows.Scan(&inicio, &status, &total); err != nil {
+			logger.Error("Erro ao fazer scan da contagem por status", err)
+			return nil, err
+		}
+		for i := range metricas {
+			if mesmoPeriodo(metricas[i].Inicio, inicio) {
+				metricas[i].PorStatus[status] = total
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração da contagem por status", err)
+		return nil, err
+	}
+	return metricas, nil
+}
+
+func mesmoPeriodo(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/shared/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type StatusHistoricoRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewStatusHistoricoRepository(connection *pgxpool.Pool) StatusHistoricoRepository {
+	return StatusHistoricoRepository{
+		connection: connection,
+	}
+}
+
+// inserirTransicao registra a mudança de status na transação que a aplicou.
+func inserirTransicao(ctx context.Context, tx pgx.Tx, propostaID uuid.UUID, de *string, para string, ator string, motivo *string) error {
+	query := `INSERT INTO proposta_status_historico (proposta_id, de_status, para_status, ator, motivo)
+        VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := tx.Exec(ctx, query, propostaID, de, para, ator, motivo)
+	return err
+}
+
+func (sr *StatusHistoricoRepository) ListarHistorico(ctx context.Context, propostaID uuid.UUID) ([]model.TransicaoStatus, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, proposta_id, de_status, para_status, ator, motivo, data_criacao
+        FROM proposta_status_historico
+        WHERE proposta_id = $1
+        ORDER BY data_criacao, id`
+
+	rows, err := sr.connection.Query(ctx, query, propostaID)
+	if err != nil {
+		logger.Error("Erro ao listar histórico de status", err, zap.String("proposta_id", propostaID.String()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	historico := []model.TransicaoStatus{}
+	for rows.Next() {
+		var t model.TransicaoStatus
+		if err := rows.Scan(&t.Id, &t.PropostaId, &t.DeStatus, &t.ParaStatus, &t.Ator, &t.Motivo, &t.DataCriacao); err != nil {
+			logger.Error("Erro ao fazer scan do histórico de status", err)
+			return nil, err
+		}
+		historico = append(historico, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração do histórico de status", err)
+		return nil, err
+	}
+	return historico, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"propulse/shared/logger"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Chaves dos advisory locks usados por tarefas periódicas que devem rodar em
+// uma única réplica por vez.
+const (
+	TravaColetorOrfaos int64 = 7301
+	TravaExpiracao     int64 = 7302
+)
+
+type TravaRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewTravaRepository(connection *pgxpool.Pool) TravaRepository {
+	return TravaRepository{
+		connection: connection,
+	}
+}
+
+// ExecutarComTrava roda fn somente se conseguir o advisory lock de sessão
+// informado, segurando uma conexão do pool até o fim. Retorna false sem
+// executar fn quando outra réplica já detém a trava.
+func (tr *TravaRepository) ExecutarComTrava(ctx context.Context, trava int64, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := tr.connection.Acquire(ctx)
+	if err != nil {
+		logger.Error("Erro ao obter conexão para advisory lock", err)
+		return false, err
+	}
+	defer conn.Release()
+
+	var obtida bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, trava).Scan(&obtida); err != nil {
+		logger.Error("Erro ao tentar obter advisory lock", err, zap.Int64("trava", trava))
+		return false, err
+	}
+	if !obtida {
+		return false, nil
+	}
+	defer func() {
+		// Sem o unlock a trava ficaria presa na conexão devolvida ao pool.
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, trava); err != nil {
+			logger.Error("Erro ao liberar advisory lock", err, zap.Int64("trava", trava))
+			conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}()
+	return true, fn(ctx)
+}
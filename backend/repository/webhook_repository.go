@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"propulse/model"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookColunas = `id, url, eventos, segredo, ativo, data_criacao`
+	entregaColunas = `id, webhook_id, evento_id, evento, proposta_id, payload, status, tentativas, proxima_tentativa, ultimo_status_http, ultimo_erro, data_criacao, last_update, entregue_em`
+)
+
+type WebhookRepository struct {
+	connection *pgxpool.Pool
+}
+
+func NewWebhookRepository(connection *pgxpool.Pool) WebhookRepository {
+	return WebhookRepository{
+		connection: connection,
+	}
+}
+
+func scanWebhook(row pgx.Row) (*model.Webhook, error) {
+	var w model.Webhook
+	err := row.Scan(
+		&w.Id,
+		&w.Url,
+		&w.Eventos,
+		&w.Segredo,
+		&w.Ativo,
+		&w.DataCriacao,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func scanEntrega(row pgx.Row) (*model.EntregaWebhook, error) {
+	var e model.EntregaWebhook
+	err := row.Scan(
+		&e.Id,
+		&e.WebhookId,
+		&e.EventoId,
+		&e.Evento,
+		&e.PropostaId,
+		&e.Payload,
+		&e.Status,
+		&e.Tentativas,
+		&e.ProximaTentativa,
+		&e.UltimoStatusHttp,
+		&e.UltimoErro,
+		&e.DataCriacao,
+		&e.LastUpdate,
+		&e.EntregueEm,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// inserirEvento grava uma entrega para cada webhook ativo assinante do evento,
+// na transação da mudança que o originou (outbox). Assim nenhum evento se
+// perde se o processo cair depois do commit, nem é publicado se a mudança for
+// desfeita.
+func inserirEvento(ctx context.Context, tx pgx.Tx, evento string, propostaID uuid.UUID, dados any) error {
+	eventoID := uuid.New()
+	payload, err := json.Marshal(model.EventoWebhook{
+		Id:         eventoID,
+		Evento:     evento,
+		PropostaId: propostaID,
+		Data:       time.Now(),
+		Dados:      dados,
+	})
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO webhook_entregas (webhook_id, evento_id, evento, proposta_id, payload, status)
+        SELECT id, $1, $2, $3, $4, $5
+        FROM webhooks
+        WHERE ativo AND $2 = ANY(eventos)`
+
+	_, err = tx.Exec(ctx, query, eventoID, evento, propostaID, payload, model.EntregaPendente)
+	return err
+}
+
+// inserirEventoProposta publica o evento com a proposta como dados, sem o HTML.
+func inserirEventoProposta(ctx context.Context, tx pgx.Tx, evento string, p *model.Proposta) error {
+	dados := *p
+	dados.Html = ""
+	return inserirEvento(ctx, tx, evento, p.Id, dados)
+}
+
+// inserirEventoStatus publica o evento correspondente ao status atual da
+// proposta, quando houver um.
+func inserirEventoStatus(ctx context.Context, tx pgx.Tx, p *model.Proposta) error {
+	evento, ok := model.EventoDoStatus(p.Status)
+	if !ok {
+		return nil
+	}
+	return inserirEventoProposta(ctx, tx, evento, p)
+}
+
+func (wr *WebhookRepository) CriarWebhook(ctx context.Context, webhook model.Webhook) (*model.Webhook, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO webhooks (url, eventos, segredo)
+        VALUES ($1, $2, $3)
+        RETURNING ` + webhookColunas
+
+	w, err := scanWebhook(wr.connection.QueryRow(ctx, query, webhook.Url, webhook.Eventos, webhook.Segredo))
+	if err != nil {
+		logger.Error("Erro ao criar webhook", err)
+		return nil, err
+	}
+	return w, nil
+}
+
+func (wr *WebhookRepository) ListarWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	rows, err := wr.connection.Query(ctx, `SELECT `+webhookColunas+` FROM webhooks ORDER BY data_criacao DESC`)
+	if err != nil {
+		logger.Error("Erro ao listar webhooks", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []model.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			logger.Error("Erro ao fazer scan do webhook", err)
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração dos webhooks", err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (wr *WebhookRepository) FindWebhook(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	return scanWebhook(wr.connection.QueryRow(ctx, `SELECT `+webhookColunas+` FROM webhooks WHERE id = $1`, id))
+}
+
+// DeleteWebhook remove a assinatura; as entregas pendentes caem em cascata.
+func (wr *WebhookRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	tag, err := wr.connection.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		logger.Error("Erro ao remover webhook", err, zap.String("id", id.String()))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ListarEntregas devolve as entregas mais recentes do webhook, opcionalmente
+// filtradas por status.
+func (wr *WebhookRepository) ListarEntregas(ctx context.Context, webhookID uuid.UUID, status string, limite int) ([]model.EntregaWebhook, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + entregaColunas + ` FROM webhook_entregas
+        WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY data_criacao DESC
+        LIMIT $3`
+
+	rows, err := wr.connection.Query(ctx, query, webhookID, status, limite)
+	if err != nil {
+		logger.Error("Erro ao listar entregas do webhook", err, zap.String("webhook_id", webhookID.String()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	entregas := []model.EntregaWebhook{}
+	for rows.Next() {
+		e, err := scanEntrega(rows)
+		if err != nil {
+			logger.Error("Erro ao fazer scan da entrega", err)
+			return nil, err
+		}
+		entregas = append(entregas, *e)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Erro durante iteração das entregas", err)
+		return nil, err
+	}
+	return entregas, nil
+}
+
+// ClaimProximaEntrega reserva a próxima entrega pronta, junto com o webhook de
+// destino. Entregas em "entregando" sem atualização há mais que lease são de um
+// processo que caiu e voltam a ser elegíveis. Retorna nil, nil, nil quando não
+// há entregas prontas.
+func (wr *WebhookRepository) ClaimProximaEntrega(ctx context.Context, lease time.Duration) (*model.EntregaWebhook, *model.Webhook, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE webhook_entregas
+        SET status = $1, tentativas = tentativas + 1, last_update = now()
+        WHERE id = (
+            SELECT id FROM webhook_entregas
+            WHERE (status = $2 AND proxima_tentativa <= now())
+               OR (status = $1 AND last_update < $3)
+            ORDER BY proxima_tentativa
+            FOR UPDATE SKIP LOCKED
+            LIMIT 1
+        )
+        RETURNING ` + entregaColunas
+
+	e, err := scanEntrega(wr.connection.QueryRow(ctx, query, model.EntregaEntregando, model.EntregaPendente, time.Now().Add(-lease)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		logger.Error("Erro ao reservar entrega de webhook", err)
+		return nil, nil, err
+	}
+	w, err := scanWebhook(wr.connection.QueryRow(ctx, `SELECT `+webhookColunas+` FROM webhooks WHERE id = $1`, e.WebhookId))
+	if err != nil {
+		logger.Error("Erro ao buscar webhook da entrega", err, zap.String("entrega_id", e.Id.String()))
+		return nil, nil, err
+	}
+	return e, w, nil
+}
+
+func (wr *WebhookRepository) MarcarEntregue(ctx context.Context, id uuid.UUID, statusHttp int) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE webhook_entregas
+        SET status = $1, ultimo_status_http = $2, ultimo_erro = NULL, last_update = now(), entregue_em = now()
+        WHERE id = $3`
+
+	if _, err := wr.connection.Exec(ctx, query, model.EntregaEntregue, statusHttp, id); err != nil {
+		logger.Error("Erro ao marcar entrega como concluída", err, zap.String("id", id.String()))
+		return err
+	}
+	return nil
+}
+
+// MarcarFalhaEntrega registra o erro da tentativa. Com proximaTentativa
+// preenchida a entrega volta para a fila; sem ela é encerrada como falhou.
+func (wr *WebhookRepository) MarcarFalhaEntrega(ctx context.Context, id uuid.UUID, statusHttp *int, erro string, proximaTentativa *time.Time) error {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	var err error
+	if proximaTentativa != nil {
+		query := `UPDATE webhook_entregas
+            SET status = $1, ultimo_status_http = $2, ultimo_erro = $3, proxima_tentativa = $4, last_update = now()
+            WHERE id = $5`
+		_, err = wr.connection.Exec(ctx, query, model.EntregaPendente, statusHttp, erro, *proximaTentativa, id)
+	} else {
+		query := `UPDATE webhook_entregas
+            SET status = $1, ultimo_status_http = $2, ultimo_erro = $3, last_update = now()
+            WHERE id = $4`
+		_, err = wr.connection.Exec(ctx, query, model.EntregaFalhou, statusHttp, erro, id)
+	}
+	if err != nil {
+		logger.Error("Erro ao registrar falha da entrega", err, zap.String("id", id.String()))
+		return err
+	}
+	return nil
+}
+
+// ReenviarEntrega devolve a entrega à fila com as tentativas zeradas. O
+// payload é o mesmo, então o receptor recebe o mesmo id de evento.
+func (wr *WebhookRepository) ReenviarEntrega(ctx context.Context, webhookID uuid.UUID, entregaID uuid.UUID) (*model.EntregaWebhook, error) {
+	ctx, cancel := comTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE webhook_entregas
+        SET status = $1, tentativas = 0, proxima_tentativa = now(), entregue_em = NULL, last_update = now()
+        WHERE id = $2 AND webhook_id = $3
+        RETURNING ` + entregaColunas
+
+	e, err := scanEntrega(wr.connection.QueryRow(ctx, query, model.EntregaPendente, entregaID, webhookID))
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/logger"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// AceiteService conduz a decisão do cliente pelo link público: grava a
+// evidência, move o status da proposta e anexa o certificado ao PDF.
+type AceiteService struct {
+	aceites   repository.AceiteRepository
+	links     LinkService
+	propostas PropostaService
+	gerador   Generator
+}
+
+func NewAceiteService(ar repository.AceiteRepository, links LinkService, propostas PropostaService, gerador Generator) AceiteService {
+	return AceiteService{
+		aceites:   ar,
+		links:     links,
+		propostas: propostas,
+		gerador:   gerador,
+	}
+}
+
+// EvidenciaRequisicao traz os dados da requisição HTTP gravados como evidência.
+type EvidenciaRequisicao struct {
+	Ip        string
+	UserAgent string
+}
+
+// PaginaAceite carrega o que a página pública de aceite exibe. O aceite
+// retornado é nil enquanto o cliente não decidiu.
+func (as *AceiteService) PaginaAceite(ctx context.Context, token string) (*model.Proposta, *model.Aceite, error) {
+	link, err := as.links.Resolver(ctx, token, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	proposta, err := as.propostas.FindByID(ctx, link.PropostaId.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	aceite, err := as.aceites.FindAceiteByProposta(ctx, link.PropostaId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return proposta, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return proposta, aceite, nil
+}
+
+// RegistrarAceite grava a decisão do cliente. O certificado é gerado em seguida;
+// se falhar, a decisão continua registrada e o certificado pode ser gerado de
+// novo por POST /proposta/:id/aceite/certificado.
+func (as *AceiteService) RegistrarAceite(ctx context.Context, token string, input model.RegistrarAceite, evidencia EvidenciaRequisicao) (*model.Aceite, error) {
+	link, err := as.links.Resolver(ctx, token, false)
+	if err != nil {
+		return nil, err
+	}
+	proposta, err := as.propostas.FindByID(ctx, link.PropostaId.String())
+	if err != nil {
+		return nil, err
+	}
+	if proposta.ArquivoSha256 == nil {
+		return nil, ErrPDFIndisponivel
+	}
+	documentoSha256 := strings.ToLower(input.DocumentoSha256)
+	if documentoSha256 == "" {
+		documentoSha256 = *proposta.ArquivoSha256
+	}
+
+	var comentario *string
+	if c := strings.TrimSpace(input.Comentario); c != "" {
+		comentario = &c
+	}
+	nome := strings.TrimSpace(input.Nome)
+	aceite, err := as.aceites.RegistrarAceite(ctx, model.Aceite{
+		PropostaId:      proposta.Id,
+		LinkId:          link.Id,
+		Decisao:         input.Decisao,
+		Nome:            nome,
+		Comentario:      comentario,
+		Ip:              evidencia.Ip,
+		UserAgent:       evidencia.UserAgent,
+		DocumentoSha256: documentoSha256,
+	}, input.StatusDestino(), model.AtorCliente+":"+nome)
+	if err != nil {
+		return nil, err
+	}
+
+	// A decisão já foi confirmada; o certificado não deve ser perdido se o
+	// cliente fechar a página durante a renderização.
+	comCertificado, err := as.anexarCertificado(context.WithoutCancel(ctx), aceite)
+	if err != nil {
+		logger.Error("Erro ao gerar certificado do aceite, a decisão foi registrada", err, zap.String("proposta_id", aceite.PropostaId.String()))
+		return aceite, nil
+	}
+	return comCertificado, nil
+}
+
+func (as *AceiteService) FindAceite(ctx context.Context, idParam string) (*model.Aceite, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	return as.aceites.FindAceiteByProposta(ctx, id)
+}
+
+// GerarCertificado gera o certificado de um aceite que ficou sem ele.
+func (as *AceiteService) GerarCertificado(ctx context.Context, idParam string) (*model.Aceite, error) {
+	aceite, err := as.FindAceite(ctx, idParam)
+	if err != nil {
+		return nil, err
+	}
+	return as.anexarCertificado(ctx, aceite)
+}
+
+func (as *AceiteService) anexarCertificado(ctx context.Context, aceite *model.Aceite) (*model.Aceite, error) {
+	if aceite.CertificadoChave != nil {
+		return aceite, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, geracaoTimeout)
+	defer cancel()
+
+	proposta, pdf, _, err := as.propostas.AbrirPDF(ctx, aceite.PropostaId.String())
+	if err != nil {
+		return nil, err
+	}
+	defer pdf.Close()
+	// O certificado só pode ser anexado ao documento sobre o qual o cliente decidiu.
+	if proposta.ArquivoSha256 == nil || *proposta.ArquivoSha256 != aceite.DocumentoSha256 {
+		return nil, model.ErrDocumentoDivergente
+	}
+
+	comCertificado, err := as.gerador.AnexarCertificado(ctx, *proposta, pdf, *aceite)
+	if err != nil {
+		return nil, err
+	}
+	defer comCertificado.Close()
+
+	artefato, err := as.propostas.SalvarPDF(ctx, proposta.Id, comCertificado)
+	if err != nil {
+		return nil, err
+	}
+	return as.aceites.RegistrarCertificado(ctx, aceite, *artefato)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const expiracaoTamanhoLote = 200
+
+// AgendadorExpiracao move para expirado as propostas enviadas cuja validade
+// terminou. Todas as réplicas rodam o agendador, mas um advisory lock garante
+// que só uma varre o banco por vez.
+type AgendadorExpiracao struct {
+	propostas repository.PropostaRepository
+	travas    repository.TravaRepository
+	intervalo time.Duration
+}
+
+func NewAgendadorExpiracao(pr repository.PropostaRepository, tr repository.TravaRepository) *AgendadorExpiracao {
+	return &AgendadorExpiracao{
+		propostas: pr,
+		travas:    tr,
+		intervalo: env.Duration("EXPIRACAO_INTERVALO", 5*time.Minute),
+	}
+}
+
+// Iniciar executa a varredura na inicialização e depois a cada
+// EXPIRACAO_INTERVALO até ctx ser cancelado. Intervalo igual a zero desliga o
+// agendador.
+func (a *AgendadorExpiracao) Iniciar(ctx context.Context) {
+	if a.intervalo <= 0 {
+		logger.Info("Agendador de expiração desativado")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(a.intervalo)
+		defer ticker.Stop()
+		for {
+			a.Executar(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Executar expira as propostas vencidas em lotes. Retorna quantas foram
+// expiradas; zero também quando outra réplica detém a trava.
+func (a *AgendadorExpiracao) Executar(ctx context.Context) (int, error) {
+	total := 0
+	executou, err := a.travas.ExecutarComTrava(ctx, repository.TravaExpiracao, func(ctx context.Context) error {
+		agora := time.Now()
+		for {
+			ids, err := a.propostas.ExpirarVencidas(ctx, agora, expiracaoTamanhoLote)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				logger.Info("Proposta expirada por validade", zap.String("proposta_id", id.String()))
+			}
+			total += len(ids)
+			if len(ids) < expiracaoTamanhoLote {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		logger.Error("Erro ao expirar propostas vencidas", err)
+		return total, err
+	}
+	if executou && total > 0 {
+		logger.Info("Propostas vencidas expiradas", zap.Int("total", total))
+	}
+	return total, nil
+}
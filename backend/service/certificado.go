@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"html/template"
+	"propulse/model"
+	"time"
+)
+
+var certificadoHTMLTemplate = template.Must(template.New("certificado").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="UTF-8">
+<title>Certificado de {{.Acao}}</title>
+<style>
+body { font-family: sans-serif; margin: 40px; color: #222; }
+h1 { font-size: 22px; border-bottom: 2px solid #222; padding-bottom: 8px; }
+table { border-collapse: collapse; width: 100%; margin-top: 16px; }
+td { border: 1px solid #ccc; padding: 8px; vertical-align: top; font-size: 13px; }
+td:first-child { width: 30%; font-weight: bold; background: #f5f5f5; }
+.hash { font-family: monospace; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>Certificado de {{.Acao}}</h1>
+<p>Este certificado registra a decisão do cliente sobre a proposta abaixo, tomada pelo link de compartilhamento.</p>
+<table>
+<tr><td>Proposta</td><td>{{.Proposta.Titulo}}</td></tr>
+<tr><td>Identificador</td><td class="hash">{{.Proposta.Id}}</td></tr>
+<tr><td>Empresa</td><td>{{.Proposta.NomeEmpresa}}</td></tr>
+<tr><td>Decisão</td><td>{{.Acao}}</td></tr>
+<tr><td>Nome informado</td><td>{{.Aceite.Nome}}</td></tr>
+{{if .Aceite.Comentario}}<tr><td>Comentário</td><td>{{.Aceite.Comentario}}</td></tr>{{end}}
+<tr><td>Data e hora (UTC)</td><td>{{.Data}}</td></tr>
+<tr><td>Endereço IP</td><td>{{.Aceite.Ip}}</td></tr>
+<tr><td>Navegador</td><td>{{.Aceite.UserAgent}}</td></tr>
+<tr><td>SHA-256 do documento</td><td class="hash">{{.Aceite.DocumentoSha256}}</td></tr>
+</table>
+</body>
+</html>
+`))
+
+type dadosCertificado struct {
+	Proposta model.Proposta
+	Aceite   model.Aceite
+	Acao     string
+	Data     string
+}
+
+func novosDadosCertificado(proposta model.Proposta, aceite model.Aceite) dadosCertificado {
+	acao := "aceite"
+	if aceite.Decisao == model.DecisaoRecusar {
+		acao = "recusa"
+	}
+	return dadosCertificado{
+		Proposta: proposta,
+		Aceite:   aceite,
+		Acao:     acao,
+		Data:     aceite.DataCriacao.UTC().Format(time.DateTime),
+	}
+}
+
+// certificadoHTML renderiza a página de certificado que o ia-service anexa ao PDF.
+func certificadoHTML(proposta model.Proposta, aceite model.Aceite) (string, error) {
+	var html bytes.Buffer
+	if err := certificadoHTMLTemplate.Execute(&html, novosDadosCertificado(proposta, aceite)); err != nil {
+		return "", err
+	}
+	return html.String(), nil
+}
+
+// linhasCertificado é a mesma página em texto simples, usada pelo FakeGenerator.
+func linhasCertificado(proposta model.Proposta, aceite model.Aceite) []string {
+	d := novosDadosCertificado(proposta, aceite)
+	linhas := []string{
+		"Certificado de " + d.Acao,
+		"",
+		"Proposta: " + proposta.Titulo,
+		"Identificador: " + proposta.Id.String(),
+		"Empresa: " + proposta.NomeEmpresa,
+		"Decisão: " + d.Acao,
+		"Nome informado: " + aceite.Nome,
+	}
+	if aceite.Comentario != nil {
+		linhas = append(linhas, "Comentário:")
+		linhas = append(linhas, quebrarLinhas(*aceite.Comentario, 90)...)
+	}
+	linhas = append(linhas,
+		"Data e hora (UTC): "+d.Data,
+		"Endereço IP: "+aceite.Ip,
+	)
+	linhas = append(linhas, quebrarLinhas("Navegador: "+aceite.UserAgent, 90)...)
+	return append(linhas, "SHA-256 do documento:", aceite.DocumentoSha256)
+}
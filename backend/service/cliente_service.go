@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/logger"
+
+	"github.com/google/uuid"
+)
+
+type ClienteService struct {
+	repository repository.ClienteRepository
+}
+
+func NewClienteService(cr repository.ClienteRepository) ClienteService {
+	return ClienteService{
+		repository: cr,
+	}
+}
+
+func (cs *ClienteService) CriarCliente(ctx context.Context, cliente model.Cliente) (*model.Cliente, error) {
+	cliente.Normalizar()
+	return cs.repository.CriarCliente(ctx, cliente)
+}
+
+func (cs *ClienteService) ListarClientes(ctx context.Context, busca string) ([]model.Cliente, error) {
+	return cs.repository.ListarClientes(ctx, busca)
+}
+
+func (cs *ClienteService) FindCliente(ctx context.Context, idParam string) (*model.Cliente, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	return cs.repository.FindCliente(ctx, id)
+}
+
+func (cs *ClienteService) AtualizarCliente(ctx context.Context, idParam string, cliente model.Cliente) (*model.Cliente, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	cliente.Normalizar()
+	return cs.repository.AtualizarCliente(ctx, id, cliente)
+}
+
+func (cs *ClienteService) DeleteCliente(ctx context.Context, idParam string) error {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return err
+	}
+	return cs.repository.DeleteCliente(ctx, id)
+}
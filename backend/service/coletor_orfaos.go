@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"propulse/storage"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	prefixoPDFs        = "propostas/"
+	coletorTamanhoLote = 500
+)
+
+// RelatorioColeta resume uma execução do coletor de órfãos. Em dry-run os
+// órfãos são apenas listados.
+type RelatorioColeta struct {
+	Inicio      time.Time `json:"inicio"`
+	Fim         time.Time `json:"fim"`
+	DryRun      bool      `json:"dryRun"`
+	Carencia    string    `json:"carencia"`
+	Verificados int       `json:"verificados"`
+	Orfaos      []string  `json:"orfaos"`
+	BytesOrfaos int64     `json:"bytesOrfaos"`
+	Removidos   int       `json:"removidos"`
+	Falhas      int       `json:"falhas"`
+}
+
+// ColetorOrfaos reconcilia o storage com o banco: objetos sob "propostas/" que
+// nenhuma proposta, versão ou artefato referencia e que são mais antigos que a
+// carência são removidos. A carência protege PDFs recém-gravados cuja
+// transação ainda não foi confirmada. Um advisory lock garante uma única
+// execução por vez entre as réplicas.
+type ColetorOrfaos struct {
+	store     storage.Store
+	artefatos repository.ArtefatoRepository
+	travas    repository.TravaRepository
+	intervalo time.Duration
+	carencia  time.Duration
+	dryRun    bool
+}
+
+func NewColetorOrfaos(store storage.Store, ar repository.ArtefatoRepository, tr repository.TravaRepository) *ColetorOrfaos {
+	return &ColetorOrfaos{
+		store:     store,
+		artefatos: ar,
+		travas:    tr,
+		intervalo: env.Duration("GC_INTERVALO", time.Hour),
+		carencia:  env.Duration("GC_CARENCIA", 24*time.Hour),
+		dryRun:    env.Bool("GC_DRY_RUN", false),
+	}
+}
+
+// Iniciar executa a coleta periodicamente até ctx ser cancelado. GC_INTERVALO
+// igual a zero desliga a execução periódica.
+func (c *ColetorOrfaos) Iniciar(ctx context.Context) {
+	if c.intervalo <= 0 {
+		logger.Info("Coletor de órfãos periódico desativado")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(c.intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Executar(ctx, c.dryRun)
+			}
+		}
+	}()
+}
+
+// Executar roda uma coleta. Retorna nil quando outra réplica já está coletando.
+func (c *ColetorOrfaos) Executar(ctx context.Context, dryRun bool) (*RelatorioColeta, error) {
+	var relatorio *RelatorioColeta
+	executou, err := c.travas.ExecutarComTrava(ctx, repository.TravaColetorOrfaos, func(ctx context.Context) error {
+		var err error
+		relatorio, err = c.coletar(ctx, dryRun)
+		return err
+	})
+	if err != nil {
+		logger.Error("Erro na coleta de PDFs órfãos", err)
+		return nil, err
+	}
+	if !executou {
+		logger.Info("Coleta de órfãos já em execução em outra réplica")
+		return nil, nil
+	}
+	logger.Info("Coleta de PDFs órfãos concluída",
+		zap.Bool("dry_run", relatorio.DryRun),
+		zap.Int("verificados", relatorio.Verificados),
+		zap.Int("orfaos", len(relatorio.Orfaos)),
+		zap.Int("removidos", relatorio.Removidos),
+		zap.Int("falhas", relatorio.Falhas),
+	)
+	return relatorio, nil
+}
+
+func (c *ColetorOrfaos) coletar(ctx context.Context, dryRun bool) (*RelatorioColeta, error) {
+	relatorio := &RelatorioColeta{
+		Inicio:   time.Now(),
+		DryRun:   dryRun,
+		Carencia: c.carencia.String(),
+		Orfaos:   []string{},
+	}
+	limite := relatorio.Inicio.Add(-c.carencia)
+
+	lote := make([]storage.Info, 0, coletorTamanhoLote)
+	processar := func() error {
+		if len(lote) == 0 {
+			return nil
+		}
+		chaves := make([]string, len(lote))
+		tamanhos := make(map[string]int64, len(lote))
+		for i, info := range lote {
+			chaves[i] = info.Chave
+			tamanhos[info.Chave] = info.Tamanho
+		}
+		lote = lote[:0]
+
+		orfas, err := c.artefatos.FiltrarNaoReferenciadas(ctx, chaves)
+		if err != nil {
+			return err
+		}
+		for _, chave := range orfas {
+			relatorio.Orfaos = append(relatorio.Orfaos, chave)
+			relatorio.BytesOrfaos += tamanhos[chave]
+			if dryRun {
+				continue
+			}
+			if err := c.store.Delete(ctx, chave); err != nil {
+				logger.Error("Erro ao remover PDF órfão", err, zap.String("chave", chave))
+				relatorio.Falhas++
+				continue
+			}
+			relatorio.Removidos++
+		}
+		return nil
+	}
+
+	err := c.store.List(ctx, prefixoPDFs, func(info storage.Info) error {
+		relatorio.Verificados++
+		if info.ModificadoEm.After(limite) {
+			return nil
+		}
+		lote = append(lote, info)
+		if len(lote) == coletorTamanhoLote {
+			return processar()
+		}
+		return nil
+	})
+	if err == nil {
+		err = processar()
+	}
+	if err != nil {
+		return nil, err
+	}
+	relatorio.Fim = time.Now()
+	return relatorio, nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"propulse/email"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/logger"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	assuntoEmailPadrao = `Proposta: {{.Titulo}}`
+	corpoEmailPadrao   = `Olá, {{.NomeCliente}},
+
+Segue em anexo a proposta "{{.Titulo}}".
+{{- if .ValidoAte}}
+Ela é válida até {{.ValidoAte}}.
+{{- end}}
+{{- if .LinkAceite}}
+
+Você também pode visualizá-la e aceitá-la ou recusá-la online:
+{{.LinkAceite}}
+{{- end}}
+
+Atenciosamente.
+`
+)
+
+var ErrEnvioFalhou = errors.New("falha ao enviar o e-mail")
+
+// EnvioService envia o PDF da proposta por e-mail, registra cada tentativa e
+// marca a proposta como enviada quando o servidor aceita a mensagem.
+type EnvioService struct {
+	envios    repository.EnvioRepository
+	propostas PropostaService
+	links     LinkService
+	mailer    email.Mailer
+}
+
+func NewEnvioService(er repository.EnvioRepository, propostas PropostaService, links LinkService, mailer email.Mailer) EnvioService {
+	return EnvioService{
+		envios:    er,
+		propostas: propostas,
+		links:     links,
+		mailer:    mailer,
+	}
+}
+
+func parseTemplateEmail(nome string, texto string, padrao string) (*template.Template, error) {
+	if strings.TrimSpace(texto) == "" {
+		texto = padrao
+	}
+	t, err := template.New(nome).Option("missingkey=error").Parse(texto)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", model.ErrTemplateInvalido, err.Error())
+	}
+	return t, nil
+}
+
+func executarTemplateEmail(t *template.Template, dados model.DadosEmailProposta) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, dados); err != nil {
+		return "", fmt.Errorf("%w: %s", model.ErrTemplateInvalido, err.Error())
+	}
+	return b.String(), nil
+}
+
+// assuntoEmail executa o template do assunto, que é uma linha só: quebras
+// vindas do template viram espaço.
+func assuntoEmail(t *template.Template, dados model.DadosEmailProposta) (string, error) {
+	assunto, err := executarTemplateEmail(t, dados)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(assunto), " "), nil
+}
+
+// mensagemProposta monta o e-mail com o PDF da proposta anexado.
+func mensagemProposta(proposta *model.Proposta, input model.EnviarProposta, assunto string, corpo string, pdf io.Reader) email.Mensagem {
+	return email.Mensagem{
+		Para:    input.Para,
+		Cc:      input.Cc,
+		Assunto: assunto,
+		Corpo:   corpo,
+		Anexos: []email.Anexo{{
+			Nome:        proposta.NomeArquivoPDF(),
+			ContentType: "application/pdf",
+			Conteudo:    pdf,
+		}},
+	}
+}
+
+// EnviarProposta envia o PDF atual da proposta. A proposta precisa poder ir
+// para enviado (ou já estar enviada, no caso de um reenvio). A falha do
+// servidor SMTP fica registrada no envio e é devolvida como ErrEnvioFalhou.
+func (es *EnvioService) EnviarProposta(ctx context.Context, idParam string, input model.EnviarProposta, ator string) (*model.EnvioEmail, *model.Proposta, error) {
+	proposta, pdf, _, err := es.propostas.AbrirPDF(ctx, idParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer pdf.Close()
+	if proposta.Status != model.StatusEnviado {
+		if err := model.ValidarTransicao(proposta, model.StatusEnviado); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Os templates são validados antes de qualquer efeito colateral.
+	tAssunto, err := parseTemplateEmail("assunto", input.Assunto, assuntoEmailPadrao)
+	if err != nil {
+		return nil, nil, err
+	}
+	tCorpo, err := parseTemplateEmail("corpo", input.Corpo, corpoEmailPadrao)
+	if err != nil {
+		return nil, nil, err
+	}
+	dados := model.DadosEmailProposta{
+		Titulo:      proposta.Titulo,
+		NomeEmpresa: proposta.NomeEmpresa,
+		NomeCliente: proposta.NomeCliente,
+	}
+	if proposta.ValidoAte != nil {
+		dados.ValidoAte = proposta.ValidoAte.Format("02/01/2006")
+	}
+	assunto, err := assuntoEmail(tAssunto, dados)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if input.IncluirLink {
+		link, token, err := es.links.CriarLink(ctx, idParam, model.CriarLink{ValidadeHoras: validadeLinkEmail(proposta)})
+		if err != nil {
+			return nil, nil, err
+		}
+		dados.Link = es.links.URL(token)
+		dados.LinkAceite = dados.Link + "/aceite"
+		logger.Info("Link de compartilhamento criado para o e-mail", zap.String("link_id", link.Id.String()))
+	}
+	corpo, err := executarTemplateEmail(tCorpo, dados)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envio, err := es.envios.RegistrarEnvio(ctx, model.EnvioEmail{
+		PropostaId:      proposta.Id,
+		Para:            input.Para,
+		Cc:              input.Cc,
+		Assunto:         assunto,
+		DocumentoSha256: proposta.ArquivoSha256,
+		Ator:            ator,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A partir daqui o resultado precisa ser gravado mesmo que o cliente HTTP
+	// desista; o SMTPMailer tem o próprio timeout.
+	ctx = context.WithoutCancel(ctx)
+	messageID, err := es.mailer.Enviar(ctx, mensagemProposta(proposta, input, assunto, corpo, pdf))
+	if err != nil {
+		logger.Error("Erro ao enviar proposta por e-mail", err, zap.String("proposta_id", proposta.Id.String()))
+		if falha, errFalha := es.envios.FalharEnvio(ctx, envio.Id, err.Error()); errFalha == nil {
+			envio = falha
+		}
+		return envio, nil, fmt.Errorf("%w: %s", ErrEnvioFalhou, err.Error())
+	}
+	envio, err = es.envios.ConcluirEnvio(ctx, envio.Id, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Info("Proposta enviada por e-mail", zap.String("proposta_id", proposta.Id.String()), zap.String("message_id", messageID))
+
+	if proposta.Status == model.StatusEnviado {
+		return envio, proposta, nil
+	}
+	status := model.StatusEnviado
+	motivo := "enviada por e-mail para " + strings.Join(input.Para, ", ")
+	atualizada, err := es.propostas.UpdateProposta(ctx, proposta.Id, model.PropostaUpdate{
+		Status: &status,
+		Motivo: &motivo,
+		Ator:   ator,
+	})
+	if err != nil {
+		// O e-mail já saiu; a falha ao mudar o status não desfaz o envio.
+		logger.Error("E-mail enviado, mas não foi possível marcar a proposta como enviada", err, zap.String("proposta_id", proposta.Id.String()))
+		return envio, proposta, nil
+	}
+	return envio, atualizada, nil
+}
+
+// validadeLinkEmail faz o link do e-mail valer até o fim da validade da
+// proposta; sem validade, vale o padrão do LinkService.
+func validadeLinkEmail(proposta *model.Proposta) int {
+	if proposta.ValidoAte == nil {
+		return 0
+	}
+	horas := int(math.Ceil(time.Until(*proposta.ValidoAte).Hours()))
+	return min(max(horas, 1), 8760)
+}
+
+func (es *EnvioService) ListarEnvios(ctx context.Context, idParam string) ([]model.EnvioEmail, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	if _, err := es.propostas.FindByID(ctx, idParam); err != nil {
+		return nil, err
+	}
+	return es.envios.ListarEnvios(ctx, id)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"propulse/email"
+	"propulse/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvioPropostaComMemoriaMailer(t *testing.T) {
+	validoAte := time.Date(2025, 4, 30, 23, 59, 0, 0, time.UTC)
+	proposta := &model.Proposta{Titulo: "Proposta Comercial", NomeEmpresa: "Cliente S.A.", NomeCliente: "Maria", ValidoAte: &validoAte}
+	casos := []struct {
+		nome            string
+		input           model.EnviarProposta
+		link            string
+		assuntoEsperado string
+		corpoContem     []string
+		corpoNaoContem  []string
+	}{
+		{
+			nome:            "textos padrão sem link",
+			input:           model.EnviarProposta{Para: []string{"maria@cliente.com"}},
+			assuntoEsperado: "Proposta: Proposta Comercial",
+			corpoContem:     []string{"Olá, Maria,", `"Proposta Comercial"`, "válida até 30/04/2025"},
+			corpoNaoContem:  []string{"aceitá-la"},
+		},
+		{
+			nome:            "textos padrão com link de aceite",
+			input:           model.EnviarProposta{Para: []string{"maria@cliente.com"}, Cc: []string{"compras@cliente.com"}, IncluirLink: true},
+			link:            "https://propostas.exemplo.com/p/abc",
+			assuntoEsperado: "Proposta: Proposta Comercial",
+			corpoContem:     []string{"https://propostas.exemplo.com/p/abc/aceite"},
+		},
+		{
+			nome:            "templates próprios com quebra no assunto",
+			input:           model.EnviarProposta{Para: []string{"maria@cliente.com"}, Assunto: "{{.NomeEmpresa}}\r\nBcc: intruso@externo.com", Corpo: "Prezada {{.NomeCliente}}, segue {{.Titulo}}."},
+			assuntoEsperado: "Cliente S.A. Bcc: intruso@externo.com",
+			corpoContem:     []string{"Prezada Maria, segue Proposta Comercial."},
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			tAssunto, err := parseTemplateEmail("assunto", c.input.Assunto, assuntoEmailPadrao)
+			if err != nil {
+				t.Fatalf("template do assunto: %v", err)
+			}
+			tCorpo, err := parseTemplateEmail("corpo", c.input.Corpo, corpoEmailPadrao)
+			if err != nil {
+				t.Fatalf("template do corpo: %v", err)
+			}
+			dados := model.DadosEmailProposta{
+				Titulo:      proposta.Titulo,
+				NomeEmpresa: proposta.NomeEmpresa,
+				NomeCliente: proposta.NomeCliente,
+				ValidoAte:   proposta.ValidoAte.Format("02/01/2006"),
+			}
+			if c.link != "" {
+				dados.Link = c.link
+				dados.LinkAceite = c.link + "/aceite"
+			}
+			assunto, err := assuntoEmail(tAssunto, dados)
+			if err != nil {
+				t.Fatalf("assunto: %v", err)
+			}
+			corpo, err := executarTemplateEmail(tCorpo, dados)
+			if err != nil {
+				t.Fatalf("corpo: %v", err)
+			}
+
+			mailer := email.NewMemoriaMailer("Propulse <propostas@propulse.local>")
+			pdf := []byte("%PDF-1.7 proposta")
+			messageID, err := mailer.Enviar(context.Background(), mensagemProposta(proposta, c.input, assunto, corpo, bytes.NewReader(pdf)))
+			if err != nil {
+				t.Fatalf("Enviar: %v", err)
+			}
+
+			enviadas := mailer.Enviadas()
+			if len(enviadas) != 1 {
+				t.Fatalf("%d mensagens, esperado 1", len(enviadas))
+			}
+			enviada := enviadas[0]
+			if enviada.MessageID != messageID {
+				t.Errorf("Message-ID %q, esperado %q", enviada.MessageID, messageID)
+			}
+			if enviada.Assunto != c.assuntoEsperado {
+				t.Errorf("assunto %q, esperado %q", enviada.Assunto, c.assuntoEsperado)
+			}
+			if esperados := len(c.input.Para) + len(c.input.Cc); len(enviada.Destinatarios) != esperados {
+				t.Errorf("destinatários %v", enviada.Destinatarios)
+			}
+			for _, trecho := range c.corpoContem {
+				if !strings.Contains(corpo, trecho) {
+					t.Errorf("corpo sem %q:\n%s", trecho, corpo)
+				}
+			}
+			for _, trecho := range c.corpoNaoContem {
+				if strings.Contains(corpo, trecho) {
+					t.Errorf("corpo com %q:\n%s", trecho, corpo)
+				}
+			}
+			dadosMIME := string(enviada.Dados)
+			if !strings.Contains(dadosMIME, `filename="Proposta Comercial - Maria.pdf"`) {
+				t.Errorf("anexo sem o nome do PDF da proposta")
+			}
+			if strings.Contains(dadosMIME, "\r\nBcc:") {
+				t.Errorf("cabeçalho Bcc injetado na mensagem")
+			}
+		})
+	}
+}
+
+func TestEnvioPropostaMemoriaMailerRecusaCRLF(t *testing.T) {
+	mailer := email.NewMemoriaMailer("Propulse <propostas@propulse.local>")
+	input := model.EnviarProposta{Para: []string{"maria@cliente.com\r\nBcc: intruso@externo.com"}}
+	_, err := mailer.Enviar(context.Background(), mensagemProposta(&model.Proposta{Titulo: "Proposta"}, input, "Proposta", "corpo", strings.NewReader("")))
+	if !errors.Is(err, email.ErrEnderecoInvalido) {
+		t.Errorf("erro %v, esperado %v", err, email.ErrEnderecoInvalido)
+	}
+	if len(mailer.Enviadas()) != 0 {
+		t.Errorf("mensagem guardada com destinatário inválido")
+	}
+}
+
+func TestTemplateEmailInvalido(t *testing.T) {
+	if _, err := parseTemplateEmail("assunto", "{{.Titulo", assuntoEmailPadrao); !errors.Is(err, model.ErrTemplateInvalido) {
+		t.Errorf("template com sintaxe inválida: erro %v", err)
+	}
+	tCorpo, err := parseTemplateEmail("corpo", "{{.CampoInexistente}}", corpoEmailPadrao)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := executarTemplateEmail(tCorpo, model.DadosEmailProposta{}); !errors.Is(err, model.ErrTemplateInvalido) {
+		t.Errorf("campo inexistente: erro %v", err)
+	}
+}
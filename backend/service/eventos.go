@@ -0,0 +1,65 @@
+package service
+
+import (
+	"propulse/model"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventoBroker distribui os eventos de geração para os clientes SSE conectados
+// nesta instância do backend, agrupados por proposta. Os eventos ficam em
+// memória: com várias réplicas, um cliente só recebe os eventos dos jobs
+// processados pela réplica em que está conectado.
+type EventoBroker struct {
+	mu         sync.Mutex
+	assinantes map[uuid.UUID]map[chan model.EventoGeracao]struct{}
+}
+
+func NewEventoBroker() *EventoBroker {
+	return &EventoBroker{
+		assinantes: make(map[uuid.UUID]map[chan model.EventoGeracao]struct{}),
+	}
+}
+
+// Assinar retorna o canal de eventos da proposta e a função que encerra a assinatura.
+func (b *EventoBroker) Assinar(propostaID uuid.UUID) (<-chan model.EventoGeracao, func()) {
+	ch := make(chan model.EventoGeracao, 16)
+
+	b.mu.Lock()
+	if b.assinantes[propostaID] == nil {
+		b.assinantes[propostaID] = make(map[chan model.EventoGeracao]struct{})
+	}
+	b.assinantes[propostaID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	cancelar := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.assinantes[propostaID][ch]; !ok {
+			return
+		}
+		delete(b.assinantes[propostaID], ch)
+		if len(b.assinantes[propostaID]) == 0 {
+			delete(b.assinantes, propostaID)
+		}
+		close(ch)
+	}
+	return ch, cancelar
+}
+
+// Publicar nunca bloqueia o worker: assinantes lentos com o buffer cheio perdem o evento.
+func (b *EventoBroker) Publicar(evento model.EventoGeracao) {
+	if evento.Data.IsZero() {
+		evento.Data = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.assinantes[evento.PropostaId] {
+		select {
+		case ch <- evento:
+		default:
+		}
+	}
+}
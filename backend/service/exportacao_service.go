@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	FormatoCSV  = "csv"
+	FormatoXLSX = "xlsx"
+)
+
+var ErrFormatoExportacao = errors.New("formato de exportação inválido (use csv ou xlsx)")
+
+// ContentTypesExportacao mapeia cada formato aceito para o Content-Type da resposta.
+var ContentTypesExportacao = map[string]string{
+	FormatoCSV:  "text/csv; charset=utf-8",
+	FormatoXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// As datas saem em UTC, já que o servidor não conhece o fuso de quem abre a planilha.
+var cabecalhoExportacao = []string{"ID", "Título", "Empresa", "Cliente", "Status", "Criada em (UTC)", "Atualizada em (UTC)", "Válida até (UTC)", "PDF"}
+
+const (
+	// bomUTF8 faz o Excel reconhecer o CSV como UTF-8; sem ele os acentos
+	// aparecem corrompidos.
+	bomUTF8 = "\uFEFF"
+	// O Excel em português usa ";" como separador, pois "," é o separador decimal.
+	separadorCSV = ';'
+	// linhasPorFlush controla de quanto em quanto o CSV é enviado ao cliente.
+	linhasPorFlush = 500
+	formatoDataCSV = "2006-01-02 15:04:05"
+)
+
+// ExportacaoService gera a planilha da listagem de propostas, escrevendo as
+// linhas à medida que são lidas do banco.
+type ExportacaoService struct {
+	repository repository.PropostaRepository
+	urlPublica string
+}
+
+func NewExportacaoService(pr repository.PropostaRepository) ExportacaoService {
+	return ExportacaoService{
+		repository: pr,
+		urlPublica: strings.TrimSuffix(env.String("URL_PUBLICA", ""), "/"),
+	}
+}
+
+type escritorPlanilha interface {
+	escreverLinha(p *model.Proposta, linkPDF string) error
+	fechar() error
+	// descartar libera os recursos quando a exportação é interrompida.
+	descartar()
+}
+
+// Exportar escreve em w as propostas que atendem aos filtros da listagem, na
+// ordem do sort; limit, offset e cursor são ignorados. Nada chega a w antes
+// da primeira leitura do banco, então um erro de consulta ainda pode ser
+// respondido normalmente.
+func (es *ExportacaoService) Exportar(ctx context.Context, filtro model.FiltroPropostas, formato string, w io.Writer) error {
+	filtro.Normalizar()
+	criacao, err := filtro.Criacao()
+	if err != nil {
+		return err
+	}
+	atualizacao, err := filtro.Atualizacao()
+	if err != nil {
+		return err
+	}
+
+	var escritor escritorPlanilha
+	switch formato {
+	case FormatoCSV:
+		escritor, err = novoEscritorCSV(w)
+	case FormatoXLSX:
+		escritor, err = novoEscritorXLSX(w)
+	default:
+		return ErrFormatoExportacao
+	}
+	if err != nil {
+		return err
+	}
+
+	linhas := 0
+	err = es.repository.PercorrerPropostas(ctx, filtro, criacao, atualizacao, func(p *model.Proposta) error {
+		linhas++
+		return escritor.escreverLinha(p, es.linkPDF(p))
+	})
+	if err != nil {
+		escritor.descartar()
+		logger.Error("Erro ao exportar propostas", err, zap.String("formato", formato), zap.Int("linhas", linhas))
+		return err
+	}
+	if err := escritor.fechar(); err != nil {
+		logger.Error("Erro ao finalizar exportação", err, zap.String("formato", formato))
+		return err
+	}
+	logger.Info("Propostas exportadas", zap.String("formato", formato), zap.Int("linhas", linhas))
+	return nil
+}
+
+// linkPDF aponta para o download autenticado do PDF atual, quando existe.
+func (es *ExportacaoService) linkPDF(p *model.Proposta) string {
+	if p.ArquivoFinal == "" {
+		return ""
+	}
+	return es.urlPublica + "/proposta/" + p.Id.String() + "/pdf"
+}
+
+type escritorCSV struct {
+	buffer *bufio.Writer
+	csv    *csv.Writer
+	linhas int
+}
+
+func novoEscritorCSV(w io.Writer) (*escritorCSV, error) {
+	buffer := bufio.NewWriterSize(w, 32*1024)
+	if _, err := buffer.WriteString(bomUTF8); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(buffer)
+	cw.Comma = separadorCSV
+	cw.UseCRLF = true
+	if err := cw.Write(cabecalhoExportacao); err != nil {
+		return nil, err
+	}
+	return &escritorCSV{buffer: buffer, csv: cw}, nil
+}
+
+func (e *escritorCSV) escreverLinha(p *model.Proposta, linkPDF string) error {
+	validoAte := ""
+	if p.ValidoAte != nil {
+		validoAte = p.ValidoAte.UTC().Format(formatoDataCSV)
+	}
+	err := e.csv.Write([]string{
+		p.Id.String(),
+		celulaCSV(p.Titulo),
+		celulaCSV(p.NomeEmpresa),
+		celulaCSV(p.NomeCliente),
+		p.Status,
+		p.DataCriacao.UTC().Format(formatoDataCSV),
+		p.LastUpdate.UTC().Format(formatoDataCSV),
+		validoAte,
+		linkPDF,
+	})
+	if err != nil {
+		return err
+	}
+	e.linhas++
+	if e.linhas%linhasPorFlush == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *escritorCSV) flush() error {
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.buffer.Flush()
+}
+
+func (e *escritorCSV) fechar() error {
+	return e.flush()
+}
+
+func (e *escritorCSV) descartar() {}
+
+// celulaCSV impede que textos digitados pelo usuário sejam interpretados como
+// fórmula pelo Excel (CSV injection).
+func celulaCSV(valor string) string {
+	if valor != "" && strings.ContainsRune("=+-@\t\r", rune(valor[0])) {
+		return "'" + valor
+	}
+	return valor
+}
+
+// escritorXLSX usa o StreamWriter do excelize, que guarda as linhas em arquivo
+// temporário em vez de manter a planilha inteira em memória. O XLSX é um ZIP,
+// então só é enviado ao cliente em fechar.
+type escritorXLSX struct {
+	w          io.Writer
+	arquivo    *excelize.File
+	stream     *excelize.StreamWriter
+	estiloData int
+	linha      int
+}
+
+const abaExportacao = "Propostas"
+
+func novoEscritorXLSX(w io.Writer) (*escritorXLSX, error) {
+	arquivo := excelize.NewFile()
+	if err := arquivo.SetSheetName(arquivo.GetSheetName(0), abaExportacao); err != nil {
+		arquivo.Close()
+		return nil, err
+	}
+	estiloCabecalho, err := arquivo.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		arquivo.Close()
+		return nil, err
+	}
+	formatoData := "dd/mm/yyyy hh:mm"
+	estiloData, err := arquivo.NewStyle(&excelize.Style{CustomNumFmt: &formatoData})
+	if err != nil {
+		arquivo.Close()
+		return nil, err
+	}
+	stream, err := arquivo.NewStreamWriter(abaExportacao)
+	if err != nil {
+		arquivo.Close()
+		return nil, err
+	}
+	larguras := []float64{38, 40, 30, 30, 12, 18, 18, 18, 60}
+	for i, largura := range larguras {
+		if err := stream.SetColWidth(i+1, i+1, largura); err != nil {
+			arquivo.Close()
+			return nil, err
+		}
+	}
+	cabecalho := make([]any, len(cabecalhoExportacao))
+	for i, titulo := range cabecalhoExportacao {
+		cabecalho[i] = excelize.Cell{StyleID: estiloCabecalho, Value: titulo}
+	}
+	if err := stream.SetRow("A1", cabecalho, excelize.RowOpts{}); err != nil {
+		arquivo.Close()
+		return nil, err
+	}
+	return &escritorXLSX{w: w, arquivo: arquivo, stream: stream, estiloData: estiloData, linha: 1}, nil
+}
+
+func (e *escritorXLSX) data(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return excelize.Cell{StyleID: e.estiloData, Value: t.UTC()}
+}
+
+func (e *escritorXLSX) escreverLinha(p *model.Proposta, linkPDF string) error {
+	e.linha++
+	celula, err := excelize.CoordinatesToCellName(1, e.linha)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(celula, []any{
+		p.Id.String(),
+		p.Titulo,
+		p.NomeEmpresa,
+		p.NomeCliente,
+		p.Status,
+		e.data(&p.DataCriacao),
+		e.data(&p.LastUpdate),
+		e.data(p.ValidoAte),
+		linkPDF,
+	})
+}
+
+func (e *escritorXLSX) fechar() error {
+	defer e.arquivo.Close()
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := e.arquivo.WriteTo(e.w)
+	return err
+}
+
+func (e *escritorXLSX) descartar() {
+	e.arquivo.Close()
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"propulse/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCelulaCSV(t *testing.T) {
+	casos := []struct {
+		valor    string
+		esperado string
+	}{
+		{"=SOMA(A1:A9)", "'=SOMA(A1:A9)"},
+		{`=HYPERLINK("http://malicioso","clique")`, `'=HYPERLINK("http://malicioso","clique")`},
+		{"+55 11 99999-0000", "'+55 11 99999-0000"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(1)", "'@SUM(1)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"Proposta = boa", "Proposta = boa"},
+		{"Empresa-X", "Empresa-X"},
+		{"São Paulo", "São Paulo"},
+		{"", ""},
+	}
+	for _, c := range casos {
+		if got := celulaCSV(c.valor); got != c.esperado {
+			t.Errorf("celulaCSV(%q) = %q, esperado %q", c.valor, got, c.esperado)
+		}
+	}
+}
+
+func propostaExportacao(titulo string) *model.Proposta {
+	fuso := time.FixedZone("BRT", -3*60*60)
+	validoAte := time.Date(2025, 4, 30, 21, 0, 0, 0, fuso)
+	return &model.Proposta{
+		Id:          uuid.MustParse("7b0c7f4e-1d2a-4c3b-9e8f-0a1b2c3d4e5f"),
+		Titulo:      titulo,
+		NomeEmpresa: "Ação & Cia; Ltda",
+		NomeCliente: "@Maria",
+		Status:      model.StatusEnviado,
+		DataCriacao: time.Date(2025, 3, 31, 22, 30, 0, 0, fuso),
+		LastUpdate:  time.Date(2025, 4, 1, 8, 15, 30, 0, time.UTC),
+		ValidoAte:   &validoAte,
+	}
+}
+
+func TestEscritorCSV(t *testing.T) {
+	var saida bytes.Buffer
+	escritor, err := novoEscritorCSV(&saida)
+	if err != nil {
+		t.Fatalf("novoEscritorCSV: %v", err)
+	}
+	titulos := []string{"=1+1", "Proposta \"Premium\"\ncom quebra", "-10% de desconto"}
+	for _, titulo := range titulos {
+		if err := escritor.escreverLinha(propostaExportacao(titulo), "https://api.exemplo.com/proposta/x/pdf"); err != nil {
+			t.Fatalf("escreverLinha: %v", err)
+		}
+	}
+	semPDF := propostaExportacao("Sem PDF")
+	semPDF.ValidoAte = nil
+	if err := escritor.escreverLinha(semPDF, ""); err != nil {
+		t.Fatalf("escreverLinha: %v", err)
+	}
+	if err := escritor.fechar(); err != nil {
+		t.Fatalf("fechar: %v", err)
+	}
+
+	texto := saida.String()
+	if !strings.HasPrefix(texto, bomUTF8) {
+		t.Fatalf("CSV sem BOM UTF-8")
+	}
+	if !strings.Contains(texto, "\r\n") {
+		t.Errorf("CSV sem quebras CRLF")
+	}
+	leitor := csv.NewReader(strings.NewReader(strings.TrimPrefix(texto, bomUTF8)))
+	leitor.Comma = separadorCSV
+	linhas, err := leitor.ReadAll()
+	if err != nil {
+		t.Fatalf("CSV ilegível: %v", err)
+	}
+	if len(linhas) != 5 {
+		t.Fatalf("%d linhas, esperado cabeçalho e 4 propostas", len(linhas))
+	}
+	if strings.Join(linhas[0], ";") != strings.Join(cabecalhoExportacao, ";") {
+		t.Errorf("cabeçalho %v", linhas[0])
+	}
+
+	esperadas := [][]string{
+		{"7b0c7f4e-1d2a-4c3b-9e8f-0a1b2c3d4e5f", "'=1+1", "Ação & Cia; Ltda", "'@Maria", "enviado", "2025-04-01 01:30:00", "2025-04-01 08:15:30", "2025-05-01 00:00:00", "https://api.exemplo.com/proposta/x/pdf"},
+		{"7b0c7f4e-1d2a-4c3b-9e8f-0a1b2c3d4e5f", "Proposta \"Premium\"\ncom quebra", "Ação & Cia; Ltda", "'@Maria", "enviado", "2025-04-01 01:30:00", "2025-04-01 08:15:30", "2025-05-01 00:00:00", "https://api.exemplo.com/proposta/x/pdf"},
+		{"7b0c7f4e-1d2a-4c3b-9e8f-0a1b2c3d4e5f", "'-10% de desconto", "Ação & Cia; Ltda", "'@Maria", "enviado", "2025-04-01 01:30:00", "2025-04-01 08:15:30", "2025-05-01 00:00:00", "https://api.exemplo.com/proposta/x/pdf"},
+		{"7b0c7f4e-1d2a-4c3b-9e8f-0a1b2c3d4e5f", "Sem PDF", "Ação & Cia; Ltda", "'@Maria", "enviado", "2025-04-01 01:30:00", "2025-04-01 08:15:30", "", ""},
+	}
+	for n, esperada := range esperadas {
+		linha := linhas[n+1]
+		for coluna := range esperada {
+			if linha[coluna] != esperada[coluna] {
+				t.Errorf("linha %d, coluna %q: %q, esperado %q", n+1, cabecalhoExportacao[coluna], linha[coluna], esperada[coluna])
+			}
+		}
+	}
+}
+
+func TestEscritorXLSX(t *testing.T) {
+	var saida bytes.Buffer
+	escritor, err := novoEscritorXLSX(&saida)
+	if err != nil {
+		t.Fatalf("novoEscritorXLSX: %v", err)
+	}
+	if err := escritor.escreverLinha(propostaExportacao("=1+1"), "https://api.exemplo.com/proposta/x/pdf"); err != nil {
+		t.Fatalf("escreverLinha: %v", err)
+	}
+	semValidade := propostaExportacao("Sem validade")
+	semValidade.ValidoAte = nil
+	if err := escritor.escreverLinha(semValidade, ""); err != nil {
+		t.Fatalf("escreverLinha: %v", err)
+	}
+	if err := escritor.fechar(); err != nil {
+		t.Fatalf("fechar: %v", err)
+	}
+
+	arquivo, err := excelize.OpenReader(&saida)
+	if err != nil {
+		t.Fatalf("XLSX inválido: %v", err)
+	}
+	defer arquivo.Close()
+	linhas, err := arquivo.GetRows(abaExportacao, excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+	if len(linhas) != 3 {
+		t.Fatalf("%d linhas, esperado cabeçalho e 2 propostas", len(linhas))
+	}
+	if strings.Join(linhas[0], ";") != strings.Join(cabecalhoExportacao, ";") {
+		t.Errorf("cabeçalho %v", linhas[0])
+	}
+	// No XLSX o texto vai como string, nunca como fórmula, então não precisa do apóstrofo.
+	if titulo, _ := arquivo.GetCellValue(abaExportacao, "B2"); titulo != "=1+1" {
+		t.Errorf("título %q", titulo)
+	}
+	if formula, _ := arquivo.GetCellFormula(abaExportacao, "B2"); formula != "" {
+		t.Errorf("título gravado como fórmula %q", formula)
+	}
+	if nome, _ := arquivo.GetCellValue(abaExportacao, "D2"); nome != "@Maria" {
+		t.Errorf("cliente %q", nome)
+	}
+
+	criada, err := arquivo.GetCellValue(abaExportacao, "F2")
+	if err != nil || criada != "01/04/2025 01:30" {
+		t.Errorf("data de criação %q (%v), esperado em UTC", criada, err)
+	}
+	if tipo, _ := arquivo.GetCellType(abaExportacao, "F2"); tipo == excelize.CellTypeSharedString || tipo == excelize.CellTypeInlineString {
+		t.Errorf("data gravada como texto")
+	}
+	if validade, _ := arquivo.GetCellValue(abaExportacao, "H3"); validade != "" {
+		t.Errorf("validade %q, esperado vazia", validade)
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"propulse/model"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var fakeHTMLTemplate = template.Must(template.New("proposta").Funcs(template.FuncMap{
+	"brl":    model.FormatarBRL,
+	"numero": model.FormatarNumero,
+}).Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="UTF-8">
+<title>{{.Titulo}}</title>
+<style>
+body { font-family: {{if .Marca}}{{with .Marca.Fonte}}{{.}}, {{end}}{{end}}sans-serif; margin: 40px; }
+h1 { color: {{.CorPrimaria}}; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; }
+</style>
+</head>
+<body>
+<h1>{{.Titulo}}</h1>
+<p><strong>Empresa:</strong> {{.NomeEmpresa}}</p>
+<p><strong>Contato:</strong> {{.NomeCliente}}</p>
+<h2>Escopo</h2>
+<p>{{.Prompt}}</p>
+{{- if .Totais}}
+<h2>Investimento</h2>
+<table>
+<tr><th>Descrição</th><th>Quantidade</th><th>Preço unitário</th><th>Desconto</th><th>Imposto</th><th>Total</th></tr>
+{{- range .Itens}}
+<tr><td>{{.Descricao}}</td><td>{{numero .Quantidade}} {{.Unidade}}</td><td>{{brl .PrecoUnitario}}</td><td>{{numero .Desconto}}%</td><td>{{numero .Imposto}}%</td><td>{{brl .Total}}</td></tr>
+{{- end}}
+<tr><td colspan="5">Subtotal</td><td>{{brl .Totais.Subtotal}}</td></tr>
+<tr><td colspan="5">Descontos</td><td>{{brl .Totais.Descontos}}</td></tr>
+<tr><td colspan="5">Impostos</td><td>{{brl .Totais.Impostos}}</td></tr>
+<tr><td colspan="5"><strong>Total</strong></td><td><strong>{{brl .Totais.Total}}</strong></td></tr>
+</table>
+{{- end}}
+{{- with .Marca}}
+<footer>
+{{- if .Rodape}}<p>{{.Rodape}}</p>{{end}}
+{{- with .Contato.Linha}}<p>{{.}}</p>{{end}}
+</footer>
+{{- end}}
+</body>
+</html>
+`))
+
+// FakeGenerator gera um HTML simples e um PDF mínimo a partir dos dados da
+// proposta, sem depender do ia-service. A saída é determinística, o que o
+// torna útil para desenvolvimento local e testes.
+type FakeGenerator struct{}
+
+func NewFakeGenerator() *FakeGenerator {
+	return &FakeGenerator{}
+}
+
+func (g *FakeGenerator) Gerar(ctx context.Context, proposta model.Proposta) (*ResultadoGeracao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	corPrimaria := "#333333"
+	if len(proposta.Cores) > 0 {
+		corPrimaria = proposta.Cores[0]
+	}
+
+	var html bytes.Buffer
+	err := fakeHTMLTemplate.Execute(&html, struct {
+		model.Proposta
+		CorPrimaria template.CSS
+	}{proposta, template.CSS(corPrimaria)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultadoGeracao{
+		Html: html.String(),
+		PDF:  io.NopCloser(bytes.NewReader(gerarPDFSimples(linhasProposta(proposta)))),
+	}, nil
+}
+
+// AnexarCertificado recria o PDF da proposta com a página de certificado no
+// fim. Como a saída do FakeGenerator é determinística, a página da proposta
+// sai idêntica à original, então o PDF recebido não precisa ser lido.
+func (g *FakeGenerator) AnexarCertificado(ctx context.Context, proposta model.Proposta, pdf io.Reader, aceite model.Aceite) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(gerarPDFSimples(linhasProposta(proposta), linhasCertificado(proposta, aceite)))), nil
+}
+
+func linhasProposta(proposta model.Proposta) []string {
+	linhas := []string{
+		proposta.Titulo,
+		"",
+		"Empresa: " + proposta.NomeEmpresa,
+		"Contato: " + proposta.NomeCliente,
+	}
+	if proposta.ValidoAte != nil {
+		linhas = append(linhas, "Válida até: "+proposta.ValidoAte.Format("02/01/2006"))
+	}
+	linhas = append(linhas, "")
+	linhas = append(linhas, quebrarLinhas(proposta.Prompt, 90)...)
+	if proposta.Totais != nil {
+		linhas = append(linhas, "", "Investimento:")
+		for _, item := range proposta.Itens {
+			linhas = append(linhas, fmt.Sprintf("- %s: %s %s x %s = %s",
+				item.Descricao, model.FormatarNumero(item.Quantidade), item.Unidade,
+				model.FormatarBRL(item.PrecoUnitario), model.FormatarBRL(item.Total)))
+		}
+		linhas = append(linhas, "Total: "+model.FormatarBRL(proposta.Totais.Total))
+	}
+	return linhas
+}
+
+func quebrarLinhas(texto string, largura int) []string {
+	var linhas []string
+	for _, paragrafo := range strings.Split(texto, "\n") {
+		atual := ""
+		for _, palavra := range strings.Fields(paragrafo) {
+			if atual != "" && utf8.RuneCountInString(atual)+1+utf8.RuneCountInString(palavra) > largura {
+				linhas = append(linhas, atual)
+				atual = palavra
+				continue
+			}
+			if atual != "" {
+				atual += " "
+			}
+			atual += palavra
+		}
+		linhas = append(linhas, atual)
+	}
+	return linhas
+}
+
+// gerarPDFSimples monta um PDF A4 com uma página por slice de linhas, em
+// Helvetica. O texto é codificado em WinAnsi para preservar os acentos do português.
+func gerarPDFSimples(paginas ...[]string) []byte {
+	objetos := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // árvore de páginas, preenchida quando os ids das páginas forem conhecidos
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+	kids := []string{}
+	for _, linhas := range paginas {
+		var conteudo bytes.Buffer
+		conteudo.WriteString("BT\n/F1 11 Tf\n14 TL\n50 790 Td\n")
+		for i, linha := range linhas {
+			if i >= 52 {
+				break
+			}
+			conteudo.WriteString("(")
+			conteudo.WriteString(escaparTextoPDF(linha))
+			conteudo.WriteString(") Tj T*\n")
+		}
+		conteudo.WriteString("ET\n")
+
+		idPagina := len(objetos) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", idPagina))
+		objetos = append(objetos,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", idPagina+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", conteudo.Len(), conteudo.String()),
+		)
+	}
+	objetos[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objetos))
+	for i, obj := range objetos {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objetos)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objetos)+1, xref)
+	return pdf.Bytes()
+}
+
+func escaparTextoPDF(texto string) string {
+	var b strings.Builder
+	for _, r := range texto {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"propulse/model"
+	"propulse/shared/env"
+	"strings"
+)
+
+const (
+	GeradorIA   = "ia"
+	GeradorFake = "fake"
+)
+
+// ResultadoGeracao traz o PDF como leitor para que ele siga em streaming até o
+// storage sem ser carregado inteiro em memória. Quem recebe deve fechar PDF.
+type ResultadoGeracao struct {
+	Html string
+	PDF  io.ReadCloser
+}
+
+// Generator produz o HTML e o PDF de uma proposta. A implementação usada é
+// escolhida pela variável GERADOR (ia ou fake).
+type Generator interface {
+	Gerar(ctx context.Context, proposta model.Proposta) (*ResultadoGeracao, error)
+	// AnexarCertificado devolve o PDF informado com a página de certificado do
+	// aceite no final. Quem recebe deve fechar o leitor.
+	AnexarCertificado(ctx context.Context, proposta model.Proposta, pdf io.Reader, aceite model.Aceite) (io.ReadCloser, error)
+}
+
+func NewGenerator(ia *IAClient) (Generator, error) {
+	nome := strings.ToLower(env.String("GERADOR", GeradorIA))
+	switch nome {
+	case GeradorIA:
+		return NewIAGenerator(ia), nil
+	case GeradorFake:
+		return NewFakeGenerator(), nil
+	default:
+		return nil, fmt.Errorf("gerador desconhecido: %s (use %q ou %q)", nome, GeradorIA, GeradorFake)
+	}
+}
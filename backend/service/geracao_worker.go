@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GeracaoWorker consome a fila geracao_jobs com um pool de goroutines. Como a
+// reserva usa FOR UPDATE SKIP LOCKED, várias réplicas podem rodar ao mesmo tempo.
+type GeracaoWorker struct {
+	propostaService PropostaService
+	jobs            repository.GeracaoJobRepository
+	workers         int
+	intervalo       time.Duration
+	lease           time.Duration
+	backoffBase     time.Duration
+	wg              sync.WaitGroup
+}
+
+var ErrLeaseGeracaoCurto = errors.New("GERACAO_LEASE precisa ser maior que GERACAO_TIMEOUT")
+
+// margemLease é a folga exigida entre GERACAO_TIMEOUT e GERACAO_LEASE, que
+// cobre o registro do resultado depois do fim do prazo da geração.
+const margemLease = 30 * time.Second
+
+func NewGeracaoWorker(ps PropostaService, jr repository.GeracaoJobRepository) (*GeracaoWorker, error) {
+	w := &GeracaoWorker{
+		propostaService: ps,
+		jobs:            jr,
+		workers:         env.IntMinimo("GERACAO_WORKERS", 2, 1),
+		intervalo:       env.Duration("GERACAO_INTERVALO", 2*time.Second),
+		lease:           env.Duration("GERACAO_LEASE", 10*time.Minute),
+		backoffBase:     env.Duration("GERACAO_BACKOFF", 30*time.Second),
+	}
+	if err := validarLease(w.lease, geracaoTimeout); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// validarLease recusa um lease que possa vencer com a geração ainda em
+// andamento. O heartbeat renova o lease, mas se ele falhar o job só pode ser
+// reservado de novo depois que o prazo da geração certamente acabou.
+func validarLease(lease time.Duration, timeout time.Duration) error {
+	if lease <= timeout+margemLease {
+		return fmt.Errorf("%w mais %s: lease %s, timeout %s", ErrLeaseGeracaoCurto, margemLease, lease, timeout)
+	}
+	return nil
+}
+
+func (w *GeracaoWorker) Start(ctx context.Context) {
+	logger.Info("Iniciando workers de geração", zap.Int("workers", w.workers))
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go w.loop(ctx, i)
+	}
+}
+
+// Wait bloqueia até que todos os workers tenham encerrado após o cancelamento do contexto.
+func (w *GeracaoWorker) Wait() {
+	w.wg.Wait()
+}
+
+func (w *GeracaoWorker) loop(ctx context.Context, numero int) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.intervalo)
+	defer ticker.Stop()
+
+	for {
+		w.encerrarEsgotados(ctx)
+		// Drena a fila enquanto houver jobs prontos antes de voltar a esperar.
+		for ctx.Err() == nil {
+			job, err := w.jobs.ClaimProximoJob(ctx, w.lease)
+			if err != nil || job == nil {
+				break
+			}
+			w.processar(ctx, job, numero)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Worker de geração finalizado", zap.Int("worker", numero))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *GeracaoWorker) processar(ctx context.Context, job *model.GeracaoJob, numero int) {
+	tags := []zap.Field{
+		zap.Int("worker", numero),
+		zap.String("job_id", job.Id.String()),
+		zap.String("proposta_id", job.PropostaId.String()),
+		zap.Int("tentativa", job.Tentativas),
+	}
+	logger.Info("Processando job de geração", tags...)
+
+	// O registro do resultado precisa chegar ao banco mesmo durante o desligamento.
+	bookkeepingCtx := context.WithoutCancel(ctx)
+
+	pararHeartbeat := w.manterLease(ctx, job.Id)
+	_, err := w.propostaService.ProcessarGeracao(ctx, job)
+	pararHeartbeat()
+	if err == nil {
+		// Sem o sucesso registrado o job volta para a fila depois do lease,
+		// mas a nova tentativa encontra o PDF já gravado e não gera de novo.
+		if errSucesso := w.jobs.MarcarSucesso(bookkeepingCtx, job.Id); errSucesso != nil {
+			logger.Error("Não foi possível registrar o sucesso do job de geração", errSucesso, tags...)
+			return
+		}
+		logger.Info("Job de geração concluído", tags...)
+		return
+	}
+
+	if ctx.Err() != nil {
+		logger.Info("Job de geração interrompido pelo desligamento, devolvendo para a fila", tags...)
+		if errLiberar := w.jobs.LiberarJob(bookkeepingCtx, job.Id); errLiberar != nil {
+			logger.Error("Não foi possível devolver o job de geração para a fila, ele volta depois do lease", errLiberar, tags...)
+		}
+		return
+	}
+
+	logger.Error("Falha no job de geração", err, tags...)
+	var proximaExecucao *time.Time
+	if job.Tentativas < job.MaxTentativas {
+		quando := time.Now().Add(esperaNovaTentativa(w.backoffBase, job.Tentativas))
+		proximaExecucao = &quando
+	}
+	if errFalha := w.jobs.MarcarFalha(bookkeepingCtx, job.Id, err.Error(), proximaExecucao); errFalha != nil {
+		logger.Error("Não foi possível registrar a falha do job de geração", errFalha, tags...)
+		return
+	}
+	w.propostaService.publicarFalha(job, err.Error(), proximaExecucao == nil)
+	if proximaExecucao != nil {
+		w.propostaService.publicarEvento(job, model.EventoQueued, "nova tentativa em "+proximaExecucao.Format(time.RFC3339))
+	}
+}
+
+// manterLease renova o lease do job a cada quarto de GERACAO_LEASE enquanto a
+// geração roda, para que outro worker ou réplica não o reserve de novo. A
+// função devolvida para a renovação e espera a goroutine terminar.
+func (w *GeracaoWorker) manterLease(ctx context.Context, jobID uuid.UUID) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	terminou := make(chan struct{})
+	go func() {
+		defer close(terminou)
+		ticker := time.NewTicker(w.lease / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.jobs.RenovarLease(ctx, jobID)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-terminou
+	}
+}
+
+// encerrarEsgotados encerra os jobs cujo worker caiu durante a última
+// tentativa, que não voltam mais para a fila.
+func (w *GeracaoWorker) encerrarEsgotados(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	const erro = "lease expirado na última tentativa"
+	jobs, err := w.jobs.EncerrarJobsEsgotados(ctx, w.lease, erro)
+	if err != nil {
+		return
+	}
+	for n := range jobs {
+		job := &jobs[n]
+		logger.Info("Job de geração abandonado encerrado sem novas tentativas",
+			zap.String("job_id", job.Id.String()),
+			zap.String("proposta_id", job.PropostaId.String()),
+			zap.Int("tentativa", job.Tentativas),
+		)
+		w.propostaService.publicarFalha(job, erro, true)
+	}
+}
+
+// esperaNovaTentativa é o backoff exponencial entre as tentativas de um job:
+// base depois da primeira, o dobro depois da segunda e assim por diante.
+func esperaNovaTentativa(base time.Duration, tentativa int) time.Duration {
+	return time.Duration(float64(base) * math.Pow(2, float64(tentativa-1)))
+}
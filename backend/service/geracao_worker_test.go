@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"propulse/repository"
+	"testing"
+	"time"
+)
+
+func TestEsperaNovaTentativa(t *testing.T) {
+	casos := []struct {
+		tentativa int
+		esperado  time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+	}
+	for _, c := range casos {
+		if obtido := esperaNovaTentativa(30*time.Second, c.tentativa); obtido != c.esperado {
+			t.Errorf("esperaNovaTentativa(30s, %d) = %s, esperado %s", c.tentativa, obtido, c.esperado)
+		}
+	}
+}
+
+func TestValidarLease(t *testing.T) {
+	casos := []struct {
+		nome    string
+		lease   time.Duration
+		timeout time.Duration
+		valido  bool
+	}{
+		{"padrão", 10 * time.Minute, 5 * time.Minute, true},
+		{"lease igual ao timeout", 5 * time.Minute, 5 * time.Minute, false},
+		{"lease menor que o timeout", time.Minute, 5 * time.Minute, false},
+		{"sem a margem", 5*time.Minute + margemLease, 5 * time.Minute, false},
+		{"logo acima da margem", 5*time.Minute + margemLease + time.Second, 5 * time.Minute, true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			err := validarLease(c.lease, c.timeout)
+			if c.valido && err != nil {
+				t.Errorf("lease %s com timeout %s recusado: %v", c.lease, c.timeout, err)
+			}
+			if !c.valido && !errors.Is(err, ErrLeaseGeracaoCurto) {
+				t.Errorf("lease %s com timeout %s: erro %v, esperado %v", c.lease, c.timeout, err, ErrLeaseGeracaoCurto)
+			}
+		})
+	}
+}
+
+func TestNewGeracaoWorkerRecusaLeaseCurto(t *testing.T) {
+	t.Setenv("GERACAO_LEASE", geracaoTimeout.String())
+	if _, err := NewGeracaoWorker(PropostaService{}, repository.GeracaoJobRepository{}); !errors.Is(err, ErrLeaseGeracaoCurto) {
+		t.Fatalf("erro %v, esperado %v", err, ErrLeaseGeracaoCurto)
+	}
+}
+
+func TestNewGeracaoWorkerAoMenosUmWorker(t *testing.T) {
+	t.Setenv("GERACAO_WORKERS", "0")
+	w, err := NewGeracaoWorker(PropostaService{}, repository.GeracaoJobRepository{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.workers != 1 {
+		t.Errorf("%d workers, esperado 1", w.workers)
+	}
+}
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"propulse/model"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	CircuitoFechado    = "fechado"
+	CircuitoAberto     = "aberto"
+	CircuitoMeioAberto = "meio_aberto"
+)
+
+var ErrCircuitoAberto = errors.New("servico de IA indisponivel: circuit breaker aberto apos falhas consecutivas")
+
+type IAClientConfig struct {
+	BaseURL       string
+	Timeout       time.Duration
+	MaxTentativas int
+	BackoffBase   time.Duration
+	BackoffMax    time.Duration
+	LimiteFalhas  int
+	TempoAberto   time.Duration
+}
+
+func IAClientConfigFromEnv() IAClientConfig {
+	return IAClientConfig{
+		BaseURL:       iaURL,
+		Timeout:       env.Duration("IA_TIMEOUT", 90*time.Second),
+		MaxTentativas: env.IntMinimo("IA_MAX_TENTATIVAS", 3, 1),
+		BackoffBase:   env.Duration("IA_BACKOFF_BASE", 500*time.Millisecond),
+		BackoffMax:    env.Duration("IA_BACKOFF_MAX", 10*time.Second),
+		LimiteFalhas:  env.Int("IA_CIRCUIT_LIMITE_FALHAS", 5),
+		TempoAberto:   env.Duration("IA_CIRCUIT_TEMPO_ABERTO", 30*time.Second),
+	}
+}
+
+// iaResponse aceita os dois contratos do ia-service: o JSON antigo com o PDF em
+// base64 e o multipart/mixed, em que o PDF chega em binário logo depois do
+// JSON com o HTML. No segundo caso PDF é o próprio corpo da resposta sendo
+// lido em streaming, e quem chama deve fechá-lo.
+type iaResponse struct {
+	Html      string        `json:"html"`
+	PDFBase64 string        `json:"pdf_base64"`
+	PDF       io.ReadCloser `json:"-"`
+}
+
+// corpoPDF mantém a resposta HTTP e o prazo da tentativa vivos enquanto o PDF
+// é consumido.
+type corpoPDF struct {
+	io.Reader
+	fechar func()
+}
+
+func (c *corpoPDF) Close() error {
+	c.fechar()
+	return nil
+}
+
+// EstadoCircuito é servido sem autenticação em /diagnostico/ia, então traz só
+// o estado e os contadores; a resposta de erro do ia-service fica nos logs.
+type EstadoCircuito struct {
+	Estado             string     `json:"estado"`
+	FalhasConsecutivas int        `json:"falhasConsecutivas"`
+	LimiteFalhas       int        `json:"limiteFalhas"`
+	AbertoDesde        *time.Time `json:"abertoDesde,omitempty"`
+	ProximaTentativa   *time.Time `json:"proximaTentativa,omitempty"`
+}
+
+// IAClient encapsula as chamadas ao ia-service com timeout por tentativa,
+// retentativas com backoff exponencial e jitter, e um circuit breaker que
+// passa a falhar rápido depois de LimiteFalhas erros consecutivos.
+type IAClient struct {
+	cfg        IAClientConfig
+	httpClient *http.Client
+
+	mu                 sync.Mutex
+	estado             string
+	falhasConsecutivas int
+	abertoEm           time.Time
+	testeEmAndamento   bool
+}
+
+func NewIAClient(cfg IAClientConfig) *IAClient {
+	return &IAClient{
+		cfg:        cfg,
+		httpClient: &http.Client{},
+		estado:     CircuitoFechado,
+	}
+}
+
+// erroIA marca se a falha deve gerar nova tentativa e contar para o circuit breaker.
+type erroIA struct {
+	err        error
+	retentavel bool
+}
+
+func (e *erroIA) Error() string { return e.err.Error() }
+func (e *erroIA) Unwrap() error { return e.err }
+
+// GerarProposta respeita o cancelamento de ctx: cada tentativa recebe um prazo
+// derivado dele, e um cancelamento de quem chama não conta como falha do serviço.
+func (c *IAClient) GerarProposta(ctx context.Context, proposta *model.Proposta) (*iaResponse, error) {
+	body, err := json.Marshal(proposta)
+	if err != nil {
+		logger.Error("Erro ao realizar o Marshal da Proposta", err)
+		return nil, err
+	}
+
+	var ultimoErr error
+	for tentativa := 1; tentativa <= c.cfg.MaxTentativas; tentativa++ {
+		if err := c.permitir(); err != nil {
+			logger.Error("Chamada ao servico de IA bloqueada pelo circuit breaker", err)
+			return nil, err
+		}
+
+		resp, err := c.chamar(ctx, body)
+		if err == nil {
+			c.registrarSucesso()
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			c.liberarTeste()
+			logger.Error("Chamada ao servico de IA cancelada", ctx.Err())
+			return nil, ctx.Err()
+		}
+
+		var eIA *erroIA
+		if !errors.As(err, &eIA) || !eIA.retentavel {
+			c.liberarTeste()
+			logger.Error("Servico de IA retornou erro nao retentavel", err)
+			return nil, err
+		}
+		c.registrarFalha(err)
+		ultimoErr = err
+
+		if tentativa < c.cfg.MaxTentativas {
+			espera := c.backoff(tentativa)
+			logger.Error("Falha ao chamar o servico de IA, nova tentativa agendada", err,
+				zap.Int("tentativa", tentativa),
+				zap.Duration("espera", espera),
+			)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(espera):
+			}
+		}
+	}
+	return nil, fmt.Errorf("servico de IA falhou apos %d tentativas: %w", c.cfg.MaxTentativas, ultimoErr)
+}
+
+// chamar faz uma tentativa. O prazo IA_TIMEOUT cobre também a leitura do PDF
+// em streaming; por isso, no contrato multipart, o cancelamento só acontece
+// quando quem chama fecha iaResponse.PDF.
+func (c *IAClient) chamar(ctx context.Context, body []byte) (*iaResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/gerarproposta/pdf_dynamic", bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "multipart/mixed, application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, &erroIA{err: err, retentavel: true}
+	}
+	fechar := func() {
+		resp.Body.Close()
+		cancel()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer fechar()
+		errorBody, _ := io.ReadAll(resp.Body)
+		errorMsg := fmt.Errorf("servico de IA falhou: %s - %s", resp.Status, string(errorBody))
+		retentavel := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, &erroIA{err: errorMsg, retentavel: retentavel}
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "multipart/mixed" {
+		iaResp, err := lerMultipart(resp.Body, params["boundary"])
+		if err != nil {
+			fechar()
+			logger.Error("Erro ao ler resposta multipart da IA", err)
+			return nil, &erroIA{err: err, retentavel: ctx.Err() != nil || errors.Is(err, io.ErrUnexpectedEOF)}
+		}
+		iaResp.PDF = &corpoPDF{Reader: iaResp.PDF, fechar: fechar}
+		return iaResp, nil
+	}
+
+	defer fechar()
+	var iaResp iaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&iaResp); err != nil {
+		logger.Error("Erro ao decodificar resposta da IA", err)
+		// Corpo truncado por timeout ou conexão derrubada também merece nova tentativa.
+		return nil, &erroIA{err: err, retentavel: ctx.Err() != nil || errors.Is(err, io.ErrUnexpectedEOF)}
+	}
+	return &iaResp, nil
+}
+
+// lerMultipart decodifica a primeira parte (JSON com o HTML) e devolve a segunda
+// parte, o PDF, sem lê-la: ela é consumida direto pelo storage.
+func lerMultipart(corpo io.Reader, boundary string) (*iaResponse, error) {
+	if boundary == "" {
+		return nil, errors.New("resposta multipart da IA sem boundary")
+	}
+	mr := multipart.NewReader(corpo, boundary)
+
+	parte, err := mr.NextPart()
+	if err != nil {
+		return nil, fmt.Errorf("resposta multipart da IA sem a parte JSON: %w", err)
+	}
+	var iaResp iaResponse
+	if err := json.NewDecoder(parte).Decode(&iaResp); err != nil {
+		return nil, fmt.Errorf("parte JSON da resposta da IA inválida: %w", err)
+	}
+
+	parte, err = mr.NextPart()
+	if err != nil {
+		return nil, fmt.Errorf("resposta multipart da IA sem a parte PDF: %w", err)
+	}
+	if tipo, _, _ := mime.ParseMediaType(parte.Header.Get("Content-Type")); tipo != "application/pdf" {
+		return nil, fmt.Errorf("parte PDF da resposta da IA com Content-Type inesperado: %q", tipo)
+	}
+	iaResp.PDF = io.NopCloser(parte)
+	return &iaResp, nil
+}
+
+// AnexarCertificado envia o PDF e o HTML do certificado ao ia-service, que
+// renderiza o certificado e o anexa como última página. O PDF segue em
+// streaming no corpo multipart/form-data; como ele não pode ser relido, há uma
+// única tentativa, mas ela passa pelo circuit breaker como as demais.
+func (c *IAClient) AnexarCertificado(ctx context.Context, pdf io.Reader, certificadoHTML string) (io.ReadCloser, error) {
+	if err := c.permitir(); err != nil {
+		logger.Error("Chamada ao servico de IA bloqueada pelo circuit breaker", err)
+		return nil, err
+	}
+
+	corpo, escritor := io.Pipe()
+	mw := multipart.NewWriter(escritor)
+	go func() {
+		err := mw.WriteField("certificado_html", certificadoHTML)
+		if err == nil {
+			var parte io.Writer
+			parte, err = mw.CreateFormFile("pdf", "proposta.pdf")
+			if err == nil {
+				_, err = io.Copy(parte, pdf)
+			}
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		escritor.CloseWithError(err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/gerarproposta/anexar_certificado", corpo)
+	if err != nil {
+		cancel()
+		corpo.CloseWithError(err)
+		c.liberarTeste()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/pdf")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		corpo.CloseWithError(err)
+		if ctx.Err() != nil {
+			c.liberarTeste()
+		} else {
+			c.registrarFalha(err)
+		}
+		logger.Error("Erro ao chamar o servico de IA para anexar certificado", err)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer cancel()
+		defer resp.Body.Close()
+		errorBody, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("servico de IA falhou ao anexar certificado: %s - %s", resp.Status, string(errorBody))
+		if resp.StatusCode >= 500 {
+			c.registrarFalha(err)
+		} else {
+			c.liberarTeste()
+		}
+		return nil, err
+	}
+	c.registrarSucesso()
+	return &corpoPDF{Reader: resp.Body, fechar: func() {
+		resp.Body.Close()
+		cancel()
+	}}, nil
+}
+
+// backoff aplica full jitter sobre o backoff exponencial limitado por BackoffMax.
+func (c *IAClient) backoff(tentativa int) time.Duration {
+	limite := c.cfg.BackoffBase << (tentativa - 1)
+	if limite <= 0 || limite > c.cfg.BackoffMax {
+		limite = c.cfg.BackoffMax
+	}
+	return time.Duration(rand.Int64N(int64(limite) + 1))
+}
+
+func (c *IAClient) permitir() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.estado {
+	case CircuitoAberto:
+		if time.Since(c.abertoEm) < c.cfg.TempoAberto {
+			return ErrCircuitoAberto
+		}
+		c.estado = CircuitoMeioAberto
+		c.testeEmAndamento = true
+		logger.Info("Circuit breaker do servico de IA em meio aberto, testando chamada")
+		return nil
+	case CircuitoMeioAberto:
+		// Apenas uma chamada de teste por vez enquanto o circuito está meio aberto.
+		if c.testeEmAndamento {
+			return ErrCircuitoAberto
+		}
+		c.testeEmAndamento = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (c *IAClient) registrarSucesso() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.estado != CircuitoFechado {
+		logger.Info("Circuit breaker do servico de IA fechado novamente")
+	}
+	c.estado = CircuitoFechado
+	c.falhasConsecutivas = 0
+	c.testeEmAndamento = false
+}
+
+func (c *IAClient) registrarFalha(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.falhasConsecutivas++
+	c.testeEmAndamento = false
+	if c.estado == CircuitoMeioAberto || c.falhasConsecutivas >= c.cfg.LimiteFalhas {
+		if c.estado != CircuitoAberto {
+			logger.Error("Circuit breaker do servico de IA aberto", err, zap.Int("falhas_consecutivas", c.falhasConsecutivas))
+		}
+		c.estado = CircuitoAberto
+		c.abertoEm = time.Now()
+	}
+}
+
+// liberarTeste devolve a vaga de teste do meio aberto quando o erro não diz
+// nada sobre a saúde do serviço (ex.: 4xx por payload inválido).
+func (c *IAClient) liberarTeste() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.testeEmAndamento = false
+}
+
+func (c *IAClient) Estado() EstadoCircuito {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	estado := EstadoCircuito{
+		Estado:             c.estado,
+		FalhasConsecutivas: c.falhasConsecutivas,
+		LimiteFalhas:       c.cfg.LimiteFalhas,
+	}
+	if c.estado != CircuitoFechado {
+		abertoDesde := c.abertoEm
+		proxima := c.abertoEm.Add(c.cfg.TempoAberto)
+		estado.AbertoDesde = &abertoDesde
+		estado.ProximaTentativa = &proxima
+	}
+	return estado
+}
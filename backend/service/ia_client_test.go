@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"propulse/model"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// servidorIA responde com os status informados, um por chamada, e com 200 e
+// um JSON válido depois que eles acabam.
+func servidorIA(t *testing.T, status ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var chamadas atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(chamadas.Add(1))
+		if n <= len(status) {
+			w.WriteHeader(status[n-1])
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"html":"<p>proposta</p>","pdf_base64":"JVBERg=="}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &chamadas
+}
+
+func configTesteIA(url string) IAClientConfig {
+	return IAClientConfig{
+		BaseURL:       url,
+		Timeout:       time.Second,
+		MaxTentativas: 3,
+		BackoffBase:   time.Millisecond,
+		BackoffMax:    2 * time.Millisecond,
+		LimiteFalhas:  5,
+		TempoAberto:   time.Hour,
+	}
+}
+
+func TestIAClientRetentaFalhasTemporarias(t *testing.T) {
+	srv, chamadas := servidorIA(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	c := NewIAClient(configTesteIA(srv.URL))
+
+	resp, err := c.GerarProposta(context.Background(), &model.Proposta{})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if resp.Html != "<p>proposta</p>" {
+		t.Errorf("html = %q", resp.Html)
+	}
+	if n := chamadas.Load(); n != 3 {
+		t.Errorf("%d chamadas, esperado 3", n)
+	}
+	if estado := c.Estado(); estado.Estado != CircuitoFechado || estado.FalhasConsecutivas != 0 {
+		t.Errorf("estado depois do sucesso = %+v", estado)
+	}
+}
+
+func TestIAClientNaoRetentaErroDoCliente(t *testing.T) {
+	srv, chamadas := servidorIA(t, http.StatusBadRequest)
+	c := NewIAClient(configTesteIA(srv.URL))
+
+	if _, err := c.GerarProposta(context.Background(), &model.Proposta{}); err == nil {
+		t.Fatal("esperado erro para 400")
+	}
+	if n := chamadas.Load(); n != 1 {
+		t.Errorf("%d chamadas, esperado 1", n)
+	}
+	if falhas := c.Estado().FalhasConsecutivas; falhas != 0 {
+		t.Errorf("400 contou %d falhas para o circuit breaker", falhas)
+	}
+}
+
+func TestIAClientEsgotaTentativas(t *testing.T) {
+	srv, chamadas := servidorIA(t, 500, 500, 500)
+	c := NewIAClient(configTesteIA(srv.URL))
+
+	_, err := c.GerarProposta(context.Background(), &model.Proposta{})
+	if err == nil || errors.Is(err, ErrCircuitoAberto) {
+		t.Fatalf("erro = %v, esperado falha após as tentativas", err)
+	}
+	if n := chamadas.Load(); n != 3 {
+		t.Errorf("%d chamadas, esperado 3", n)
+	}
+	if falhas := c.Estado().FalhasConsecutivas; falhas != 3 {
+		t.Errorf("%d falhas consecutivas, esperado 3", falhas)
+	}
+}
+
+func TestIAClientAbreCircuito(t *testing.T) {
+	srv, chamadas := servidorIA(t, 500, 500, 500, 500)
+	cfg := configTesteIA(srv.URL)
+	cfg.LimiteFalhas = 2
+	c := NewIAClient(cfg)
+
+	if _, err := c.GerarProposta(context.Background(), &model.Proposta{}); !errors.Is(err, ErrCircuitoAberto) {
+		t.Fatalf("erro = %v, esperado %v na terceira tentativa", err, ErrCircuitoAberto)
+	}
+	if _, err := c.GerarProposta(context.Background(), &model.Proposta{}); !errors.Is(err, ErrCircuitoAberto) {
+		t.Fatalf("erro = %v, esperado %v com o circuito aberto", err, ErrCircuitoAberto)
+	}
+	if n := chamadas.Load(); n != 2 {
+		t.Errorf("%d chamadas, esperado 2: com o circuito aberto o serviço não é chamado", n)
+	}
+	estado := c.Estado()
+	if estado.Estado != CircuitoAberto || estado.AbertoDesde == nil || estado.ProximaTentativa == nil {
+		t.Errorf("estado = %+v, esperado aberto com as datas", estado)
+	}
+}
+
+func TestIAClientMeioAberto(t *testing.T) {
+	srv, chamadas := servidorIA(t)
+	cfg := configTesteIA(srv.URL)
+	cfg.LimiteFalhas = 1
+	c := NewIAClient(cfg)
+
+	c.registrarFalha(errors.New("falha"))
+	if err := c.permitir(); !errors.Is(err, ErrCircuitoAberto) {
+		t.Fatalf("permitir = %v com o circuito recém-aberto", err)
+	}
+
+	// Passado TempoAberto, só uma chamada de teste é liberada por vez.
+	c.abertoEm = time.Now().Add(-cfg.TempoAberto)
+	if err := c.permitir(); err != nil {
+		t.Fatalf("primeira chamada do meio aberto recusada: %v", err)
+	}
+	if err := c.permitir(); !errors.Is(err, ErrCircuitoAberto) {
+		t.Fatalf("segunda chamada simultânea do meio aberto = %v", err)
+	}
+	// Uma falha no teste reabre o circuito.
+	c.registrarFalha(errors.New("falha no teste"))
+	if estado := c.Estado().Estado; estado != CircuitoAberto {
+		t.Fatalf("estado = %s depois da falha no teste", estado)
+	}
+
+	// Um sucesso no teste fecha o circuito.
+	c.abertoEm = time.Now().Add(-cfg.TempoAberto)
+	if _, err := c.GerarProposta(context.Background(), &model.Proposta{}); err != nil {
+		t.Fatalf("chamada de teste falhou: %v", err)
+	}
+	if estado := c.Estado(); estado.Estado != CircuitoFechado || estado.FalhasConsecutivas != 0 {
+		t.Errorf("estado = %+v, esperado fechado", estado)
+	}
+	if n := chamadas.Load(); n != 1 {
+		t.Errorf("%d chamadas, esperado 1", n)
+	}
+}
+
+func TestIAClientBackoff(t *testing.T) {
+	c := NewIAClient(IAClientConfig{BackoffBase: 100 * time.Millisecond, BackoffMax: time.Second})
+	limites := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, limite := range limites {
+		for range 50 {
+			if espera := c.backoff(i + 1); espera < 0 || espera > limite {
+				t.Fatalf("backoff(%d) = %s, fora de [0, %s]", i+1, espera, limite)
+			}
+		}
+	}
+	// Deslocamentos que estouram o int64 ficam no máximo.
+	if espera := c.backoff(80); espera < 0 || espera > time.Second {
+		t.Errorf("backoff(80) = %s, fora de [0, 1s]", espera)
+	}
+}
+
+func TestIAClientCancelamentoNaoContaComoFalha(t *testing.T) {
+	bloqueio := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-bloqueio:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(bloqueio)
+	c := NewIAClient(configTesteIA(srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.GerarProposta(ctx, &model.Proposta{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("erro = %v, esperado %v", err, context.DeadlineExceeded)
+	}
+	if falhas := c.Estado().FalhasConsecutivas; falhas != 0 {
+		t.Errorf("cancelamento contou %d falhas para o circuit breaker", falhas)
+	}
+}
+
+func TestIAClientEstadoNaoExpoeErro(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "detalhe interno do ia-service", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	cfg := configTesteIA(srv.URL)
+	cfg.MaxTentativas = 1
+	cfg.LimiteFalhas = 1
+	c := NewIAClient(cfg)
+	c.GerarProposta(context.Background(), &model.Proposta{})
+
+	corpo, err := json.Marshal(c.Estado())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(corpo), "detalhe interno") {
+		t.Errorf("estado do circuito expõe o erro do serviço: %s", corpo)
+	}
+}
+
+func TestIAClientConfigAoMenosUmaTentativa(t *testing.T) {
+	t.Setenv("IA_MAX_TENTATIVAS", "0")
+	if n := IAClientConfigFromEnv().MaxTentativas; n != 1 {
+		t.Errorf("MaxTentativas = %d, esperado 1", n)
+	}
+}
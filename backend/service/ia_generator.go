@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"propulse/model"
+	"propulse/shared/logger"
+	"strings"
+)
+
+// IAGenerator delega a geração ao ia-service em Python (/gerarproposta/pdf_dynamic).
+type IAGenerator struct {
+	client *IAClient
+}
+
+func NewIAGenerator(client *IAClient) *IAGenerator {
+	return &IAGenerator{
+		client: client,
+	}
+}
+
+func (g *IAGenerator) Gerar(ctx context.Context, proposta model.Proposta) (*ResultadoGeracao, error) {
+	iaResp, err := g.client.GerarProposta(ctx, &proposta)
+	if err != nil {
+		logger.Error("Erro ao chamar o servico de IA", err)
+		return nil, err
+	}
+	pdf := iaResp.PDF
+	if pdf == nil {
+		// Contrato antigo: o PDF veio em base64 dentro do JSON e é decodificado
+		// em streaming conforme o storage lê.
+		pdf = io.NopCloser(base64.NewDecoder(base64.StdEncoding, strings.NewReader(iaResp.PDFBase64)))
+	}
+	return &ResultadoGeracao{
+		Html: iaResp.Html,
+		PDF:  pdf,
+	}, nil
+}
+
+func (g *IAGenerator) AnexarCertificado(ctx context.Context, proposta model.Proposta, pdf io.Reader, aceite model.Aceite) (io.ReadCloser, error) {
+	html, err := certificadoHTML(proposta, aceite)
+	if err != nil {
+		logger.Error("Erro ao renderizar HTML do certificado", err)
+		return nil, err
+	}
+	return g.client.AnexarCertificado(ctx, pdf, html)
+}
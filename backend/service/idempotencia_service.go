@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrIdempotenciaConflito     = errors.New("Idempotency-Key já utilizada com outra requisição")
+	ErrIdempotenciaEmAndamento  = errors.New("requisição com esta Idempotency-Key ainda está em processamento")
+	ErrReservaIdempotenciaCurta = errors.New("IDEMPOTENCY_RESERVA_TTL precisa ser maior que GERACAO_TIMEOUT")
+)
+
+type IdempotenciaService struct {
+	repository repository.IdempotenciaRepository
+	ttl        time.Duration
+	reservaTTL time.Duration
+}
+
+// NewIdempotenciaService recusa uma IDEMPOTENCY_RESERVA_TTL que não cubra a
+// regeneração síncrona, que pode levar até GERACAO_TIMEOUT: a reserva seria
+// retomada com a requisição original ainda em andamento e a IA chamada duas vezes.
+func NewIdempotenciaService(ir repository.IdempotenciaRepository) (IdempotenciaService, error) {
+	is := IdempotenciaService{
+		repository: ir,
+		ttl:        env.Duration("IDEMPOTENCY_TTL", 24*time.Hour),
+		reservaTTL: env.Duration("IDEMPOTENCY_RESERVA_TTL", 10*time.Minute),
+	}
+	if is.reservaTTL <= geracaoTimeout {
+		return IdempotenciaService{}, fmt.Errorf("%w: reserva %s, timeout %s", ErrReservaIdempotenciaCurta, is.reservaTTL, geracaoTimeout)
+	}
+	return is, nil
+}
+
+// Iniciar reserva a chave para a requisição atual. Retorna nil, nil quando quem
+// chama deve processar a requisição; retorna o registro quando há uma resposta
+// original para repetir; e retorna erro quando a chave foi reutilizada com outro
+// corpo ou quando a requisição original ainda não terminou. Uma reserva sem
+// resposta há mais que IDEMPOTENCY_RESERVA_TTL é de um processo que caiu e é
+// retomada.
+func (is *IdempotenciaService) Iniciar(ctx context.Context, chave string, requestHash string) (*model.RegistroIdempotencia, error) {
+	reservada, existente, err := is.repository.Reservar(ctx, chave, requestHash, time.Now().Add(is.ttl), is.reservaTTL)
+	if err != nil {
+		return nil, err
+	}
+	if reservada {
+		return nil, nil
+	}
+	if err := avaliarExistente(existente, requestHash); err != nil {
+		return nil, err
+	}
+	logger.Info("Repetindo resposta gravada para Idempotency-Key", zap.String("chave", chave))
+	return existente, nil
+}
+
+// avaliarExistente decide se a resposta gravada para a chave pode ser repetida
+// para uma requisição com requestHash.
+func avaliarExistente(existente *model.RegistroIdempotencia, requestHash string) error {
+	if existente.RequestHash != requestHash {
+		return ErrIdempotenciaConflito
+	}
+	if !existente.Concluido() {
+		return ErrIdempotenciaEmAndamento
+	}
+	return nil
+}
+
+func (is *IdempotenciaService) Concluir(ctx context.Context, chave string, statusCode int, contentType string, resposta []byte) error {
+	return is.repository.Concluir(ctx, chave, statusCode, contentType, resposta)
+}
+
+// Liberar descarta a reserva para que o cliente possa tentar de novo com a mesma chave.
+func (is *IdempotenciaService) Liberar(ctx context.Context, chave string) error {
+	return is.repository.Remover(ctx, chave)
+}
+
+// IniciarLimpeza remove periodicamente as chaves expiradas até ctx ser cancelado.
+func (is *IdempotenciaService) IniciarLimpeza(ctx context.Context) {
+	intervalo := env.Duration("IDEMPOTENCY_LIMPEZA_INTERVALO", time.Hour)
+	go func() {
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				removidas, err := is.repository.RemoverExpiradas(ctx)
+				if err == nil && removidas > 0 {
+					logger.Info("Chaves de idempotência expiradas removidas", zap.Int64("quantidade", removidas))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"propulse/model"
+	"propulse/repository"
+	"testing"
+)
+
+func TestAvaliarExistente(t *testing.T) {
+	status := 201
+	concluido := &model.RegistroIdempotencia{Chave: "chave", RequestHash: "hash", StatusCode: &status, Resposta: []byte(`{}`)}
+	emAndamento := &model.RegistroIdempotencia{Chave: "chave", RequestHash: "hash"}
+	casos := []struct {
+		nome        string
+		existente   *model.RegistroIdempotencia
+		requestHash string
+		esperado    error
+	}{
+		{"mesma requisição concluída repete a resposta", concluido, "hash", nil},
+		{"mesma requisição ainda em andamento", emAndamento, "hash", ErrIdempotenciaEmAndamento},
+		{"outra requisição com a chave concluída", concluido, "outro", ErrIdempotenciaConflito},
+		{"outra requisição com a chave em andamento", emAndamento, "outro", ErrIdempotenciaConflito},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if err := avaliarExistente(c.existente, c.requestHash); !errors.Is(err, c.esperado) {
+				t.Errorf("erro = %v, esperado %v", err, c.esperado)
+			}
+		})
+	}
+}
+
+func TestNewIdempotenciaServiceRecusaReservaCurta(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_RESERVA_TTL", geracaoTimeout.String())
+	if _, err := NewIdempotenciaService(repository.IdempotenciaRepository{}); !errors.Is(err, ErrReservaIdempotenciaCurta) {
+		t.Fatalf("erro = %v, esperado %v", err, ErrReservaIdempotenciaCurta)
+	}
+
+	t.Setenv("IDEMPOTENCY_RESERVA_TTL", "")
+	is, err := NewIdempotenciaService(repository.IdempotenciaRepository{})
+	if err != nil {
+		t.Fatalf("erro inesperado com o padrão: %v", err)
+	}
+	if is.reservaTTL <= geracaoTimeout {
+		t.Errorf("reservaTTL padrão %s não cobre GERACAO_TIMEOUT %s", is.reservaTTL, geracaoTimeout)
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrLinkInvalido = errors.New("link de compartilhamento inválido")
+	ErrLinkExpirado = errors.New("link de compartilhamento expirado, revogado ou sem visualizações restantes")
+
+	ErrLinkSegredoAusente = errors.New("LINK_SEGREDO não definido: sem ele os links assinados por uma réplica não valem nas outras nem após reiniciar")
+)
+
+// LinkService emite e valida os links públicos das propostas. O token carrega o
+// id do link e a expiração, assinados com HMAC-SHA256, então tokens forjados
+// são recusados sem consultar o banco.
+type LinkService struct {
+	repository     repository.LinkRepository
+	propostas      PropostaService
+	segredo        []byte
+	validadePadrao time.Duration
+	urlPublica     string
+}
+
+func NewLinkService(lr repository.LinkRepository, propostas PropostaService) (LinkService, error) {
+	segredo := []byte(env.String("LINK_SEGREDO", ""))
+	if len(segredo) == 0 {
+		return LinkService{}, ErrLinkSegredoAusente
+	}
+	return LinkService{
+		repository:     lr,
+		propostas:      propostas,
+		segredo:        segredo,
+		validadePadrao: env.Duration("LINK_VALIDADE_PADRAO", 7*24*time.Hour),
+		urlPublica:     strings.TrimSuffix(env.String("URL_PUBLICA", ""), "/"),
+	}, nil
+}
+
+func (ls *LinkService) CriarLink(ctx context.Context, idParam string, input model.CriarLink) (*model.LinkCompartilhamento, string, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, "", err
+	}
+	if _, err := ls.propostas.FindByID(ctx, idParam); err != nil {
+		return nil, "", err
+	}
+	validade := ls.validadePadrao
+	if input.ValidadeHoras > 0 {
+		validade = time.Duration(input.ValidadeHoras) * time.Hour
+	}
+	// Truncado ao segundo, pois o token carrega a expiração em Unix.
+	expiraEm := time.Now().Add(validade).Truncate(time.Second)
+
+	link, err := ls.repository.CriarLink(ctx, id, expiraEm, input.MaxVisualizacoes)
+	if err != nil {
+		return nil, "", err
+	}
+	logger.Info("Link de compartilhamento criado", zap.String("proposta_id", id.String()), zap.String("link_id", link.Id.String()))
+	return link, ls.token(link.Id, link.ExpiraEm), nil
+}
+
+// URL monta o endereço público do token.
+func (ls *LinkService) URL(token string) string {
+	return ls.urlPublica + "/p/" + token
+}
+
+func (ls *LinkService) ListarLinks(ctx context.Context, idParam string) ([]model.LinkCompartilhamento, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	if _, err := ls.propostas.FindByID(ctx, idParam); err != nil {
+		return nil, err
+	}
+	return ls.repository.ListarLinks(ctx, id)
+}
+
+func (ls *LinkService) RevogarLink(ctx context.Context, idParam string, linkParam string) (*model.LinkCompartilhamento, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	linkID, err := uuid.Parse(linkParam)
+	if err != nil {
+		logger.Error("id do link não é um UUID", err)
+		return nil, err
+	}
+	return ls.repository.RevogarLink(ctx, id, linkID)
+}
+
+// Resolver valida o token e retorna o link ativo. Com contar=true a
+// visualização é contabilizada.
+func (ls *LinkService) Resolver(ctx context.Context, token string, contar bool) (*model.LinkCompartilhamento, error) {
+	linkID, expiraEm, ok := ls.verificar(token)
+	if !ok {
+		return nil, ErrLinkInvalido
+	}
+	if !time.Now().Before(expiraEm) {
+		return nil, ErrLinkExpirado
+	}
+	link, err := ls.repository.UsarLink(ctx, linkID, contar)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLinkExpirado
+	}
+	if err != nil {
+		logger.Error("Erro ao validar link de compartilhamento", err, zap.String("link_id", linkID.String()))
+		return nil, err
+	}
+	return link, nil
+}
+
+func (ls *LinkService) token(id uuid.UUID, expiraEm time.Time) string {
+	payload := make([]byte, 0, 24)
+	payload = append(payload, id[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(expiraEm.Unix()))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(ls.assinar(payload))
+}
+
+func (ls *LinkService) verificar(token string) (uuid.UUID, time.Time, bool) {
+	payloadB64, assinaturaB64, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(payloadB64)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, time.Time{}, false
+	}
+	assinatura, err := base64.RawURLEncoding.DecodeString(assinaturaB64)
+	if err != nil || !hmac.Equal(assinatura, ls.assinar(payload)) {
+		return uuid.Nil, time.Time{}, false
+	}
+	id, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, time.Time{}, false
+	}
+	return id, time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0), true
+}
+
+func (ls *LinkService) assinar(payload []byte) []byte {
+	mac := hmac.New(sha256.New, ls.segredo)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"propulse/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTokenLink(t *testing.T) {
+	ls := LinkService{segredo: []byte("segredo-de-teste")}
+	id := uuid.New()
+	expiraEm := time.Now().Add(time.Hour).Truncate(time.Second)
+	token := ls.token(id, expiraEm)
+
+	obtidoID, obtidoExpira, ok := ls.verificar(token)
+	if !ok || obtidoID != id || !obtidoExpira.Equal(expiraEm) {
+		t.Fatalf("verificar = %s, %s, %v; esperado %s, %s", obtidoID, obtidoExpira, ok, id, expiraEm)
+	}
+
+	payload, assinatura, _ := strings.Cut(token, ".")
+	bytesPayload, _ := base64.RawURLEncoding.DecodeString(payload)
+	bytesPayload[len(bytesPayload)-1]++
+	prorrogado := base64.RawURLEncoding.EncodeToString(bytesPayload) + "." + assinatura
+
+	outroSegredo := LinkService{segredo: []byte("outro-segredo")}
+	casos := []struct {
+		nome  string
+		ls    LinkService
+		token string
+	}{
+		{"expiração alterada", ls, prorrogado},
+		{"assinatura de outro link", ls, payload + "." + strings.Split(ls.token(uuid.New(), expiraEm), ".")[1]},
+		{"assinado com outro segredo", outroSegredo, token},
+		{"sem assinatura", ls, payload},
+		{"payload curto", ls, base64.RawURLEncoding.EncodeToString([]byte("curto")) + "." + assinatura},
+		{"base64 inválido", ls, "***." + assinatura},
+		{"vazio", ls, ""},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if _, _, ok := c.ls.verificar(c.token); ok {
+				t.Errorf("token %q aceito", c.token)
+			}
+		})
+	}
+}
+
+func TestResolverLinkSemConsultarBanco(t *testing.T) {
+	// O repositório não tem conexão: os tokens inválidos e expirados precisam
+	// ser recusados antes de qualquer consulta.
+	ls := LinkService{segredo: []byte("segredo-de-teste")}
+	casos := []struct {
+		nome     string
+		token    string
+		esperado error
+	}{
+		{"forjado", "abc.def", ErrLinkInvalido},
+		{"expirado", ls.token(uuid.New(), time.Now().Add(-time.Second).Truncate(time.Second)), ErrLinkExpirado},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if _, err := ls.Resolver(context.Background(), c.token, true); !errors.Is(err, c.esperado) {
+				t.Errorf("erro = %v, esperado %v", err, c.esperado)
+			}
+		})
+	}
+}
+
+func TestNewLinkServiceExigeSegredo(t *testing.T) {
+	t.Setenv("LINK_SEGREDO", "  ")
+	if _, err := NewLinkService(repository.LinkRepository{}, PropostaService{}); !errors.Is(err, ErrLinkSegredoAusente) {
+		t.Fatalf("erro %v, esperado %v", err, ErrLinkSegredoAusente)
+	}
+
+	t.Setenv("LINK_SEGREDO", "segredo-de-teste")
+	ls, err := NewLinkService(repository.LinkRepository{}, PropostaService{})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if string(ls.segredo) != "segredo-de-teste" {
+		t.Errorf("segredo = %q", ls.segredo)
+	}
+}
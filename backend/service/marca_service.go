@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/logger"
+
+	"github.com/google/uuid"
+)
+
+type MarcaService struct {
+	repository repository.MarcaRepository
+}
+
+func NewMarcaService(mr repository.MarcaRepository) MarcaService {
+	return MarcaService{
+		repository: mr,
+	}
+}
+
+func (ms *MarcaService) CriarMarca(ctx context.Context, marca model.Marca) (*model.Marca, error) {
+	marca.Normalizar()
+	return ms.repository.CriarMarca(ctx, marca)
+}
+
+func (ms *MarcaService) ListarMarcas(ctx context.Context) ([]model.Marca, error) {
+	return ms.repository.ListarMarcas(ctx)
+}
+
+func (ms *MarcaService) FindMarca(ctx context.Context, idParam string) (*model.Marca, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	return ms.repository.FindMarca(ctx, id)
+}
+
+func (ms *MarcaService) AtualizarMarca(ctx context.Context, idParam string, marca model.Marca) (*model.Marca, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	marca.Normalizar()
+	return ms.repository.AtualizarMarca(ctx, id, marca)
+}
+
+func (ms *MarcaService) DeleteMarca(ctx context.Context, idParam string) error {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return err
+	}
+	return ms.repository.DeleteMarca(ctx, id)
+}
@@ -1,139 +1,351 @@
 package service
 
 import (
-	"bytes"
-	"encoding/base64"
-	"encoding/json"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"path/filepath"
 	"propulse/model"
 	"propulse/repository"
+	"propulse/shared/env"
 	"propulse/shared/logger"
-	"slices"
+	"propulse/storage"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
 type PropostaService struct {
 	repository repository.PropostaRepository
+	jobs       repository.GeracaoJobRepository
+	versoes    repository.PropostaVersaoRepository
+	artefatos  repository.ArtefatoRepository
+	historico  repository.StatusHistoricoRepository
+	clientes   repository.ClienteRepository
+	marcas     repository.MarcaRepository
+	eventos    *EventoBroker
+	gerador    Generator
+	store      storage.Store
 }
 
 var iaURL = os.Getenv("IA_URL")
 
-type iaResponse struct {
-	Html      string `json:"html"`
-	PDFBase64 string `json:"pdf_base64"`
-}
+var geracaoMaxTentativas = env.IntMinimo("GERACAO_MAX_TENTATIVAS", 3, 1)
+
+var geracaoTimeout = env.Duration("GERACAO_TIMEOUT", 5*time.Minute)
 
-func NewPropostaService(pr repository.PropostaRepository) PropostaService {
+func NewPropostaService(pr repository.PropostaRepository, jr repository.GeracaoJobRepository, vr repository.PropostaVersaoRepository, ar repository.ArtefatoRepository, hr repository.StatusHistoricoRepository, cr repository.ClienteRepository, mr repository.MarcaRepository, eventos *EventoBroker, gerador Generator, store storage.Store) PropostaService {
 	return PropostaService{
 		repository: pr,
+		jobs:       jr,
+		versoes:    vr,
+		artefatos:  ar,
+		historico:  hr,
+		clientes:   cr,
+		marcas:     mr,
+		eventos:    eventos,
+		gerador:    gerador,
+		store:      store,
 	}
 }
 
-func (ps *PropostaService) SalvarPDF(propostaID uuid.UUID, pdfData []byte) (string, error) {
-	diretorioDestino := filepath.Join("uploads", "propostas")
-	if err := os.MkdirAll(diretorioDestino, 0755); err != nil {
-		logger.Error("Erro ao criar diretorio de destino:", err, zap.String("Path", diretorioDestino))
-		return "", err
-	}
-	nomeArquivo := fmt.Sprintf("proposta_%s.pdf", propostaID.String())
-	filePath := filepath.Join(diretorioDestino, nomeArquivo)
-	err := os.WriteFile(filePath, pdfData, 0644)
+// SalvarPDF grava cada renderização sob uma chave única e versionada no Store
+// configurado. O PDF é lido em streaming, com o checksum calculado no caminho,
+// então o uso de memória não cresce com o tamanho do arquivo. O valor guardado
+// em arquivo_final é a chave, não um caminho em disco.
+func (ps *PropostaService) SalvarPDF(ctx context.Context, propostaID uuid.UUID, pdf io.Reader) (*model.ArtefatoPDF, error) {
+	chave := model.PrefixoChavePDF(propostaID) + fmt.Sprintf("%s_%s.pdf",
+		time.Now().UTC().Format("20060102T150405Z"),
+		uuid.New().String()[:8],
+	)
+
+	hash := sha256.New()
+	info, err := ps.store.Put(ctx, chave, io.TeeReader(pdf, hash), -1, "application/pdf")
 	if err != nil {
-		logger.Error("Erro ao salvar arquivo PDF no disco:", err)
-		return "", err
+		logger.Error("Erro ao salvar arquivo PDF no storage:", err, zap.String("chave", chave))
+		return nil, err
 	}
-	logger.Info("PDF salvo com sucesso em:", zap.String("Path", filePath))
-	return filePath, nil
+
+	logger.Info("PDF salvo com sucesso no storage:", zap.String("chave", chave), zap.Int64("tamanho", info.Tamanho))
+	return &model.ArtefatoPDF{
+		PropostaId: propostaID,
+		Chave:      chave,
+		Sha256:     hex.EncodeToString(hash.Sum(nil)),
+		Tamanho:    info.Tamanho,
+	}, nil
 }
 
-func (ps *PropostaService) GetAllPropostas() (*[]model.Proposta, error) {
-	listaDePropostas, err := ps.repository.GetAllPropostas()
+// ListarPropostas devolve uma página da listagem com o total e, quando houver
+// mais propostas, o cursor da próxima página.
+func (ps *PropostaService) ListarPropostas(ctx context.Context, filtro model.FiltroPropostas) (*model.PaginaPropostas, error) {
+	filtro.Normalizar()
+	criacao, err := filtro.Criacao()
+	if err != nil {
+		return nil, err
+	}
+	atualizacao, err := filtro.Atualizacao()
+	if err != nil {
+		return nil, err
+	}
+	var cursor *model.CursorPropostas
+	if filtro.Cursor != "" {
+		if cursor, err = model.DecodificarCursor(filtro.Cursor, filtro.Sort); err != nil {
+			return nil, err
+		}
+		filtro.Offset = 0
+	}
+
+	propostas, total, err := ps.repository.ListarPropostas(ctx, filtro, criacao, atualizacao, cursor)
 	if err != nil {
 		logger.Error("Erro ao consultar propostas", err)
-		return &[]model.Proposta{}, err
+		return nil, err
 	}
-	return listaDePropostas, nil
+	pagina := &model.PaginaPropostas{
+		Itens:  propostas,
+		Total:  total,
+		Limit:  filtro.Limit,
+		Offset: filtro.Offset,
+	}
+	if len(propostas) > filtro.Limit {
+		pagina.Itens = propostas[:filtro.Limit]
+		ultima := &pagina.Itens[filtro.Limit-1]
+		campo, _ := filtro.CampoOrdenacao()
+		pagina.ProximoCursor = model.CursorPropostas{
+			Sort:  filtro.Sort,
+			Valor: model.ValorOrdenacao(ultima, campo),
+			Id:    ultima.Id,
+		}.Codificar()
+	}
+	return pagina, nil
 }
 
-func (ps *PropostaService) CriarProposta(propostaInput model.Proposta) (*model.Proposta, error) {
+// BuscarPropostas faz a busca textual paginada por relevância.
+func (ps *PropostaService) BuscarPropostas(ctx context.Context, busca model.BuscaPropostas) (*model.PaginaBusca, error) {
+	if busca.Limit == 0 {
+		busca.Limit = model.ListagemLimitePadrao
+	}
+	resultados, total, err := ps.repository.BuscarPropostas(ctx, busca.Q, busca.Limit, busca.Offset)
+	if err != nil {
+		logger.Error("Erro na busca de propostas", err, zap.String("q", busca.Q))
+		return nil, err
+	}
+	return &model.PaginaBusca{
+		Itens:  resultados,
+		Total:  total,
+		Limit:  busca.Limit,
+		Offset: busca.Offset,
+	}, nil
+}
+
+// CriarProposta persiste a proposta e enfileira a geração do conteúdo. A chamada
+// à IA, o salvamento do PDF e a atualização final ficam a cargo do GeracaoWorker.
+func (ps *PropostaService) CriarProposta(ctx context.Context, propostaInput model.Proposta) (*model.Proposta, *model.GeracaoJob, error) {
 	propostaInput.Id = uuid.New()
-	if propostaInput.Html == "" {
-		propostaInput.Html = ""
+	// O PDF só é definido pela geração; uma chave vinda do cliente poderia
+	// apontar para o arquivo de outra proposta.
+	propostaInput.ArquivoFinal = ""
+	if propostaInput.Status == "" {
+		propostaInput.Status = model.StatusRascunho
+	}
+	if propostaInput.Status != model.StatusRascunho {
+		return nil, nil, fmt.Errorf("%w: toda proposta começa como %s", model.ErrTransicaoInvalida, model.StatusRascunho)
+	}
+	if propostaInput.ValidadeDias != nil {
+		validoAte := time.Now().AddDate(0, 0, *propostaInput.ValidadeDias)
+		propostaInput.ValidoAte = &validoAte
+	}
+	if propostaInput.ValidoAte != nil && !propostaInput.ValidoAte.After(time.Now()) {
+		return nil, nil, model.ErrValidadeInvalida
+	}
+	if propostaInput.ClienteId != nil {
+		cliente, err := ps.buscarCliente(ctx, *propostaInput.ClienteId)
+		if err != nil {
+			return nil, nil, err
+		}
+		propostaInput.AplicarCliente(cliente)
+	}
+	if propostaInput.NomeEmpresa == "" || propostaInput.NomeCliente == "" {
+		return nil, nil, model.ErrDadosCliente
 	}
-	propostaOutput, err := ps.repository.CriarProposta(propostaInput)
+	if propostaInput.MarcaId != nil {
+		marca, err := ps.buscarMarca(ctx, *propostaInput.MarcaId)
+		if err != nil {
+			return nil, nil, err
+		}
+		propostaInput.AplicarMarca(marca)
+	}
+	propostaOutput, job, err := ps.repository.CriarProposta(ctx, propostaInput, geracaoMaxTentativas)
 	if err != nil {
 		logger.Error("Erro ao criar proposta!", err)
-		return &model.Proposta{}, err
+		return nil, nil, err
+	}
+	ps.publicarEvento(job, model.EventoQueued, "")
+	return propostaOutput, job, nil
+}
+
+// buscarCliente carrega o cliente referenciado por uma proposta; um id
+// inexistente vira ErrClienteNaoEncontrado, um erro do pedido e não 404 da rota.
+func (ps *PropostaService) buscarCliente(ctx context.Context, id uuid.UUID) (*model.Cliente, error) {
+	cliente, err := ps.clientes.FindCliente(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.ErrClienteNaoEncontrado
 	}
-	body, err := json.Marshal(propostaOutput)
 	if err != nil {
-		logger.Error("Erro ao realizar o Marshal da Proposta", err)
-		return &model.Proposta{}, err
+		logger.Error("Erro ao carregar cliente da proposta", err, zap.String("cliente_id", id.String()))
+		return nil, err
+	}
+	return cliente, nil
+}
+
+// buscarMarca carrega o kit de marca referenciado por uma proposta; como em
+// buscarCliente, um id inexistente vira ErrMarcaNaoEncontrada.
+func (ps *PropostaService) buscarMarca(ctx context.Context, id uuid.UUID) (*model.Marca, error) {
+	marca, err := ps.marcas.FindMarca(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.ErrMarcaNaoEncontrada
 	}
-	payload := bytes.NewBuffer(body)
-	resp, err := http.Post(iaURL+"/gerarproposta/pdf_dynamic", "application/json", payload)
 	if err != nil {
-		logger.Error("Erro ao gerar proposta", err)
-		return &model.Proposta{}, err
+		logger.Error("Erro ao carregar marca da proposta", err, zap.String("marca_id", id.String()))
+		return nil, err
+	}
+	return marca, nil
+}
+
+func (ps *PropostaService) publicarEvento(job *model.GeracaoJob, tipo string, mensagem string) {
+	ps.eventos.Publicar(model.EventoGeracao{
+		Tipo:       tipo,
+		PropostaId: job.PropostaId,
+		JobId:      job.Id,
+		Tentativa:  job.Tentativas,
+		Mensagem:   mensagem,
+		Final:      tipo == model.EventoSaved,
+	})
+}
+
+// publicarFalha publica o evento failed; final indica que o job esgotou as
+// tentativas e nenhum queued virá depois.
+func (ps *PropostaService) publicarFalha(job *model.GeracaoJob, mensagem string, final bool) {
+	ps.eventos.Publicar(model.EventoGeracao{
+		Tipo:       model.EventoFailed,
+		PropostaId: job.PropostaId,
+		JobId:      job.Id,
+		Tentativa:  job.Tentativas,
+		Mensagem:   mensagem,
+		Final:      final,
+	})
+}
+
+// AssinarEventos valida a proposta e retorna o último job conhecido junto com o
+// canal de eventos de geração dela.
+func (ps *PropostaService) AssinarEventos(ctx context.Context, propostaParam string) (*model.GeracaoJob, <-chan model.EventoGeracao, func(), error) {
+	propostaID, err := uuid.Parse(propostaParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, nil, nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		logger.Error("Servico de IA retornou um erro:", errors.New(resp.Status))
-		errorBody, _ := io.ReadAll(resp.Body)
-		logger.Error("Detalhe do erro da IA:", errors.New(string(errorBody)))
-		return nil, fmt.Errorf("servico de IA falhou: %s", resp.Status)
+	if _, err := ps.repository.FindByID(ctx, propostaID); err != nil {
+		return nil, nil, nil, err
 	}
-	respBody, err := io.ReadAll(resp.Body)
+	eventos, cancelar := ps.eventos.Assinar(propostaID)
+	job, err := ps.jobs.FindUltimoJobByProposta(ctx, propostaID)
 	if err != nil {
-		logger.Error("Erro ao ler resposta final da IA:", err)
+		cancelar()
+		return nil, nil, nil, err
+	}
+	return job, eventos, cancelar, nil
+}
+
+func (ps *PropostaService) FindJobByID(ctx context.Context, propostaParam string, jobParam string) (*model.GeracaoJob, error) {
+	propostaID, err := uuid.Parse(propostaParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
 		return nil, err
 	}
-	var iaResp iaResponse
-	if err := json.Unmarshal(respBody, &iaResp); err != nil {
-		logger.Error("Erro ao decodificar resposta da IA:", err)
+	jobID, err := uuid.Parse(jobParam)
+	if err != nil {
+		logger.Error("id do job não é um UUID", err)
 		return nil, err
 	}
-	pdfBytes, err := base64.StdEncoding.DecodeString(iaResp.PDFBase64)
+	job, err := ps.jobs.FindJobByID(ctx, jobID)
 	if err != nil {
-		logger.Error("Erro ao decodificar PDF recebido da IA:", err)
 		return nil, err
 	}
-	filePath, err := ps.SalvarPDF(propostaOutput.Id, pdfBytes)
+	if job.PropostaId != propostaID {
+		return nil, pgx.ErrNoRows
+	}
+	return job, nil
+}
+
+// ProcessarGeracao executa a geração de uma proposta já persistida: chama a IA,
+// salva o PDF e grava o HTML e a chave do PDF na proposta. Toda a geração
+// fica limitada a GERACAO_TIMEOUT.
+func (ps *PropostaService) ProcessarGeracao(ctx context.Context, job *model.GeracaoJob) (*model.Proposta, error) {
+	ctx, cancel := context.WithTimeout(ctx, geracaoTimeout)
+	defer cancel()
+
+	proposta, err := ps.repository.FindByID(ctx, job.PropostaId)
+	if err != nil {
+		logger.Error("Erro ao carregar proposta para geração", err)
+		return nil, err
+	}
+	// Os jobs só fazem a primeira geração. Com PDF já gravado, uma tentativa
+	// anterior concluiu a geração e falhou só ao marcar o job; gerar de novo
+	// seria outra chamada paga à IA sobrescrevendo o mesmo conteúdo.
+	if proposta.ArquivoFinal != "" {
+		logger.Info("Proposta já gerada por uma tentativa anterior, job concluído sem nova chamada à IA",
+			zap.String("job_id", job.Id.String()),
+			zap.String("proposta_id", proposta.Id.String()),
+		)
+		ps.publicarEvento(job, model.EventoSaved, "")
+		return proposta, nil
+	}
+	ps.publicarEvento(job, model.EventoCallingIA, "")
+	resultado, err := ps.gerador.Gerar(ctx, *proposta)
+	if err != nil {
+		logger.Error("Erro ao gerar conteudo da proposta", err)
+		return nil, err
+	}
+	defer resultado.PDF.Close()
+	ps.publicarEvento(job, model.EventoRenderingPDF, "")
+	artefato, err := ps.SalvarPDF(ctx, proposta.Id, resultado.PDF)
 	if err != nil {
 		logger.Error("Erro ao salvar o arquivo PDF:", err)
 		return nil, err
 	}
-	novoStatus := "rascunho"
 	updateData := model.PropostaUpdate{
-		ArquivoFinal: &filePath,
-		Status:       &novoStatus,
-		Html:         &iaResp.Html,
+		Artefato: artefato,
+		Html:     &resultado.Html,
 	}
-	propostaAtualizada, err := ps.repository.UpdateProposta(propostaOutput.Id, updateData)
+	propostaAtualizada, err := ps.repository.UpdateProposta(ctx, proposta.Id, updateData, model.VersaoGeracao)
 	if err != nil {
 		logger.Error("Erro ao atualizar proposta com caminho do PDF:", err)
 		return nil, err
 	}
+	ps.publicarEvento(job, model.EventoSaved, "")
 	return propostaAtualizada, nil
 }
 
-func (ps *PropostaService) UpdateProposta(id uuid.UUID, update model.PropostaUpdate) (*model.Proposta, error) {
-	if update.Status != nil {
-		validStatuses := []string{"rascunho", "enviado", "aprovado"}
-		statusValido := slices.Contains(validStatuses, *update.Status)
-		if !statusValido {
-			logger.Error("Status inválido fornecido", nil)
-			return nil, fmt.Errorf("status inválido: %s", *update.Status)
-		}
+func (ps *PropostaService) UpdateProposta(ctx context.Context, id uuid.UUID, update model.PropostaUpdate) (*model.Proposta, error) {
+	// A transição em si é validada pela máquina de estados do model dentro da
+	// transação do repositório, com a linha bloqueada.
+	if update.Status != nil && !model.StatusValido(*update.Status) {
+		logger.Error("Status inválido fornecido", model.ErrStatusInvalido, zap.String("status", *update.Status))
+		return nil, fmt.Errorf("%w: %s", model.ErrStatusInvalido, *update.Status)
 	}
-	propostaOutput, err := ps.repository.UpdateProposta(id, update)
+	if update.ValidoAte != nil && !update.ValidoAte.After(time.Now()) {
+		return nil, model.ErrValidadeInvalida
+	}
+	origemVersao := ""
+	if update.Html != nil {
+		origemVersao = model.VersaoEdicaoHtml
+	}
+	propostaOutput, err := ps.repository.UpdateProposta(ctx, id, update, origemVersao)
 	if err != nil {
 		logger.Error("Erro ao atualizar proposta!", err)
 		return nil, err
@@ -141,7 +353,7 @@ func (ps *PropostaService) UpdateProposta(id uuid.UUID, update model.PropostaUpd
 	return propostaOutput, nil
 }
 
-func (ps *PropostaService) FindByID(ParamID string) (*model.Proposta, error) {
+func (ps *PropostaService) FindByID(ctx context.Context, ParamID string) (*model.Proposta, error) {
 	if ParamID == "" {
 		return &model.Proposta{}, errors.New("ID não pode ser nulo")
 	}
@@ -150,7 +362,7 @@ func (ps *PropostaService) FindByID(ParamID string) (*model.Proposta, error) {
 		logger.Error("id não é um UUID", err)
 		return &model.Proposta{}, err
 	}
-	proposta, err := ps.repository.FindByID(id)
+	proposta, err := ps.repository.FindByID(ctx, id)
 	if err != nil {
 		logger.Error("Erro ao procurar proposta", err)
 		return &model.Proposta{}, err
@@ -158,7 +370,7 @@ func (ps *PropostaService) FindByID(ParamID string) (*model.Proposta, error) {
 	return proposta, nil
 }
 
-func (ps *PropostaService) DeleteProposta(idParam string) error {
+func (ps *PropostaService) DeleteProposta(ctx context.Context, idParam string) error {
 	if idParam == "" {
 		return errors.New("ID não pode ser nulo")
 	}
@@ -167,82 +379,205 @@ func (ps *PropostaService) DeleteProposta(idParam string) error {
 		logger.Error("id não é um UUID", err)
 		return err
 	}
-	err = ps.repository.DeleteProposta(id)
+	chaves, err := ps.repository.DeleteProposta(ctx, id)
 	if err != nil {
 		logger.Error("Erro ao deletar proposta", err)
 		return err
 	}
+	// A exclusão já foi confirmada no banco; remover os PDFs não deve depender
+	// do cliente continuar conectado. Falhas aqui viram órfãos para o coletor.
+	ctx = context.WithoutCancel(ctx)
+	for _, chave := range chaves {
+		if err := ps.store.Delete(ctx, chave); err != nil {
+			logger.Error("Erro ao remover PDF da proposta excluída, ficará para o coletor de órfãos", err, zap.String("chave", chave))
+		}
+	}
 	return nil
 }
 
-func (ps *PropostaService) RegerarProposta(idParam string, input model.RegerarProposta) (*model.Proposta, error) {
+// RegerarProposta gera o novo conteúdo a partir da proposta atual com as novas
+// entradas aplicadas em memória e só então grava tudo de uma vez. Se a geração
+// for cancelada ou falhar, a linha da proposta permanece intacta.
+func (ps *PropostaService) RegerarProposta(ctx context.Context, idParam string, input model.RegerarProposta) (*model.Proposta, error) {
 	id, err := uuid.Parse(idParam)
 	if err != nil {
 		logger.Error("id nao e um UUID valido", err)
 		return nil, err
 	}
-	propostaAtualizada, err := ps.repository.UpdateForRegerar(id, input)
+	ctx, cancel := context.WithTimeout(ctx, geracaoTimeout)
+	defer cancel()
+
+	proposta, err := ps.repository.FindByID(ctx, id)
 	if err != nil {
-		logger.Error("Erro ao atualizar proposta para regerar", err)
+		logger.Error("Erro ao carregar proposta para regerar", err)
 		return nil, err
 	}
-	body, err := json.Marshal(propostaAtualizada)
-	if err != nil {
-		logger.Error("Erro ao realizar o Marshal da Proposta", err)
+	// Conferido antes da chamada à IA para não pagar por uma geração que o
+	// repositório recusaria; a conferência definitiva é feita ao gravar.
+	if err := model.ValidarAlteracaoConteudo(proposta.Status); err != nil {
 		return nil, err
 	}
+	input.AplicarEm(proposta)
+	clienteID := input.ClienteId
+	if clienteID == nil {
+		clienteID = proposta.ClienteId
+	}
+	if clienteID != nil {
+		cliente, err := ps.buscarCliente(ctx, *clienteID)
+		if err != nil {
+			return nil, err
+		}
+		// O contato e o logo informados têm precedência; sem eles valem os do
+		// cliente e, se o cadastro não tiver, os atuais da proposta.
+		atual := *proposta
+		proposta.NomeCliente, proposta.LogoCliente = input.NomeCliente, input.LogoCliente
+		proposta.AplicarCliente(cliente)
+		if proposta.NomeCliente == "" {
+			proposta.NomeCliente = atual.NomeCliente
+		}
+		if proposta.LogoCliente == "" {
+			proposta.LogoCliente = atual.LogoCliente
+		}
+	}
+	marcaID := input.MarcaId
+	if marcaID == nil {
+		marcaID = proposta.MarcaId
+	}
+	if marcaID != nil {
+		marca, err := ps.buscarMarca(ctx, *marcaID)
+		if err != nil {
+			return nil, err
+		}
+		// Cores, logo e estilo informados têm precedência sobre o kit atual;
+		// sem logo no kit, vale o atual da proposta.
+		atual := *proposta
+		proposta.Cores, proposta.Logo, proposta.Marca = input.Cores, input.Logo, input.Marca
+		proposta.AplicarMarca(marca)
+		if proposta.Logo == "" {
+			proposta.Logo = atual.Logo
+		}
+	}
+	// O repositório grava o que vier em input, então ele passa a refletir os
+	// dados resolvidos.
+	input.ClienteId = proposta.ClienteId
+	input.NomeEmpresa = proposta.NomeEmpresa
+	input.NomeCliente = proposta.NomeCliente
+	input.LogoCliente = proposta.LogoCliente
+	input.MarcaId = proposta.MarcaId
+	input.Marca = proposta.Marca
+	input.Cores = proposta.Cores
+	input.Logo = proposta.Logo
 
-	payload := bytes.NewBuffer(body)
-	fullApiUrl := iaURL + "/gerarproposta/pdf_dynamic"
-
-	resp, err := http.Post(fullApiUrl, "application/json", payload)
+	resultado, err := ps.gerador.Gerar(ctx, *proposta)
 	if err != nil {
-		logger.Error("Erro ao chamar o servico de IA", err)
+		logger.Error("Erro ao gerar conteudo da proposta", err)
 		return nil, err
 	}
-	defer resp.Body.Close()
+	defer resultado.PDF.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		errorBody, _ := io.ReadAll(resp.Body)
-		errorMsg := fmt.Errorf("servico de IA falhou: %s - %s", resp.Status, string(errorBody))
-		logger.Error("Erro:", errorMsg)
-		return nil, errorMsg
+	artefato, err := ps.SalvarPDF(ctx, id, resultado.PDF)
+	if err != nil {
+		logger.Error("Erro ao salvar o novo arquivo PDF", err)
+		return nil, err
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	propostaComPDF, err := ps.repository.UpdateForRegerar(ctx, id, input, resultado.Html, *artefato)
 	if err != nil {
-		logger.Error("Erro ao ler resposta final da IA", err)
+		logger.Error("Erro ao atualizar proposta com o conteudo regerado", err)
 		return nil, err
 	}
 
-	var iaResp iaResponse
-	if err := json.Unmarshal(respBody, &iaResp); err != nil {
-		logger.Error("Erro ao decodificar resposta da IA", err)
+	return propostaComPDF, nil
+}
+
+func (ps *PropostaService) ListarVersoes(ctx context.Context, idParam string) ([]model.PropostaVersao, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	if _, err := ps.repository.FindByID(ctx, id); err != nil {
 		return nil, err
 	}
+	return ps.versoes.ListarVersoes(ctx, id)
+}
 
-	pdfBytes, err := base64.StdEncoding.DecodeString(iaResp.PDFBase64)
+func (ps *PropostaService) FindVersao(ctx context.Context, idParam string, numeroParam string) (*model.PropostaVersao, error) {
+	id, numero, err := parseVersaoParams(idParam, numeroParam)
 	if err != nil {
-		logger.Error("Erro ao decodificar PDF recebido da IA", err)
 		return nil, err
 	}
+	return ps.versoes.FindVersao(ctx, id, numero)
+}
 
-	filePath, err := ps.SalvarPDF(id, pdfBytes)
+// RestaurarVersao torna uma versão antiga o conteúdo atual da proposta. A
+// restauração gera uma nova versão, então nenhuma versão intermediária se perde.
+func (ps *PropostaService) RestaurarVersao(ctx context.Context, idParam string, numeroParam string) (*model.Proposta, error) {
+	id, numero, err := parseVersaoParams(idParam, numeroParam)
 	if err != nil {
-		logger.Error("Erro ao salvar o novo arquivo PDF", err)
 		return nil, err
 	}
+	return ps.versoes.RestaurarVersao(ctx, id, numero)
+}
 
-	updateData := model.PropostaUpdate{
-		ArquivoFinal: &filePath,
-		Html:         &iaResp.Html,
+func parseVersaoParams(idParam string, numeroParam string) (uuid.UUID, int, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return uuid.Nil, 0, err
 	}
+	numero, err := strconv.Atoi(numeroParam)
+	if err != nil || numero < 1 {
+		return uuid.Nil, 0, fmt.Errorf("número de versão inválido: %s", numeroParam)
+	}
+	return id, numero, nil
+}
 
-	propostaComPDF, err := ps.repository.UpdateProposta(id, updateData)
+func (ps *PropostaService) ListarArtefatos(ctx context.Context, idParam string) ([]model.ArtefatoPDF, error) {
+	id, err := uuid.Parse(idParam)
 	if err != nil {
-		logger.Error("Erro ao atualizar proposta com caminho do PDF regerado", err)
+		logger.Error("id não é um UUID", err)
 		return nil, err
 	}
+	if _, err := ps.repository.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return ps.artefatos.ListarArtefatos(ctx, id)
+}
 
-	return propostaComPDF, nil
+var ErrPDFIndisponivel = errors.New("a proposta ainda não possui PDF gerado")
+
+// AbrirPDF devolve a proposta e um leitor do PDF atual no storage. Quem chama
+// deve fechar o leitor.
+func (ps *PropostaService) AbrirPDF(ctx context.Context, idParam string) (*model.Proposta, io.ReadSeekCloser, storage.Info, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, nil, storage.Info{}, err
+	}
+	proposta, err := ps.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, storage.Info{}, err
+	}
+	if proposta.ArquivoFinal == "" {
+		return nil, nil, storage.Info{}, ErrPDFIndisponivel
+	}
+	arquivo, info, err := ps.store.Get(ctx, proposta.ArquivoFinal)
+	if err != nil {
+		logger.Error("Erro ao abrir PDF no storage", err, zap.String("chave", proposta.ArquivoFinal))
+		return nil, nil, storage.Info{}, err
+	}
+	return proposta, arquivo, info, nil
+}
+
+func (ps *PropostaService) ListarHistorico(ctx context.Context, idParam string) ([]model.TransicaoStatus, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	if _, err := ps.repository.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return ps.historico.ListarHistorico(ctx, id)
 }
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"math"
+	"propulse/model"
+	"propulse/repository"
+	"time"
+)
+
+type RelatorioService struct {
+	repository repository.RelatorioRepository
+}
+
+func NewRelatorioService(rr repository.RelatorioRepository) RelatorioService {
+	return RelatorioService{
+		repository: rr,
+	}
+}
+
+// Pipeline monta o relatório do funil. Sem "ate", vale o momento atual; sem
+// "de", os últimos PeriodosPadrao períodos. Períodos sem propostas aparecem
+// zerados, para que a série não tenha buracos.
+func (rs *RelatorioService) Pipeline(ctx context.Context, filtro model.FiltroPipeline) (*model.RelatorioPipeline, error) {
+	if filtro.Periodo == "" {
+		filtro.Periodo = model.PeriodoSemana
+	}
+	intervalo, err := filtro.Intervalo()
+	if err != nil {
+		return nil, err
+	}
+	de, ate := intervaloPipeline(intervalo, filtro.Periodo, time.Now())
+
+	metricas, err := rs.repository.Pipeline(ctx, filtro.Periodo, de, ate, filtro.NomeEmpresa)
+	if err != nil {
+		return nil, err
+	}
+
+	relatorio := &model.RelatorioPipeline{
+		Periodo:     filtro.Periodo,
+		De:          de,
+		Ate:         ate,
+		NomeEmpresa: filtro.NomeEmpresa,
+	}
+	montarPeriodos(relatorio, metricas)
+	return relatorio, nil
+}
+
+// intervaloPipeline resolve o intervalo do relatório em UTC: sem "ate", vale
+// agora; sem "de", o início do período PeriodosPadrao-1 períodos antes do de "ate".
+func intervaloPipeline(intervalo model.IntervaloDatas, periodo string, agora time.Time) (time.Time, time.Time) {
+	ate := agora.UTC()
+	if intervalo.Ate != nil {
+		ate = intervalo.Ate.UTC()
+	}
+	de := model.InicioPeriodo(ate, periodo)
+	for i := 1; i < model.PeriodosPadrao; i++ {
+		de = model.InicioPeriodo(de.Add(-time.Nanosecond), periodo)
+	}
+	if intervalo.De != nil {
+		de = intervalo.De.UTC()
+	}
+	return de, ate
+}
+
+// montarPeriodos separa a linha de totais das métricas e monta a série de
+// períodos de relatorio.De a relatorio.Ate, com zeros onde não há propostas.
+func montarPeriodos(relatorio *model.RelatorioPipeline, metricas []model.MetricasPipeline) {
+	relatorio.Totais = model.MetricasPipeline{PorStatus: map[string]int{}}
+	relatorio.Periodos = []model.MetricasPipeline{}
+	porInicio := map[time.Time]model.MetricasPipeline{}
+	for _, m := range metricas {
+		calcularTaxas(&m)
+		if m.Inicio == nil {
+			relatorio.Totais = m
+			continue
+		}
+		porInicio[m.Inicio.UTC()] = m
+	}
+	for inicio := model.InicioPeriodo(relatorio.De, relatorio.Periodo); !inicio.After(relatorio.Ate); inicio = model.ProximoPeriodo(inicio, relatorio.Periodo) {
+		m, ok := porInicio[inicio]
+		if !ok {
+			m = model.MetricasPipeline{PorStatus: map[string]int{}}
+		}
+		m.Inicio = &inicio
+		relatorio.Periodos = append(relatorio.Periodos, m)
+	}
+}
+
+func calcularTaxas(m *model.MetricasPipeline) {
+	m.TaxaConversao = razao(m.Aprovadas, m.Enviadas)
+	m.TaxaFalhaGeracao = razao(m.FalhasGeracao, m.Geracoes)
+	if m.MedianaHorasAteAprovacao != nil {
+		mediana := arredondar(*m.MedianaHorasAteAprovacao)
+		m.MedianaHorasAteAprovacao = &mediana
+	}
+}
+
+// razao devolve parte/total com quatro casas, ou nil quando total é zero.
+func razao(parte int, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	r := arredondar(float64(parte) / float64(total))
+	return &r
+}
+
+func arredondar(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
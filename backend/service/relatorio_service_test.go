@@ -0,0 +1,120 @@
+package service
+
+import (
+	"propulse/model"
+	"testing"
+	"time"
+)
+
+func TestIntervaloPipeline(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	data := func(ano int, mes time.Month, dia int, hora int, loc *time.Location) *time.Time {
+		d := time.Date(ano, mes, dia, hora, 0, 0, 0, loc)
+		return &d
+	}
+	casos := []struct {
+		nome      string
+		intervalo model.IntervaloDatas
+		periodo   string
+		agora     time.Time
+		de        time.Time
+		ate       time.Time
+	}{
+		{
+			"doze semanas até agora",
+			model.IntervaloDatas{}, model.PeriodoSemana,
+			time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC),
+			time.Date(2024, 12, 23, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			"doze meses até agora",
+			model.IntervaloDatas{}, model.PeriodoMes,
+			time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			"ate em BRT é convertido para UTC antes de contar os meses",
+			model.IntervaloDatas{Ate: data(2025, 2, 28, 22, saoPaulo)}, model.PeriodoMes,
+			time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			"de informado é mantido, em UTC",
+			model.IntervaloDatas{De: data(2025, 3, 2, 22, saoPaulo), Ate: data(2025, 3, 20, 0, time.UTC)}, model.PeriodoSemana,
+			time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 3, 1, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			de, ate := intervaloPipeline(c.intervalo, c.periodo, c.agora)
+			if !de.Equal(c.de) || de.Location() != time.UTC {
+				t.Errorf("de = %s, esperado %s", de, c.de)
+			}
+			if !ate.Equal(c.ate) || ate.Location() != time.UTC {
+				t.Errorf("ate = %s, esperado %s", ate, c.ate)
+			}
+		})
+	}
+}
+
+func TestMontarPeriodos(t *testing.T) {
+	semana := func(dia int) *time.Time {
+		d := time.Date(2025, 3, dia, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	relatorio := &model.RelatorioPipeline{
+		Periodo: model.PeriodoSemana,
+		De:      time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC),
+		Ate:     time.Date(2025, 3, 24, 0, 0, 0, 0, time.UTC),
+	}
+	// O banco pode devolver o início do período em outro fuso; a série casa
+	// pelo instante em UTC.
+	inicioBRT := semana(17).In(time.FixedZone("BRT", -3*60*60))
+	metricas := []model.MetricasPipeline{
+		{Total: 5, Enviadas: 3, Aprovadas: 1, Geracoes: 4, FalhasGeracao: 1},
+		{Inicio: semana(3), Total: 2, Enviadas: 2, Aprovadas: 1},
+		{Inicio: &inicioBRT, Total: 3, Enviadas: 1},
+	}
+
+	montarPeriodos(relatorio, metricas)
+
+	if relatorio.Totais.Total != 5 || relatorio.Totais.Inicio != nil {
+		t.Errorf("totais = %+v", relatorio.Totais)
+	}
+	if relatorio.Totais.TaxaConversao == nil || *relatorio.Totais.TaxaConversao != 0.3333 {
+		t.Errorf("taxa de conversão dos totais = %v, esperado 0.3333", relatorio.Totais.TaxaConversao)
+	}
+	if relatorio.Totais.TaxaFalhaGeracao == nil || *relatorio.Totais.TaxaFalhaGeracao != 0.25 {
+		t.Errorf("taxa de falha dos totais = %v, esperado 0.25", relatorio.Totais.TaxaFalhaGeracao)
+	}
+
+	esperado := []struct {
+		inicio *time.Time
+		total  int
+	}{
+		{semana(3), 2},
+		{semana(10), 0},
+		{semana(17), 3},
+		{semana(24), 0},
+	}
+	if len(relatorio.Periodos) != len(esperado) {
+		t.Fatalf("%d períodos, esperado %d", len(relatorio.Periodos), len(esperado))
+	}
+	for i, e := range esperado {
+		p := relatorio.Periodos[i]
+		if !p.Inicio.Equal(*e.inicio) || p.Total != e.total {
+			t.Errorf("período %d = %s com %d propostas, esperado %s com %d", i, p.Inicio, p.Total, e.inicio, e.total)
+		}
+		if e.total == 0 && p.PorStatus == nil {
+			t.Errorf("período vazio %d sem PorStatus", i)
+		}
+	}
+	if relatorio.Periodos[1].TaxaConversao != nil {
+		t.Errorf("período vazio com taxa de conversão %v", *relatorio.Periodos[1].TaxaConversao)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"propulse/model"
+	"syscall"
+)
+
+// validarDestinoWebhook resolve o host da URL e recusa o webhook se algum dos
+// endereços for interno. A verificação se repete na conexão de cada entrega
+// (controleDestinoWebhook), já que o DNS pode mudar depois do cadastro.
+func validarDestinoWebhook(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !model.DestinoWebhookPermitido(ip) {
+			return model.ErrDestinoWebhookInvalido
+		}
+		return nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: não foi possível resolver %s", model.ErrDestinoWebhookInvalido, host)
+	}
+	for _, ip := range ips {
+		if !model.DestinoWebhookPermitido(ip) {
+			return model.ErrDestinoWebhookInvalido
+		}
+	}
+	return nil
+}
+
+// controleDestinoWebhook é o Control do net.Dialer das entregas: roda com o
+// endereço já resolvido, imediatamente antes de conectar.
+func controleDestinoWebhook(network string, address string, c syscall.RawConn) error {
+	destino, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !model.DestinoWebhookPermitido(destino.Addr()) {
+		return model.ErrDestinoWebhookInvalido
+	}
+	return nil
+}
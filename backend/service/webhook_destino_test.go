@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"propulse/model"
+	"propulse/repository"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidarDestinoWebhook(t *testing.T) {
+	casos := []struct {
+		url      string
+		esperado error
+	}{
+		{"https://93.184.215.14/webhook", nil},
+		{"http://127.0.0.1:8080/webhook", model.ErrDestinoWebhookInvalido},
+		{"http://[::1]/webhook", model.ErrDestinoWebhookInvalido},
+		{"http://169.254.169.254/latest/meta-data/", model.ErrDestinoWebhookInvalido},
+		{"http://10.0.0.5/webhook", model.ErrDestinoWebhookInvalido},
+		{"http://localhost/webhook", model.ErrDestinoWebhookInvalido},
+	}
+	for _, c := range casos {
+		t.Run(c.url, func(t *testing.T) {
+			err := validarDestinoWebhook(context.Background(), c.url)
+			if !errors.Is(err, c.esperado) {
+				t.Errorf("validarDestinoWebhook(%s) = %v, esperado %v", c.url, err, c.esperado)
+			}
+		})
+	}
+}
+
+func TestEnviarWebhookRecusaDestinoInterno(t *testing.T) {
+	chamado := false
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chamado = true
+	}))
+	defer servidor.Close()
+
+	w := NewWebhookWorker(repository.WebhookRepository{})
+	entrega := &model.EntregaWebhook{Id: uuid.New(), Evento: model.EventoPropostaCriada, Payload: []byte(`{}`)}
+	webhook := &model.Webhook{Id: uuid.New(), Url: servidor.URL, Segredo: "segredo-de-teste-123"}
+
+	status, err := w.enviar(context.Background(), entrega, webhook)
+	if !errors.Is(err, model.ErrDestinoWebhookInvalido) {
+		t.Fatalf("enviar para %s: erro %v, esperado %v", servidor.URL, err, model.ErrDestinoWebhookInvalido)
+	}
+	if status != 0 || chamado {
+		t.Errorf("a conexão com o destino interno não deveria ter sido feita (status %d)", status)
+	}
+	if msg := mensagemFalhaEntrega(err); msg != "destino não permitido" {
+		t.Errorf("mensagemFalhaEntrega = %q", msg)
+	}
+}
+
+func TestMensagemFalhaEntregaNaoExpoeResposta(t *testing.T) {
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("segredo interno"))
+	}))
+	defer servidor.Close()
+
+	t.Setenv("WEBHOOK_PERMITIR_REDE_PRIVADA", "true")
+	w := NewWebhookWorker(repository.WebhookRepository{})
+	entrega := &model.EntregaWebhook{Id: uuid.New(), Evento: model.EventoPropostaCriada, Payload: []byte(`{}`)}
+	webhook := &model.Webhook{Id: uuid.New(), Url: servidor.URL, Segredo: "segredo-de-teste-123"}
+
+	status, err := w.enviar(context.Background(), entrega, webhook)
+	if status != http.StatusInternalServerError || !errors.Is(err, errRespostaWebhook) {
+		t.Fatalf("enviar = (%d, %v)", status, err)
+	}
+	if msg := mensagemFalhaEntrega(err); msg != "o destino respondeu com status fora de 2xx" {
+		t.Errorf("mensagemFalhaEntrega = %q", msg)
+	}
+}
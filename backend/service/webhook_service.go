@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	entregasLimitePadrao = 50
+	entregasLimiteMaximo = 500
+)
+
+// Headers enviados em cada entrega de webhook.
+const (
+	HeaderWebhookEvento     = "X-Propulse-Evento"
+	HeaderWebhookEntrega    = "X-Propulse-Entrega"
+	HeaderWebhookTimestamp  = "X-Propulse-Timestamp"
+	HeaderWebhookAssinatura = "X-Propulse-Assinatura"
+)
+
+type WebhookService struct {
+	repository repository.WebhookRepository
+	// permitirRedePrivada desliga a recusa de destinos internos; só para
+	// desenvolvimento local.
+	permitirRedePrivada bool
+}
+
+func NewWebhookService(wr repository.WebhookRepository) WebhookService {
+	return WebhookService{
+		repository:          wr,
+		permitirRedePrivada: env.Bool("WEBHOOK_PERMITIR_REDE_PRIVADA", false),
+	}
+}
+
+// AssinarWebhook calcula o valor de X-Propulse-Assinatura: HMAC-SHA256 com o
+// segredo do webhook sobre "<timestamp>.<corpo>". Incluir o timestamp permite
+// ao receptor recusar entregas antigas reenviadas por terceiros.
+func AssinarWebhook(segredo string, timestamp int64, corpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(corpo)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CriarWebhook grava a assinatura. Sem segredo informado, um aleatório é
+// gerado; ele só é devolvido nesta chamada. URLs que resolvem para endereços
+// internos são recusadas com model.ErrDestinoWebhookInvalido.
+func (ws *WebhookService) CriarWebhook(ctx context.Context, input model.CriarWebhook) (*model.Webhook, error) {
+	if !ws.permitirRedePrivada {
+		if err := validarDestinoWebhook(ctx, input.Url); err != nil {
+			return nil, err
+		}
+	}
+	segredo := input.Segredo
+	if segredo == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		segredo = hex.EncodeToString(b)
+	}
+	eventos := slices.Compact(slices.Sorted(slices.Values(input.Eventos)))
+	w, err := ws.repository.CriarWebhook(ctx, model.Webhook{
+		Url:     input.Url,
+		Eventos: eventos,
+		Segredo: segredo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Webhook criado", zap.String("webhook_id", w.Id.String()), zap.Strings("eventos", w.Eventos))
+	return w, nil
+}
+
+func (ws *WebhookService) ListarWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	return ws.repository.ListarWebhooks(ctx)
+}
+
+func (ws *WebhookService) FindWebhook(ctx context.Context, idParam string) (*model.Webhook, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	return ws.repository.FindWebhook(ctx, id)
+}
+
+func (ws *WebhookService) DeleteWebhook(ctx context.Context, idParam string) error {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return err
+	}
+	return ws.repository.DeleteWebhook(ctx, id)
+}
+
+// ListarEntregas devolve o log de entregas do webhook. limite fora do
+// intervalo aceito usa o padrão.
+func (ws *WebhookService) ListarEntregas(ctx context.Context, idParam string, status string, limite int) ([]model.EntregaWebhook, error) {
+	webhook, err := ws.FindWebhook(ctx, idParam)
+	if err != nil {
+		return nil, err
+	}
+	if limite <= 0 || limite > entregasLimiteMaximo {
+		limite = entregasLimitePadrao
+	}
+	return ws.repository.ListarEntregas(ctx, webhook.Id, status, limite)
+}
+
+// ReenviarEntrega devolve uma entrega à fila para ser enviada de novo, com as
+// tentativas zeradas.
+func (ws *WebhookService) ReenviarEntrega(ctx context.Context, idParam string, entregaParam string) (*model.EntregaWebhook, error) {
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		logger.Error("id não é um UUID", err)
+		return nil, err
+	}
+	entregaID, err := uuid.Parse(entregaParam)
+	if err != nil {
+		logger.Error("id da entrega não é um UUID", err)
+		return nil, err
+	}
+	entrega, err := ws.repository.ReenviarEntrega(ctx, id, entregaID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Entrega de webhook reenfileirada", zap.String("webhook_id", id.String()), zap.String("entrega_id", entregaID.String()))
+	return entrega, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"propulse/model"
+	"propulse/repository"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// webhookRespostaMaxima limita quanto do corpo da resposta é lido antes de
+// fechar a conexão. O corpo nunca é guardado: o log de entregas é visível pela
+// API e não deve expor respostas de outros serviços.
+const webhookRespostaMaxima = 1024
+
+// errRespostaWebhook marca uma resposta fora de 2xx; o status fica em
+// ultimo_status_http.
+var errRespostaWebhook = errors.New("resposta fora de 2xx")
+
+// WebhookWorker consome o log de entregas (webhook_entregas). Como a reserva
+// usa FOR UPDATE SKIP LOCKED, várias réplicas podem entregar ao mesmo tempo sem
+// enviar a mesma entrega duas vezes. No desligamento, as entregas em andamento
+// terminam (limitadas por WEBHOOK_TIMEOUT) antes de Wait retornar.
+type WebhookWorker struct {
+	repository    repository.WebhookRepository
+	httpClient    *http.Client
+	workers       int
+	intervalo     time.Duration
+	lease         time.Duration
+	maxTentativas int
+	backoffBase   time.Duration
+	backoffMax    time.Duration
+	wg            sync.WaitGroup
+}
+
+func NewWebhookWorker(wr repository.WebhookRepository) *WebhookWorker {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !env.Bool("WEBHOOK_PERMITIR_REDE_PRIVADA", false) {
+		// Confere o endereço já resolvido de cada conexão, que pode não ser o
+		// mesmo validado no cadastro.
+		dialer.Control = controleDestinoWebhook
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &WebhookWorker{
+		repository: wr,
+		httpClient: &http.Client{
+			Timeout:   env.Duration("WEBHOOK_TIMEOUT", 10*time.Second),
+			Transport: transport,
+			// Redirecionamentos não são seguidos: a URL cadastrada é o destino.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		workers:       env.Int("WEBHOOK_WORKERS", 2),
+		intervalo:     env.Duration("WEBHOOK_INTERVALO", 2*time.Second),
+		lease:         env.Duration("WEBHOOK_LEASE", time.Minute),
+		maxTentativas: env.Int("WEBHOOK_MAX_TENTATIVAS", 8),
+		backoffBase:   env.Duration("WEBHOOK_BACKOFF", 30*time.Second),
+		backoffMax:    env.Duration("WEBHOOK_BACKOFF_MAX", 6*time.Hour),
+	}
+}
+
+func (w *WebhookWorker) Start(ctx context.Context) {
+	logger.Info("Iniciando workers de webhook", zap.Int("workers", w.workers))
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go w.loop(ctx, i)
+	}
+}
+
+// Wait bloqueia até que os workers terminem as entregas em andamento após o
+// cancelamento do contexto.
+func (w *WebhookWorker) Wait() {
+	w.wg.Wait()
+}
+
+func (w *WebhookWorker) loop(ctx context.Context, numero int) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.intervalo)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			entrega, webhook, err := w.repository.ClaimProximaEntrega(ctx, w.lease)
+			if err != nil || entrega == nil {
+				break
+			}
+			w.entregar(ctx, entrega, webhook)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Worker de webhook finalizado", zap.Int("worker", numero))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *WebhookWorker) entregar(ctx context.Context, entrega *model.EntregaWebhook, webhook *model.Webhook) {
+	tags := []zap.Field{
+		zap.String("entrega_id", entrega.Id.String()),
+		zap.String("webhook_id", webhook.Id.String()),
+		zap.String("evento", entrega.Evento),
+		zap.Int("tentativa", entrega.Tentativas),
+	}
+	// A entrega já reservada não é interrompida pelo desligamento.
+	bookkeepingCtx := context.WithoutCancel(ctx)
+
+	statusHttp, err := w.enviar(bookkeepingCtx, entrega, webhook)
+	if err == nil {
+		if w.repository.MarcarEntregue(bookkeepingCtx, entrega.Id, statusHttp) == nil {
+			logger.Info("Webhook entregue", tags...)
+		}
+		return
+	}
+
+	logger.Error("Falha na entrega de webhook", err, tags...)
+	var status *int
+	if statusHttp != 0 {
+		status = &statusHttp
+	}
+	var proximaTentativa *time.Time
+	if entrega.Tentativas < w.maxTentativas {
+		quando := time.Now().Add(w.backoff(entrega.Tentativas))
+		proximaTentativa = &quando
+	}
+	w.repository.MarcarFalhaEntrega(bookkeepingCtx, entrega.Id, status, mensagemFalhaEntrega(err), proximaTentativa)
+}
+
+// mensagemFalhaEntrega resume o erro em uma mensagem genérica para o log de
+// entregas. O erro completo só vai para o log da aplicação.
+func mensagemFalhaEntrega(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errRespostaWebhook):
+		return "o destino respondeu com status fora de 2xx"
+	case errors.Is(err, model.ErrDestinoWebhookInvalido):
+		return "destino não permitido"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "tempo esgotado"
+	default:
+		return "falha de conexão com o destino"
+	}
+}
+
+// enviar faz o POST assinado. Qualquer resposta fora de 2xx conta como falha;
+// o status HTTP é devolvido mesmo nesse caso para ficar no log.
+func (w *WebhookWorker) enviar(ctx context.Context, entrega *model.EntregaWebhook, webhook *model.Webhook) (int, error) {
+	corpo := []byte(entrega.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(corpo))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Propulse-Webhook/1.0")
+	req.Header.Set(HeaderWebhookEvento, entrega.Evento)
+	req.Header.Set(HeaderWebhookEntrega, entrega.Id.String())
+	req.Header.Set(HeaderWebhookTimestamp, fmt.Sprint(timestamp))
+	req.Header.Set(HeaderWebhookAssinatura, AssinarWebhook(webhook.Segredo, timestamp, corpo))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookRespostaMaxima))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, fmt.Errorf("%w: %s", errRespostaWebhook, resp.Status)
+}
+
+// backoff dobra a espera a cada tentativa, limitada por WEBHOOK_BACKOFF_MAX.
+func (w *WebhookWorker) backoff(tentativa int) time.Duration {
+	espera := float64(w.backoffBase) * math.Pow(2, float64(tentativa-1))
+	return time.Duration(math.Min(espera, float64(w.backoffMax)))
+}
@@ -0,0 +1,74 @@
+package env
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"propulse/shared/logger"
+)
+
+func String(nome string, padrao string) string {
+	valor := strings.TrimSpace(os.Getenv(nome))
+	if valor == "" {
+		return padrao
+	}
+	return valor
+}
+
+func Int(nome string, padrao int) int {
+	valor := strings.TrimSpace(os.Getenv(nome))
+	if valor == "" {
+		return padrao
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil {
+		logger.Error("Valor inteiro inválido na variável de ambiente, usando padrão", err, zap.String("variavel", nome))
+		return padrao
+	}
+	return n
+}
+
+// IntMinimo é Int com um piso: valores abaixo de minimo, que deixariam o
+// recurso sem efeito (ex.: zero workers), são trocados por minimo.
+func IntMinimo(nome string, padrao int, minimo int) int {
+	n := Int(nome, padrao)
+	if n < minimo {
+		logger.Info("Valor abaixo do mínimo na variável de ambiente, usando o mínimo",
+			zap.String("variavel", nome),
+			zap.Int("valor", n),
+			zap.Int("minimo", minimo),
+		)
+		return minimo
+	}
+	return n
+}
+
+func Duration(nome string, padrao time.Duration) time.Duration {
+	valor := strings.TrimSpace(os.Getenv(nome))
+	if valor == "" {
+		return padrao
+	}
+	d, err := time.ParseDuration(valor)
+	if err != nil {
+		logger.Error("Duração inválida na variável de ambiente, usando padrão", err, zap.String("variavel", nome))
+		return padrao
+	}
+	return d
+}
+
+func Bool(nome string, padrao bool) bool {
+	valor := strings.TrimSpace(os.Getenv(nome))
+	if valor == "" {
+		return padrao
+	}
+	b, err := strconv.ParseBool(valor)
+	if err != nil {
+		logger.Error("Booleano inválido na variável de ambiente, usando padrão", err, zap.String("variavel", nome))
+		return padrao
+	}
+	return b
+}
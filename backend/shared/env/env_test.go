@@ -0,0 +1,110 @@
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestString(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    string
+		esperado string
+	}{
+		{"vazia usa o padrão", "", "padrao"},
+		{"só espaços usa o padrão", "   ", "padrao"},
+		{"valor sem espaços nas pontas", "  valor ", "valor"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			t.Setenv("ENV_TESTE", c.valor)
+			if obtido := String("ENV_TESTE", "padrao"); obtido != c.esperado {
+				t.Errorf("String = %q, esperado %q", obtido, c.esperado)
+			}
+		})
+	}
+}
+
+func TestInt(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    string
+		esperado int
+	}{
+		{"vazia usa o padrão", "", 3},
+		{"valor válido", " 7 ", 7},
+		{"zero é aceito", "0", 0},
+		{"inválido usa o padrão", "sete", 3},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			t.Setenv("ENV_TESTE", c.valor)
+			if obtido := Int("ENV_TESTE", 3); obtido != c.esperado {
+				t.Errorf("Int = %d, esperado %d", obtido, c.esperado)
+			}
+		})
+	}
+}
+
+func TestIntMinimo(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    string
+		esperado int
+	}{
+		{"vazia usa o padrão", "", 2},
+		{"acima do mínimo", "4", 4},
+		{"igual ao mínimo", "1", 1},
+		{"zero sobe para o mínimo", "0", 1},
+		{"negativo sobe para o mínimo", "-3", 1},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			t.Setenv("ENV_TESTE", c.valor)
+			if obtido := IntMinimo("ENV_TESTE", 2, 1); obtido != c.esperado {
+				t.Errorf("IntMinimo = %d, esperado %d", obtido, c.esperado)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    string
+		esperado time.Duration
+	}{
+		{"vazia usa o padrão", "", 5 * time.Minute},
+		{"valor válido", "90s", 90 * time.Second},
+		{"sem unidade usa o padrão", "90", 5 * time.Minute},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			t.Setenv("ENV_TESTE", c.valor)
+			if obtido := Duration("ENV_TESTE", 5*time.Minute); obtido != c.esperado {
+				t.Errorf("Duration = %s, esperado %s", obtido, c.esperado)
+			}
+		})
+	}
+}
+
+func TestBool(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    string
+		esperado bool
+	}{
+		{"vazia usa o padrão", "", true},
+		{"false", "false", false},
+		{"0", "0", false},
+		{"inválido usa o padrão", "talvez", true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			t.Setenv("ENV_TESTE", c.valor)
+			if obtido := Bool("ENV_TESTE", true); obtido != c.esperado {
+				t.Errorf("Bool = %v, esperado %v", obtido, c.esperado)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type LocalConfig struct {
+	Diretorio string
+	// URLPublica é o prefixo usado nas URLs assinadas (ex.: "http://localhost:8080").
+	// Vazio gera URLs relativas à própria API.
+	URLPublica string
+	Segredo    string
+}
+
+func LocalConfigFromEnv() LocalConfig {
+	return LocalConfig{
+		Diretorio:  env.String("STORAGE_LOCAL_DIR", "uploads"),
+		URLPublica: env.String("STORAGE_URL_PUBLICA", ""),
+		Segredo:    env.String("STORAGE_SEGREDO", ""),
+	}
+}
+
+// LocalStore guarda os objetos em disco sob Diretorio. As URLs assinadas apontam
+// para a rota /arquivos da API, que valida a assinatura HMAC e a expiração.
+type LocalStore struct {
+	diretorio  string
+	urlPublica string
+	segredo    []byte
+}
+
+func NewLocalStore(cfg LocalConfig) (*LocalStore, error) {
+	if err := os.MkdirAll(cfg.Diretorio, 0755); err != nil {
+		logger.Error("Erro ao criar diretório do storage local", err, zap.String("diretorio", cfg.Diretorio))
+		return nil, err
+	}
+	segredo := []byte(cfg.Segredo)
+	if len(segredo) == 0 {
+		segredo = make([]byte, 32)
+		if _, err := rand.Read(segredo); err != nil {
+			return nil, err
+		}
+		logger.Info("STORAGE_SEGREDO não definido, URLs assinadas deixam de valer quando o processo reiniciar")
+	}
+	return &LocalStore{
+		diretorio:  cfg.Diretorio,
+		urlPublica: strings.TrimSuffix(cfg.URLPublica, "/"),
+		segredo:    segredo,
+	}, nil
+}
+
+func (s *LocalStore) caminho(chave string) (string, error) {
+	if chave == "" || strings.HasPrefix(chave, "/") || !filepath.IsLocal(filepath.FromSlash(chave)) {
+		return "", fmt.Errorf("%w: %q", ErrChaveInvalida, chave)
+	}
+	return filepath.Join(s.diretorio, filepath.FromSlash(chave)), nil
+}
+
+func infoLocal(chave string, fi fs.FileInfo) Info {
+	return Info{
+		Chave:        chave,
+		Tamanho:      fi.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(chave)),
+		ModificadoEm: fi.ModTime(),
+		ETag:         fmt.Sprintf(`"%x-%x"`, fi.ModTime().UnixNano(), fi.Size()),
+	}
+}
+
+// Put escreve primeiro em um arquivo temporário no mesmo diretório e o renomeia
+// atomicamente, para que uma falha no meio da escrita nunca deixe um objeto
+// parcial sob a chave final.
+func (s *LocalStore) Put(ctx context.Context, chave string, r io.Reader, tamanho int64, contentType string) (Info, error) {
+	if err := ctx.Err(); err != nil {
+		return Info{}, err
+	}
+	destino, err := s.caminho(chave)
+	if err != nil {
+		return Info{}, err
+	}
+	diretorio := filepath.Dir(destino)
+	if err := os.MkdirAll(diretorio, 0755); err != nil {
+		logger.Error("Erro ao criar diretório de destino", err, zap.String("diretorio", diretorio))
+		return Info{}, err
+	}
+
+	tmp, err := os.CreateTemp(diretorio, ".upload_*.tmp")
+	if err != nil {
+		logger.Error("Erro ao criar arquivo temporário", err)
+		return Info{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	escrito, err := io.Copy(tmp, r)
+	if err == nil && tamanho >= 0 && escrito != tamanho {
+		err = fmt.Errorf("tamanho divergente: esperado %d, escrito %d", tamanho, escrito)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Error("Erro ao gravar objeto no storage local", err, zap.String("chave", chave))
+		return Info{}, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		logger.Error("Erro ao ajustar permissões do objeto", err)
+		return Info{}, err
+	}
+	if err := os.Rename(tmp.Name(), destino); err != nil {
+		logger.Error("Erro ao mover objeto para o destino final", err)
+		return Info{}, err
+	}
+	return s.Stat(ctx, chave)
+}
+
+func (s *LocalStore) Get(ctx context.Context, chave string) (io.ReadSeekCloser, Info, error) {
+	destino, err := s.caminho(chave)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	f, err := os.Open(destino)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, Info{}, ErrNaoEncontrado
+	}
+	if err != nil {
+		return nil, Info{}, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, Info{}, err
+	}
+	return f, infoLocal(chave, fi), nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, chave string) error {
+	destino, err := s.caminho(chave)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(destino); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) Stat(ctx context.Context, chave string) (Info, error) {
+	destino, err := s.caminho(chave)
+	if err != nil {
+		return Info{}, err
+	}
+	fi, err := os.Stat(destino)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Info{}, ErrNaoEncontrado
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	return infoLocal(chave, fi), nil
+}
+
+// List percorre o diretório ignorando os temporários de uploads em andamento.
+func (s *LocalStore) List(ctx context.Context, prefixo string, fn func(Info) error) error {
+	return filepath.WalkDir(s.diretorio, func(caminho string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload_") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.diretorio, caminho)
+		if err != nil {
+			return err
+		}
+		chave := filepath.ToSlash(rel)
+		if !strings.HasPrefix(chave, prefixo) {
+			return nil
+		}
+		fi, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(infoLocal(chave, fi))
+	})
+}
+
+func (s *LocalStore) URLAssinada(ctx context.Context, chave string, expiracao time.Duration) (string, error) {
+	if _, err := s.caminho(chave); err != nil {
+		return "", err
+	}
+	expira := strconv.FormatInt(time.Now().Add(expiracao).Unix(), 10)
+	q := url.Values{}
+	q.Set("expira", expira)
+	q.Set("assinatura", s.assinar(chave, expira))
+	return s.urlPublica + "/arquivos/" + (&url.URL{Path: chave}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+// VerificarAssinatura confere uma URL gerada por URLAssinada.
+func (s *LocalStore) VerificarAssinatura(chave string, expira string, assinatura string) bool {
+	unix, err := strconv.ParseInt(expira, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	esperada := s.assinar(chave, expira)
+	return subtle.ConstantTimeCompare([]byte(esperada), []byte(assinatura)) == 1
+}
+
+func (s *LocalStore) assinar(chave string, expira string) string {
+	mac := hmac.New(sha256.New, s.segredo)
+	mac.Write([]byte(chave + "\n" + expira))
+	return hex.EncodeToString(mac.Sum(nil))
+}
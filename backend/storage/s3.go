@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"propulse/shared/env"
+	"propulse/shared/logger"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.uber.org/zap"
+)
+
+// tamanhoParteS3 é o tamanho de cada parte no upload de objetos de tamanho
+// desconhecido; sem ele o minio-go reserva partes de centenas de MB.
+const tamanhoParteS3 = 8 << 20
+
+type S3Config struct {
+	Endpoint    string
+	Regiao      string
+	Bucket      string
+	AccessKey   string
+	SecretKey   string
+	UsarSSL     bool
+	CriarBucket bool
+}
+
+func S3ConfigFromEnv() S3Config {
+	return S3Config{
+		Endpoint:    env.String("S3_ENDPOINT", "minio:9000"),
+		Regiao:      env.String("S3_REGION", ""),
+		Bucket:      env.String("S3_BUCKET", "propulse"),
+		AccessKey:   env.String("S3_ACCESS_KEY", ""),
+		SecretKey:   env.String("S3_SECRET_KEY", ""),
+		UsarSSL:     env.Bool("S3_USE_SSL", false),
+		CriarBucket: env.Bool("S3_CRIAR_BUCKET", false),
+	}
+}
+
+// S3Store guarda os objetos em um bucket compatível com S3 (AWS, MinIO etc.),
+// permitindo rodar várias réplicas do backend sobre o mesmo armazenamento.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Store(ctx context.Context, cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UsarSSL,
+		Region: cfg.Regiao,
+	})
+	if err != nil {
+		logger.Error("Erro ao criar cliente S3", err, zap.String("endpoint", cfg.Endpoint))
+		return nil, err
+	}
+	if cfg.CriarBucket {
+		existe, err := client.BucketExists(ctx, cfg.Bucket)
+		if err != nil {
+			logger.Error("Erro ao verificar bucket S3", err, zap.String("bucket", cfg.Bucket))
+			return nil, err
+		}
+		if !existe {
+			if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Regiao}); err != nil {
+				logger.Error("Erro ao criar bucket S3", err, zap.String("bucket", cfg.Bucket))
+				return nil, err
+			}
+			logger.Info("Bucket S3 criado", zap.String("bucket", cfg.Bucket))
+		}
+	}
+	return &S3Store{
+		client: client,
+		bucket: cfg.Bucket,
+	}, nil
+}
+
+func infoS3(oi minio.ObjectInfo) Info {
+	return Info{
+		Chave:        oi.Key,
+		Tamanho:      oi.Size,
+		ContentType:  oi.ContentType,
+		ModificadoEm: oi.LastModified,
+		ETag:         `"` + oi.ETag + `"`,
+	}
+}
+
+// traduzirErro converte o "objeto inexistente" do S3 no erro comum do pacote.
+func traduzirErro(err error) error {
+	if err == nil {
+		return nil
+	}
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound {
+		return ErrNaoEncontrado
+	}
+	return err
+}
+
+// Put usa upload simples quando o tamanho é conhecido e multipart com partes de
+// tamanhoParteS3 quando não é (tamanho < 0), limitando o buffer em memória. O
+// S3 só torna o objeto visível quando o upload termina, então nunca há objeto
+// parcial sob a chave.
+func (s *S3Store) Put(ctx context.Context, chave string, r io.Reader, tamanho int64, contentType string) (Info, error) {
+	opcoes := minio.PutObjectOptions{
+		ContentType: contentType,
+	}
+	if tamanho < 0 {
+		opcoes.PartSize = tamanhoParteS3
+	}
+	up, err := s.client.PutObject(ctx, s.bucket, chave, r, tamanho, opcoes)
+	if err != nil {
+		logger.Error("Erro ao enviar objeto ao S3", err, zap.String("chave", chave))
+		return Info{}, err
+	}
+	return Info{
+		Chave:        chave,
+		Tamanho:      up.Size,
+		ContentType:  contentType,
+		ModificadoEm: up.LastModified,
+		ETag:         `"` + up.ETag + `"`,
+	}, nil
+}
+
+// Get não baixa o objeto de imediato: o minio.Object faz GETs com Range sob
+// demanda conforme é lido e posicionado com Seek.
+func (s *S3Store) Get(ctx context.Context, chave string) (io.ReadSeekCloser, Info, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, chave, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, Info{}, traduzirErro(err)
+	}
+	oi, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, Info{}, traduzirErro(err)
+	}
+	return obj, infoS3(oi), nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, chave string) error {
+	return s.client.RemoveObject(ctx, s.bucket, chave, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Store) Stat(ctx context.Context, chave string) (Info, error) {
+	oi, err := s.client.StatObject(ctx, s.bucket, chave, minio.StatObjectOptions{})
+	if err != nil {
+		return Info{}, traduzirErro(err)
+	}
+	return infoS3(oi), nil
+}
+
+func (s *S3Store) List(ctx context.Context, prefixo string, fn func(Info) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for oi := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefixo, Recursive: true}) {
+		if oi.Err != nil {
+			return oi.Err
+		}
+		if err := fn(infoS3(oi)); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (s *S3Store) URLAssinada(ctx context.Context, chave string, expiracao time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, chave, expiracao, nil)
+	if err != nil {
+		logger.Error("Erro ao gerar URL assinada do S3", err, zap.String("chave", chave))
+		return "", err
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"propulse/shared/env"
+	"time"
+)
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var ErrNaoEncontrado = errors.New("objeto não encontrado no storage")
+
+var ErrChaveInvalida = errors.New("chave de storage inválida")
+
+// Info descreve um objeto armazenado.
+type Info struct {
+	Chave        string
+	Tamanho      int64
+	ContentType  string
+	ModificadoEm time.Time
+	ETag         string
+}
+
+// Store abstrai onde os arquivos das propostas ficam guardados. As chaves são
+// caminhos relativos separados por "/" (ex.: "propostas/proposta_<id>_....pdf")
+// e são o que fica gravado no banco, nunca um caminho do sistema de arquivos.
+type Store interface {
+	// Put aceita tamanho < 0 quando o tamanho não é conhecido de antemão.
+	Put(ctx context.Context, chave string, r io.Reader, tamanho int64, contentType string) (Info, error)
+	// Get devolve um leitor com Seek, o que permite servir Range sem baixar o
+	// objeto inteiro. Quem chama deve fechar o leitor.
+	Get(ctx context.Context, chave string) (io.ReadSeekCloser, Info, error)
+	Delete(ctx context.Context, chave string) error
+	Stat(ctx context.Context, chave string) (Info, error)
+	// List chama fn para cada objeto cuja chave começa com prefixo; um erro
+	// retornado por fn interrompe a listagem e é devolvido.
+	List(ctx context.Context, prefixo string, fn func(Info) error) error
+	URLAssinada(ctx context.Context, chave string, expiracao time.Duration) (string, error)
+}
+
+// NewStoreFromEnv escolhe a implementação pela variável STORAGE_BACKEND.
+func NewStoreFromEnv(ctx context.Context) (Store, error) {
+	backend := env.String("STORAGE_BACKEND", BackendLocal)
+	switch backend {
+	case BackendLocal:
+		return NewLocalStore(LocalConfigFromEnv())
+	case BackendS3:
+		return NewS3Store(ctx, S3ConfigFromEnv())
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND inválido: %q (use %q ou %q)", backend, BackendLocal, BackendS3)
+	}
+}